@@ -0,0 +1,87 @@
+package goh
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Selection of files for an archive, shared by archive handlers.
+type archive struct {
+	Paths         []string
+	Filter        Filter
+	AllowDotfiles bool
+}
+
+// Single file in an archive.
+type archiveFile struct {
+	Name string
+	Path string
+	Info fs.FileInfo
+}
+
+// Lists the files to be archived, in walk order.
+func (self archive) files() (out []archiveFile, _ error) {
+	for _, root := range self.Paths {
+		base := filepath.Dir(filepath.Clean(root))
+
+		err := filepath.WalkDir(root, func(path string, ent fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if path != root && !self.AllowDotfiles && strings.HasPrefix(ent.Name(), `.`) {
+				if ent.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+
+			if !ent.Type().IsRegular() || !self.allow(path) {
+				return nil
+			}
+
+			info, err := ent.Info()
+			if err != nil {
+				return err
+			}
+
+			name, err := filepath.Rel(base, path)
+			if err != nil {
+				return err
+			}
+
+			out = append(out, archiveFile{filepath.ToSlash(name), path, info})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf(`[goh] failed to list files in %q: %w`, root, err)
+		}
+	}
+	return out, nil
+}
+
+func (self archive) allow(path string) bool {
+	return self.Filter == nil || self.Filter.Allow(filepath.ToSlash(path))
+}
+
+func copyFile(out io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(out, file)
+	return err
+}
+
+func orStr(val, def string) string {
+	if val != `` {
+		return val
+	}
+	return def
+}
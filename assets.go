@@ -0,0 +1,237 @@
+package goh
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+/*
+Default "Cache-Control" header of fingerprinted files served by `goh.Assets`.
+Since the content hash is part of the file name, the files never change, and
+may be cached indefinitely.
+*/
+const DefaultAssetCacheControl = `public, max-age=31536000, immutable`
+
+/*
+HTTP handler that serves fingerprinted static files: files with a content hash
+in the name, such as "app.3f9ab2c1.js" for "app.js". Should be created at
+startup via `goh.Assets.Load`, which hashes the servable files of `.Dir` and
+populates `.Manifest`:
+
+	var assets = goh.MustAssets(goh.Assets{
+		Dir: goh.Dir{Path: `static`, Prefix: `/static/`},
+	}.Load())
+
+Templates refer to files by their original names via `goh.Assets.AssetUrl`,
+which is also available as the template function "asset", see
+`goh.Assets.FuncMap`:
+
+	<script src="{{asset "app.js"}}"></script>
+
+	<!-- Output: -->
+	<script src="/static/app.3f9ab2c1.js"></script>
+
+Requests for fingerprinted names are served from the original files, with the
+settings of `.Dir` and the "Cache-Control" header `.CacheControl`, defaulting
+to `goh.DefaultAssetCacheControl`. Other requests, including requests for the
+original names, are served by `.Dir` as usual. Files are assumed not to change
+after loading; when they do, `.Load` must be called again.
+*/
+type Assets struct {
+	Dir          Dir
+	CacheControl string
+	Manifest     map[string]string
+}
+
+/*
+Returns a copy with `.Manifest` populated by hashing the servable files in
+`.Dir.Path`, as determined by the dir settings. Keys are slash-separated paths
+relative to `.Dir.Path`, such as "one/app.js", and values are the same paths
+with a content hash inserted before the extension, such as
+"one/app.3f9ab2c1.js".
+*/
+func (self Assets) Load() (Assets, error) {
+	manifest := map[string]string{}
+
+	err := MemDir{Dir: self.Dir}.walk(func(filePath, rel string, _ fs.FileInfo) error {
+		hash, err := hashFile(filePath)
+		if err != nil {
+			return err
+		}
+		manifest[rel] = assetName(rel, hash)
+		return nil
+	})
+
+	self.Manifest = manifest
+	return self, err
+}
+
+/*
+Returns the fingerprinted path for the given path relative to `.Dir.Path`, such
+as "app.3f9ab2c1.js" for "app.js". A leading slash is ignored. Unknown paths
+are returned as-is, without a leading slash.
+*/
+func (self Assets) AssetPath(name string) string {
+	name = strings.TrimPrefix(name, `/`)
+	val, ok := self.Manifest[name]
+	if ok {
+		return val
+	}
+	return name
+}
+
+/*
+Returns the URL path of the fingerprinted file, which is `goh.Assets.AssetPath`
+prefixed with `.Dir.Prefix`, such as "/static/app.3f9ab2c1.js". Intended for
+templates.
+*/
+func (self Assets) AssetUrl(name string) string {
+	return strings.TrimSuffix(self.Dir.Prefix, `/`) + `/` + self.AssetPath(name)
+}
+
+/*
+Returns template functions for use with `html/template.Template.Funcs`.
+Currently includes only "asset", which is `goh.Assets.AssetUrl`.
+*/
+func (self Assets) FuncMap() template.FuncMap {
+	return template.FuncMap{`asset`: self.AssetUrl}
+}
+
+/*
+Returns the original path for the given fingerprinted path, and true if it's
+listed in `.Manifest`. Inverse of `goh.Assets.AssetPath`.
+*/
+func (self Assets) Source(name string) (string, bool) {
+	name = strings.TrimPrefix(name, `/`)
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+
+	// Hash before the extension, such as "app.3f9ab2c1.js".
+	src := strings.TrimSuffix(stem, path.Ext(stem)) + ext
+	if src != name && self.Manifest[src] == name {
+		return src, true
+	}
+
+	// Hash at the end of a file name without extension, such as "LICENSE.3f9ab2c1".
+	if stem != name && self.Manifest[stem] == name {
+		return stem, true
+	}
+	return ``, false
+}
+
+// Implement `http.Handler`.
+func (self Assets) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Assets`, self.serveHTTP)
+}
+
+func (self Assets) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		self.Dir.notFound().ServeHTTP(rew, req)
+	}
+}
+
+/*
+Implement `HttpHandlerOpt`. If possible, serves the requested file or
+`.Dir.Fallback`, and returns true. Otherwise returns false.
+*/
+func (self Assets) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.Assets`, self.servedHTTP)
+}
+
+func (self Assets) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return servedHTTP(self.HanOpt(req), rew, req)
+}
+
+// Conforms to `goh.Han`. Always returns non-nil.
+func (self Assets) Han(req *http.Request) http.Handler {
+	return orNotFound(self.HanOpt(req), self.Dir.notFound())
+}
+
+/*
+Conforms to `goh.Han`. Returns the original file for a fingerprinted request
+path, otherwise delegates to `goh.Dir.HanOpt`, which may return nil.
+*/
+func (self Assets) HanOpt(req *http.Request) http.Handler {
+	file, ok := self.Resolve(req)
+	if ok {
+		return file
+	}
+	return self.Dir.HanOpt(req)
+}
+
+/*
+Resolves a fingerprinted request path to the original file, returning false if
+the path is not fingerprinted or the file is not found. The resulting file has
+the "Cache-Control" header `.CacheControl`, and `.Dir.OnFile` is applied.
+*/
+func (self Assets) Resolve(req *http.Request) (File, bool) {
+	reqPath, ok := self.Dir.reqPath(req)
+	if !ok {
+		return File{}, false
+	}
+
+	src, ok := self.Source(reqPath)
+	if !ok {
+		return File{}, false
+	}
+
+	filePath := filepath.Join(self.Dir.Path, filepath.FromSlash(src))
+	if !self.Dir.Allow(filePath) || !self.Dir.allowReal(filePath) {
+		return File{}, false
+	}
+
+	file := self.Dir.File(filePath)
+	if !file.Exists() {
+		return File{}, false
+	}
+
+	file.Header = cloneHeader(file.Header)
+	file.Header.Set(`Cache-Control`, orStr(self.CacheControl, DefaultAssetCacheControl))
+	if self.Dir.OnFile != nil {
+		self.Dir.OnFile(&file, req)
+	}
+	return file, true
+}
+
+/*
+Panics if the error is non-nil, otherwise returns the value. Intended for
+initializing global variables, see `goh.Assets`.
+*/
+func MustAssets(val Assets, err error) Assets {
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Number of hex characters of the content hash used by `goh.Assets`.
+const assetHashLen = 8
+
+func hashFile(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ``, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return ``, err
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:assetHashLen], nil
+}
+
+// Inserts the hash before the extension of the file name.
+func assetName(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + `.` + hash + ext
+}
@@ -0,0 +1,92 @@
+package goh
+
+import (
+	"html/template"
+	"net/http"
+	ht "net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	_ = http.Handler(Assets{})
+	_ = HttpHandlerOpt(Assets{})
+	_ = Han(Assets{}.Han)
+	_ = Han(Assets{}.HanOpt)
+)
+
+func TestAssets(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `app.js`), `console.log(1)`)
+	writeFile(filepath.Join(root, `one/two.css`), `body {}`)
+	writeFile(filepath.Join(root, `LICENSE`), `public domain`)
+	writeFile(filepath.Join(root, `.env`), `secret`)
+
+	assets := MustAssets(Assets{Dir: Dir{Path: root, Prefix: `/static/`}}.Load())
+
+	eq(t, 3, len(assets.Manifest))
+	eq(t, `app.0a286891.js`, assets.AssetPath(`app.js`))
+	eq(t, `app.0a286891.js`, assets.AssetPath(`/app.js`))
+	eq(t, `missing.js`, assets.AssetPath(`missing.js`))
+	eq(t, `/static/`+assets.AssetPath(`one/two.css`), assets.AssetUrl(`one/two.css`))
+	eq(t, true, strings.HasPrefix(assets.AssetPath(`LICENSE`), `LICENSE.`))
+
+	for key, val := range assets.Manifest {
+		src, ok := assets.Source(val)
+		eq(t, true, ok)
+		eq(t, key, src)
+	}
+
+	_, ok := assets.Source(`app.js`)
+	eq(t, false, ok)
+	_, ok = assets.Source(`app.00000000.js`)
+	eq(t, false, ok)
+
+	t.Run(`hashed`, func(t *testing.T) {
+		for _, name := range []string{`app.js`, `one/two.css`, `LICENSE`} {
+			rew := ht.NewRecorder()
+			assets.ServeHTTP(rew, pathReq(assets.AssetUrl(name)))
+			eq(t, http.StatusOK, rew.Code)
+			eq(t, DefaultAssetCacheControl, rew.Header().Get(`Cache-Control`))
+		}
+
+		rew := ht.NewRecorder()
+		assets.ServeHTTP(rew, pathReq(assets.AssetUrl(`app.js`)))
+		eq(t, `console.log(1)`, rew.Body.String())
+	})
+
+	t.Run(`original`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		assets.ServeHTTP(rew, pathReq(`/static/app.js`))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, ``, rew.Header().Get(`Cache-Control`))
+		eq(t, `console.log(1)`, rew.Body.String())
+	})
+
+	t.Run(`missing`, func(t *testing.T) {
+		for _, path := range []string{`/static/app.00000000.js`, `/static/.env`, `/app.0a286891.js`} {
+			rew := ht.NewRecorder()
+			assets.ServeHTTP(rew, pathReq(path))
+			eq(t, http.StatusNotFound, rew.Code)
+			eq(t, nil, assets.HanOpt(pathReq(path)))
+		}
+	})
+
+	t.Run(`cache control`, func(t *testing.T) {
+		assets := assets
+		assets.CacheControl = `public, max-age=60`
+
+		rew := ht.NewRecorder()
+		assets.ServeHTTP(rew, pathReq(assets.AssetUrl(`app.js`)))
+		eq(t, `public, max-age=60`, rew.Header().Get(`Cache-Control`))
+	})
+
+	t.Run(`template`, func(t *testing.T) {
+		tmpl := template.Must(template.New(``).Funcs(assets.FuncMap()).Parse(`<script src="{{asset "app.js"}}"></script>`))
+
+		var buf strings.Builder
+		try(tmpl.Execute(&buf, nil))
+		eq(t, `<script src="/static/app.0a286891.js"></script>`, buf.String())
+	})
+}
@@ -0,0 +1,237 @@
+package goh
+
+import (
+	"net/http"
+	"sort"
+)
+
+/*
+HTTP handler that dispatches by `req.Method`. Keys must be HTTP methods such as
+`http.MethodGet`. If there's no handler for `http.MethodHead`, falls back on
+the handler for `http.MethodGet`, if any. If there's no matching handler,
+responds with `goh.MethodNotAllowed` listing the available methods. Example
+usage:
+
+	func handler(req *http.Request) http.Handler {
+		return goh.ByMethod{
+			http.MethodGet:  goh.StringOk(`read`),
+			http.MethodPost: goh.StringWith(http.StatusCreated, `created`),
+		}
+	}
+*/
+type ByMethod map[string]http.Handler
+
+// Implement `http.Handler`.
+func (self ByMethod) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.ByMethod`, self.serveHTTP)
+}
+
+func (self ByMethod) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	self.Han(req).ServeHTTP(rew, req)
+}
+
+/*
+Implement `goh.HttpHandlerOpt`. If there's a handler for the request method,
+uses it to serve the request and returns true. Otherwise returns false.
+*/
+func (self ByMethod) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.ByMethod`, self.servedHTTP)
+}
+
+func (self ByMethod) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	han := self.HanOpt(req)
+	if han != nil {
+		han.ServeHTTP(rew, req)
+		return true
+	}
+	return false
+}
+
+// Conforms to `goh.Han`. Always returns non-nil.
+func (self ByMethod) Han(req *http.Request) http.Handler {
+	han := self.HanOpt(req)
+	if han != nil {
+		return han
+	}
+	return MethodNotAllowed{Allow: self.Allow()}
+}
+
+/*
+Conforms to `goh.Han`. Returns the handler for the request method, or nil if
+there is none.
+*/
+func (self ByMethod) HanOpt(req *http.Request) http.Handler {
+	han := self[req.Method]
+	if han == nil && req.Method == http.MethodHead {
+		han = self[http.MethodGet]
+	}
+	return han
+}
+
+/*
+Returns the sorted list of methods with non-nil handlers, suitable for the
+"Allow" header. Includes `http.MethodHead` when `http.MethodGet` is present.
+*/
+func (self ByMethod) Allow() []string {
+	out := make([]string, 0, len(self)+1)
+	for key, val := range self {
+		if val != nil {
+			out = append(out, key)
+		}
+	}
+	if self[http.MethodGet] != nil && self[http.MethodHead] == nil {
+		out = append(out, http.MethodHead)
+	}
+	sort.Strings(out)
+	return out
+}
+
+/*
+HTTP handler that chooses between two handlers by calling `.Cond` on each
+request. Nil `.Cond` is considered false. A nil branch is treated as "not
+found": `.ServeHTTP` and `.Han` use `goh.NotFound`, while `.ServedHTTP` and
+`.HanOpt` treat it as a miss. Example usage:
+
+	var han = goh.If{
+		Cond: isLoggedIn,
+		Then: goh.File{Path: `static/app.html`},
+		Else: goh.RedirectWith(http.StatusSeeOther, `/login`),
+	}
+*/
+type If struct {
+	Cond func(*http.Request) bool
+	Then http.Handler
+	Else http.Handler
+}
+
+// Implement `http.Handler`.
+func (self If) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.If`, self.serveHTTP)
+}
+
+func (self If) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	self.Han(req).ServeHTTP(rew, req)
+}
+
+/*
+Implement `goh.HttpHandlerOpt`. If the chosen branch is nil, returns false. If
+the chosen branch implements `goh.HttpHandlerOpt`, delegates to it. Otherwise
+serves the chosen branch and returns true.
+*/
+func (self If) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.If`, self.servedHTTP)
+}
+
+func (self If) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return servedHTTP(self.HanOpt(req), rew, req)
+}
+
+// Conforms to `goh.Han`. Always returns non-nil.
+func (self If) Han(req *http.Request) http.Handler {
+	return orNotFound(self.HanOpt(req))
+}
+
+// Conforms to `goh.Han`. Returns the chosen branch, which may be nil.
+func (self If) HanOpt(req *http.Request) http.Handler {
+	if self.Cond != nil && self.Cond(req) {
+		return self.Then
+	}
+	return self.Else
+}
+
+func servedHTTP(han http.Handler, rew http.ResponseWriter, req *http.Request) bool {
+	if han == nil {
+		return false
+	}
+	opt, _ := han.(HttpHandlerOpt)
+	if opt != nil {
+		return opt.ServedHTTP(rew, req)
+	}
+	han.ServeHTTP(rew, req)
+	return true
+}
+
+/*
+Shortcut for `goh.Chain(vals)`. Returns an HTTP handler that tries each
+optional handler in order, serving the first one that accepts the request,
+falling back on `goh.NotFound`. Example usage:
+
+	var han = goh.Coalesce(
+		goh.Dir{Path: `static`},
+		goh.If{Cond: isApi, Then: apiHandler},
+	)
+*/
+func Coalesce(vals ...HttpHandlerOpt) http.Handler { return Chain(vals) }
+
+/*
+Sequence of optional handlers, tried in order. Nil elements are skipped. Also
+see `goh.Coalesce`.
+*/
+type Chain []HttpHandlerOpt
+
+// Implement `http.Handler`. If no handler accepts the request, uses `goh.NotFound`.
+func (self Chain) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Chain`, self.serveHTTP)
+}
+
+func (self Chain) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		NotFound{}.ServeHTTP(rew, req)
+	}
+}
+
+/*
+Implement `goh.HttpHandlerOpt`. Returns true if any of the handlers served the
+request.
+*/
+func (self Chain) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.Chain`, self.servedHTTP)
+}
+
+func (self Chain) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	for _, val := range self {
+		if val != nil && val.ServedHTTP(rew, req) {
+			return true
+		}
+	}
+	return false
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Chain) Han(*http.Request) http.Handler { return self }
+
+// Signature of a typical `net/http` middleware function.
+type Middleware = func(http.Handler) http.Handler
+
+/*
+Applies the given middlewares to the handler. The first middleware is the
+outermost: `goh.Wrap(han, one, two)` is equivalent to `one(two(han))`. Nil
+middlewares are skipped. Allows to use existing `net/http` middlewares with
+Goh handlers:
+
+	var han = goh.Wrap(goh.Dir{Path: `static`}, gziphandler.GzipHandler)
+*/
+func Wrap(han http.Handler, funs ...Middleware) http.Handler {
+	for ind := len(funs) - 1; ind >= 0; ind-- {
+		if funs[ind] != nil {
+			han = funs[ind](han)
+		}
+	}
+	return han
+}
+
+/*
+Same as `goh.Wrap`, but for `goh.Han`. The middlewares are applied to each
+handler returned by the function. When the function returns nil, the result is
+also nil, preserving the "try" semantics of functions such as
+`goh.Dir.HanOpt`.
+*/
+func WrapHan(fun Han, funs ...Middleware) Han {
+	return func(req *http.Request) http.Handler {
+		han := fun(req)
+		if han == nil {
+			return nil
+		}
+		return Wrap(han, funs...)
+	}
+}
@@ -0,0 +1,156 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+var (
+	_ = http.Handler(ByMethod{})
+	_ = HttpHandlerOpt(ByMethod{})
+	_ = Han(ByMethod{}.Han)
+	_ = Han(ByMethod{}.HanOpt)
+	_ = http.Handler(MethodNotAllowed{})
+	_ = Han(MethodNotAllowed{}.Han)
+	_ = http.Handler(If{})
+	_ = HttpHandlerOpt(If{})
+	_ = Han(If{}.Han)
+	_ = Han(If{}.HanOpt)
+	_ = HttpHandlerOpt(Chain{})
+	_ = Han(Chain{}.Han)
+)
+
+func methodReq(method string) *http.Request {
+	return &http.Request{Method: method, URL: pathUrl(`/`)}
+}
+
+func TestByMethod(t *testing.T) {
+	han := ByMethod{
+		http.MethodGet:    StringOk(`get`),
+		http.MethodPost:   StringOk(`post`),
+		http.MethodDelete: nil,
+	}
+
+	eq(t, []string{http.MethodGet, http.MethodHead, http.MethodPost}, han.Allow())
+
+	eq(t, StringOk(`get`), han.Han(methodReq(http.MethodGet)))
+	eq(t, StringOk(`get`), han.Han(methodReq(http.MethodHead)))
+	eq(t, StringOk(`post`), han.Han(methodReq(http.MethodPost)))
+	eq(t, nil, han.HanOpt(methodReq(http.MethodDelete)))
+
+	t.Run(`match`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, methodReq(http.MethodPost))
+
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `post`, rew.Body.String())
+	})
+
+	t.Run(`mismatch`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		eq(t, false, han.ServedHTTP(rew, methodReq(http.MethodPut)))
+		han.ServeHTTP(rew, methodReq(http.MethodPut))
+
+		eq(t, http.StatusMethodNotAllowed, rew.Code)
+		eq(t, http.Header{`Allow`: {`GET, HEAD, POST`}}, rew.Result().Header)
+	})
+}
+
+func TestIf(t *testing.T) {
+	isPost := func(req *http.Request) bool { return req.Method == http.MethodPost }
+
+	han := If{Cond: isPost, Then: StringOk(`then`), Else: StringOk(`else`)}
+	eq(t, StringOk(`then`), han.Han(methodReq(http.MethodPost)))
+	eq(t, StringOk(`else`), han.Han(methodReq(http.MethodGet)))
+
+	eq(t, StringOk(`else`), If{Else: StringOk(`else`)}.Han(methodReq(http.MethodPost)))
+
+	t.Run(`nil branch`, func(t *testing.T) {
+		han := If{Cond: isPost, Then: StringOk(`then`)}
+		eq(t, nil, han.HanOpt(methodReq(http.MethodGet)))
+		eq(t, NotFound{}, han.Han(methodReq(http.MethodGet)))
+
+		rew := ht.NewRecorder()
+		eq(t, false, han.ServedHTTP(rew, methodReq(http.MethodGet)))
+		eq(t, true, han.ServedHTTP(rew, methodReq(http.MethodPost)))
+		eq(t, `then`, rew.Body.String())
+	})
+
+	t.Run(`optional branch`, func(t *testing.T) {
+		han := If{Cond: isPost, Then: File{Path: `6c6ea5f2a9e24dc8b8ac5c47f77bf9e0`}}
+
+		rew := ht.NewRecorder()
+		eq(t, false, han.ServedHTTP(rew, methodReq(http.MethodPost)))
+		eq(t, http.StatusOK, rew.Code)
+	})
+}
+
+func TestChain(t *testing.T) {
+	isPost := func(req *http.Request) bool { return req.Method == http.MethodPost }
+	missing := File{Path: `0ad0bc0a1d3542b2a0c2cd1ad16da3c8`}
+
+	han := Coalesce(
+		nil,
+		missing,
+		If{Cond: isPost, Then: StringOk(`post`)},
+		ByMethod{http.MethodGet: StringOk(`get`)},
+	)
+
+	test := func(method string, code int, body string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, methodReq(method))
+		eq(t, code, rew.Code)
+		eq(t, body, rew.Body.String())
+	}
+
+	test(http.MethodPost, http.StatusOK, `post`)
+	test(http.MethodGet, http.StatusOK, `get`)
+	test(http.MethodPut, http.StatusNotFound, ``)
+
+	eq(t, false, Chain{missing}.ServedHTTP(ht.NewRecorder(), methodReq(http.MethodGet)))
+}
+
+func TestWrap(t *testing.T) {
+	mid := func(name string) Middleware {
+		return func(han http.Handler) http.Handler {
+			return http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+				rew.Header().Add(`Trace`, name)
+				han.ServeHTTP(rew, req)
+			})
+		}
+	}
+
+	rew := ht.NewRecorder()
+	Wrap(StringWith(201, `ok`), mid(`one`), nil, mid(`two`)).ServeHTTP(rew, nil)
+
+	eq(t, 201, rew.Code)
+	eq(t, `ok`, rew.Body.String())
+	eq(t, []string{`one`, `two`}, rew.Header().Values(`Trace`))
+
+	eq(t, StringOk(`ok`), Wrap(StringOk(`ok`)))
+}
+
+func TestWrapHan(t *testing.T) {
+	mid := func(han http.Handler) http.Handler {
+		return http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+			rew.Header().Set(`Wrapped`, `true`)
+			han.ServeHTTP(rew, req)
+		})
+	}
+
+	han := WrapHan(func(req *http.Request) http.Handler {
+		if req.URL.Path == `/missing` {
+			return nil
+		}
+		return StringOk(req.URL.Path)
+	}, mid)
+
+	eq(t, nil, han(pathReq(`/missing`)))
+
+	rew := ht.NewRecorder()
+	han(pathReq(`/one`)).ServeHTTP(rew, nil)
+	eq(t, `true`, rew.Header().Get(`Wrapped`))
+	eq(t, `/one`, rew.Body.String())
+}
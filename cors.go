@@ -0,0 +1,167 @@
+package goh
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+/*
+CORS configuration that doubles as an HTTP handler. When serving a preflight
+request, responds with 204 and the configured CORS headers, without invoking
+`.Handler`. For other requests, adds the CORS response headers and delegates
+to `.Handler`. When `.Handler` is nil, non-preflight requests get
+`goh.NotFound`.
+
+`.Origins` may contain "*" to allow any origin. When `.Credentials` is true,
+the wildcard is never sent as-is; the request origin is echoed instead, as
+required by the CORS spec. Empty `.Methods` and `.Headers` are omitted from
+preflight responses. `.MaxAge` is in seconds; 0 means omit.
+
+Example usage:
+
+	var cors = goh.Cors{
+		Origins: []string{`https://example.com`},
+		Methods: []string{http.MethodGet, http.MethodPost},
+		Headers: []string{goh.HeadType},
+	}
+
+	func handler(req *http.Request) http.Handler {
+		return cors.With(goh.JsonOk(someValue))
+	}
+*/
+type Cors struct {
+	Origins     []string
+	Methods     []string
+	Headers     []string
+	Credentials bool
+	MaxAge      int
+	Handler     http.Handler
+}
+
+// Implement `http.Handler`.
+func (self Cors) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Cors`, self.serveHTTP)
+}
+
+func (self Cors) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if self.ServedHTTP(rew, req) {
+		return
+	}
+	self.Apply(rew.Header(), req)
+	orNotFound(self.Handler).ServeHTTP(rew, req)
+}
+
+/*
+Implement `goh.HttpHandlerOpt`. If the request is a CORS preflight, responds to
+it and returns true. Otherwise returns false. Can be used to answer preflight
+requests before routing.
+*/
+func (self Cors) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.Cors`, self.servedHTTP)
+}
+
+func (self Cors) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	if !IsPreflight(req) {
+		return false
+	}
+
+	head := rew.Header()
+	ok := self.Apply(head, req)
+
+	/**
+	Caches may store preflight responses. Their allowed origin already varies by
+	"Origin", and they're only meaningful for the requested method and headers.
+	*/
+	AddVary(head, `Access-Control-Request-Method`, `Access-Control-Request-Headers`)
+
+	if ok {
+		setNonEmpty(head, `Access-Control-Allow-Methods`, strings.Join(self.Methods, `, `))
+		setNonEmpty(head, `Access-Control-Allow-Headers`, strings.Join(self.Headers, `, `))
+		if self.MaxAge > 0 {
+			head.Set(`Access-Control-Max-Age`, strconv.Itoa(self.MaxAge))
+		}
+	}
+
+	writeDefaultHeader(head)
+	rew.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Cors) Han(*http.Request) http.Handler { return self }
+
+// Returns a modified version with the given inner handler.
+func (self Cors) With(val http.Handler) Cors {
+	self.Handler = val
+	return self
+}
+
+/*
+Adds the CORS response headers common to preflight and actual requests:
+"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials", and "Vary".
+Returns true if the request origin is allowed, and false otherwise, in which
+case no CORS headers are added, other than "Vary".
+
+Unless the allowed origin is always the static "*", the response depends on
+the "Origin" header, so "Vary: Origin" is added even to requests without it.
+This prevents caches from serving a response made for one origin, or for no
+origin, to requests from another.
+*/
+func (self Cors) Apply(head http.Header, req *http.Request) bool {
+	origin := req.Header.Get(`Origin`)
+	if !self.staticOrigin() || origin != `` {
+		AddVary(head, `Origin`)
+	}
+	if origin == `` {
+		return false
+	}
+
+	if !self.AllowOrigin(origin) {
+		return false
+	}
+
+	if self.Credentials {
+		head.Set(`Access-Control-Allow-Origin`, origin)
+		head.Set(`Access-Control-Allow-Credentials`, `true`)
+	} else if self.anyOrigin() {
+		head.Set(`Access-Control-Allow-Origin`, `*`)
+	} else {
+		head.Set(`Access-Control-Allow-Origin`, origin)
+	}
+	return true
+}
+
+// True if the given origin is allowed by `.Origins`.
+func (self Cors) AllowOrigin(origin string) bool {
+	for _, val := range self.Origins {
+		if val == `*` || strings.EqualFold(val, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (self Cors) anyOrigin() bool {
+	for _, val := range self.Origins {
+		if val == `*` {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+True if the request is a CORS preflight request: method OPTIONS with headers
+"Origin" and "Access-Control-Request-Method".
+*/
+func IsPreflight(req *http.Request) bool {
+	return req.Method == http.MethodOptions &&
+		req.Header.Get(`Origin`) != `` &&
+		req.Header.Get(`Access-Control-Request-Method`) != ``
+}
+
+// True if the allowed origin doesn't depend on the request origin.
+func (self Cors) staticOrigin() bool {
+	return self.anyOrigin() && !self.Credentials
+}
@@ -0,0 +1,101 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+var (
+	_ = http.Handler(Cors{})
+	_ = HttpHandlerOpt(Cors{})
+	_ = Han(Cors{}.Han)
+)
+
+func corsReq(method, origin string) *http.Request {
+	req := ht.NewRequest(method, `/`, nil)
+	if origin != `` {
+		req.Header.Set(`Origin`, origin)
+	}
+	if method == http.MethodOptions {
+		req.Header.Set(`Access-Control-Request-Method`, http.MethodPost)
+	}
+	return req
+}
+
+func TestCors(t *testing.T) {
+	cors := Cors{
+		Origins: []string{`https://one.com`},
+		Methods: []string{http.MethodGet, http.MethodPost},
+		Headers: []string{HeadType},
+		MaxAge:  600,
+		Handler: StringWith(http.StatusCreated, `ok`),
+	}
+
+	t.Run(`preflight allowed`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		cors.ServeHTTP(rew, corsReq(http.MethodOptions, `https://one.com`))
+
+		eq(t, http.StatusNoContent, rew.Code)
+		eq(t, ``, rew.Body.String())
+		eq(t, http.Header{
+			`Vary`:                         {`Origin`, `Access-Control-Request-Method`, `Access-Control-Request-Headers`},
+			`Access-Control-Allow-Origin`:  {`https://one.com`},
+			`Access-Control-Allow-Methods`: {`GET, POST`},
+			`Access-Control-Allow-Headers`: {HeadType},
+			`Access-Control-Max-Age`:       {`600`},
+		}, rew.Result().Header)
+	})
+
+	t.Run(`preflight denied`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		cors.ServeHTTP(rew, corsReq(http.MethodOptions, `https://two.com`))
+
+		eq(t, http.StatusNoContent, rew.Code)
+		eq(t, http.Header{
+			`Vary`: {`Origin`, `Access-Control-Request-Method`, `Access-Control-Request-Headers`},
+		}, rew.Result().Header)
+	})
+
+	t.Run(`actual request`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		cors.ServeHTTP(rew, corsReq(http.MethodGet, `https://one.com`))
+
+		eq(t, http.StatusCreated, rew.Code)
+		eq(t, `ok`, rew.Body.String())
+		eq(t, http.Header{
+			`Vary`:                        {`Origin`},
+			`Access-Control-Allow-Origin`: {`https://one.com`},
+		}, rew.Result().Header)
+	})
+
+	t.Run(`not cors`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		eq(t, false, cors.ServedHTTP(rew, corsReq(http.MethodGet, ``)))
+		cors.ServeHTTP(rew, corsReq(http.MethodGet, ``))
+
+		eq(t, http.StatusCreated, rew.Code)
+		eq(t, http.Header{`Vary`: {`Origin`}}, rew.Result().Header)
+	})
+
+	t.Run(`wildcard`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Cors{Origins: []string{`*`}}.Apply(rew.Header(), corsReq(http.MethodGet, `https://two.com`))
+		eq(t, `*`, rew.Header().Get(`Access-Control-Allow-Origin`))
+
+		rew = ht.NewRecorder()
+		eq(t, false, Cors{Origins: []string{`*`}}.Apply(rew.Header(), corsReq(http.MethodGet, ``)))
+		eq(t, http.Header{}, rew.Header())
+	})
+
+	t.Run(`wildcard with credentials`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Cors{Origins: []string{`*`}, Credentials: true}.Apply(rew.Header(), corsReq(http.MethodGet, `https://two.com`))
+		eq(t, `https://two.com`, rew.Header().Get(`Access-Control-Allow-Origin`))
+		eq(t, `true`, rew.Header().Get(`Access-Control-Allow-Credentials`))
+
+		rew = ht.NewRecorder()
+		Cors{Origins: []string{`*`}, Credentials: true}.Apply(rew.Header(), corsReq(http.MethodGet, ``))
+		eq(t, http.Header{`Vary`: {`Origin`}}, rew.Header())
+	})
+}
@@ -0,0 +1,35 @@
+package goh
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Name of the RFC 9530 "Content-Digest" header.
+const HeadContentDigest = `Content-Digest`
+
+/*
+Returns an RFC 9530 "Content-Digest" header value of the given content, using
+the SHA-256 algorithm, such as
+"sha-256=:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=:".
+*/
+func ContentDigest(src []byte) string {
+	sum := sha256.Sum256(src)
+	return `sha-256=:` + base64.StdEncoding.EncodeToString(sum[:]) + `:`
+}
+
+/*
+Returns a copy with the "Content-Digest" header of `.Body`, see
+`goh.ContentDigest`. The digest is computed once, when calling this method,
+rather than on each request, which makes it suitable for pre-encoded responses
+stored in global variables:
+
+	var someHan = goh.JsonOk(someValue).TryBytes().WithContentDigest()
+
+Doesn't mutate the original header. `.Body` must not be modified afterwards.
+*/
+func (self Bytes) WithContentDigest() Bytes {
+	self.Header = cloneHeader(self.Header)
+	self.Header.Set(HeadContentDigest, ContentDigest(self.Body))
+	return self
+}
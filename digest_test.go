@@ -0,0 +1,53 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContentDigest(t *testing.T) {
+	eq(t, `sha-256=:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=:`, ContentDigest(nil))
+	eq(t, `sha-256=:ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=:`, ContentDigest([]byte(`abc`)))
+}
+
+func TestBytes_WithContentDigest(t *testing.T) {
+	src := http.Header{`One`: {`two`}}
+	han := Bytes{Header: src, Body: []byte(`abc`)}.WithContentDigest()
+
+	eq(t, http.Header{`One`: {`two`}}, src)
+	eq(t, ContentDigest([]byte(`abc`)), han.Header.Get(HeadContentDigest))
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+	eq(t, ContentDigest([]byte(`abc`)), rew.Header().Get(HeadContentDigest))
+	eq(t, `two`, rew.Header().Get(`One`))
+
+	han = JsonOk([]int{10}).TryBytes().WithContentDigest()
+	eq(t, ContentDigest([]byte(`[10]`)), han.Header.Get(HeadContentDigest))
+}
+
+func TestMemDir_ContentDigest(t *testing.T) {
+	root := t.TempDir()
+	body := strings.Repeat(`body {} `, 64)
+	writeFile(filepath.Join(root, `one.css`), body)
+
+	mem, err := MemDir{Dir: Dir{Path: root}}.Load()
+	try(err)
+	eq(t, ``, mem.Files[`one.css`].Plain.Header.Get(HeadContentDigest))
+
+	mem, err = MemDir{Dir: Dir{Path: root}, Gzip: true, ContentDigest: true}.Load()
+	try(err)
+
+	file := mem.Files[`one.css`]
+	eq(t, ContentDigest([]byte(body)), file.Plain.Header.Get(HeadContentDigest))
+	eq(t, ContentDigest(file.Gzipped.Body), file.Gzipped.Header.Get(HeadContentDigest))
+
+	req := ht.NewRequest(http.MethodGet, `/one.css`, nil)
+	req.Header.Set(`Accept-Encoding`, `gzip`)
+	rew := ht.NewRecorder()
+	mem.ServeHTTP(rew, req)
+	eq(t, ContentDigest(rew.Body.Bytes()), rew.Header().Get(HeadContentDigest))
+}
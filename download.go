@@ -0,0 +1,118 @@
+package goh
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	DispositionInline     = `inline`
+	DispositionAttachment = `attachment`
+)
+
+/*
+HTTP handler that sets "Content-Disposition: attachment" with the given file
+name, then delegates to `.Handler`, prompting browsers to download the response
+rather than display it. When `.Handler` is nil, responds with `goh.NotFound`,
+without the disposition header. Works with any handler, such as `goh.File`,
+`goh.Bytes`, or `goh.Reader`. Example usage:
+
+	func handler(req *http.Request) http.Handler {
+		return goh.Download{Name: `report.csv`, Handler: goh.BytesOk(csvBytes)}
+	}
+
+See `goh.ContentDisposition` for the encoding of file names.
+*/
+type Download struct {
+	Name    string
+	Handler http.Handler
+}
+
+// Implement `http.Handler`.
+func (self Download) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Download`, self.serveHTTP)
+}
+
+func (self Download) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if self.Handler == nil {
+		NotFound{}.ServeHTTP(rew, req)
+		return
+	}
+	rew.Header().Set(`Content-Disposition`, ContentDisposition(DispositionAttachment, self.Name))
+	self.Handler.ServeHTTP(rew, req)
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Download) Han(*http.Request) http.Handler { return self }
+
+/*
+Formats a "Content-Disposition" header value as described in RFC 6266. The kind
+is usually `goh.DispositionInline` or `goh.DispositionAttachment`. Empty kind
+is treated as attachment. Empty name is omitted.
+
+The name is always included as an ASCII quoted string, where non-ASCII and
+control characters are replaced with underscores. When the name contains any
+characters that can't be represented this way, it's also included in the
+extended form "filename*" described in RFC 5987, which is UTF-8 and
+percent-encoded. Example outputs:
+
+	attachment; filename="report.csv"
+	attachment; filename="___.txt"; filename*=UTF-8''%D1%84%D0%B0%D0%B9.txt
+*/
+func ContentDisposition(kind, name string) string {
+	if kind == `` {
+		kind = DispositionAttachment
+	}
+	if name == `` {
+		return kind
+	}
+
+	var buf strings.Builder
+	buf.WriteString(kind)
+	buf.WriteString(`; filename="`)
+
+	ascii := true
+	for _, char := range name {
+		if char < 0x20 || char >= 0x7f {
+			buf.WriteByte('_')
+			ascii = false
+			continue
+		}
+		if char == '"' || char == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(char)
+	}
+	buf.WriteByte('"')
+
+	if !ascii {
+		buf.WriteString(`; filename*=`)
+		writeExtValue(&buf, name)
+	}
+	return buf.String()
+}
+
+// Writes an RFC 8187 "ext-value" in UTF-8, percent-encoding non-"attr-char".
+func writeExtValue(buf *strings.Builder, val string) {
+	buf.WriteString(`UTF-8''`)
+	for ind := 0; ind < len(val); ind++ {
+		char := val[ind]
+		if isAttrChar(char) {
+			buf.WriteByte(char)
+		} else {
+			buf.WriteByte('%')
+			buf.WriteByte(hexDigits[char>>4])
+			buf.WriteByte(hexDigits[char&0xf])
+		}
+	}
+}
+
+const hexDigits = `0123456789ABCDEF`
+
+// See "attr-char" in RFC 5987.
+func isAttrChar(char byte) bool {
+	return char >= 'a' && char <= 'z' ||
+		char >= 'A' && char <= 'Z' ||
+		char >= '0' && char <= '9' ||
+		strings.IndexByte("!#$&+-.^_`|~", char) >= 0
+}
@@ -0,0 +1,46 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+var (
+	_ = http.Handler(Download{})
+	_ = Han(Download{}.Han)
+)
+
+func TestContentDisposition(t *testing.T) {
+	eq(t, `attachment`, ContentDisposition(``, ``))
+	eq(t, `inline`, ContentDisposition(DispositionInline, ``))
+	eq(t, `attachment; filename="report.csv"`, ContentDisposition(``, `report.csv`))
+	eq(t, `inline; filename="one two.txt"`, ContentDisposition(DispositionInline, `one two.txt`))
+	eq(t, `attachment; filename="one \"two\" \\ three"`, ContentDisposition(``, `one "two" \ three`))
+
+	eq(
+		t,
+		`attachment; filename="____.txt"; filename*=UTF-8''%D1%84%D0%B0%D0%B9%D0%BB.txt`,
+		ContentDisposition(``, `файл.txt`),
+	)
+
+	eq(
+		t,
+		`attachment; filename="one__two"; filename*=UTF-8''one%0D%0Atwo`,
+		ContentDisposition(``, "one\r\ntwo"),
+	)
+}
+
+func TestDownload(t *testing.T) {
+	rew := ht.NewRecorder()
+	Download{Name: `one.txt`, Handler: StringWith(201, `one`)}.ServeHTTP(rew, nil)
+
+	eq(t, 201, rew.Code)
+	eq(t, `one`, rew.Body.String())
+	eq(t, `attachment; filename="one.txt"`, rew.Header().Get(`Content-Disposition`))
+
+	rew = ht.NewRecorder()
+	Download{Name: `one.txt`}.ServeHTTP(rew, nil)
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, ``, rew.Header().Get(`Content-Disposition`))
+}
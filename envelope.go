@@ -0,0 +1,125 @@
+package goh
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+/*
+Field names used by `goh.Envelope`. Empty fields default to the corresponding
+fields of `goh.DefaultEnvelopeKeys`.
+*/
+type EnvelopeKeys struct {
+	Data  string
+	Error string
+	Meta  string
+}
+
+/*
+Default field names of `goh.Envelope`. May be modified at program startup to
+match the conventions of an API.
+*/
+var DefaultEnvelopeKeys = EnvelopeKeys{Data: `data`, Error: `error`, Meta: `meta`}
+
+/*
+JSON body that wraps the response data in a consistent envelope, as required by
+many API conventions. Intended for `goh.Json`:
+
+	goh.JsonOk(goh.Envelope{Data: users, Meta: map[string]int{`total`: 10}})
+
+Output:
+
+	{"data":[...],"error":null,"meta":{"total":10}}
+
+"data" and "error" are always present, and null when empty. "meta" is omitted
+when nil. For error responses in the same format, see `goh.ErrEnvelope` and
+`goh.ErrRenderEnvelope`.
+*/
+type Envelope struct {
+	Keys  EnvelopeKeys
+	Data  interface{}
+	Error interface{}
+	Meta  interface{}
+}
+
+// Implement `json.Marshaler`.
+func (self Envelope) MarshalJSON() ([]byte, error) {
+	keys := self.keys()
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	err := envelopeField(&buf, keys.Data, self.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteByte(',')
+	err = envelopeField(&buf, keys.Error, self.Error)
+	if err != nil {
+		return nil, err
+	}
+
+	if self.Meta != nil {
+		buf.WriteByte(',')
+		err = envelopeField(&buf, keys.Meta, self.Meta)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (self Envelope) keys() EnvelopeKeys {
+	return EnvelopeKeys{
+		Data:  orStr(self.Keys.Data, DefaultEnvelopeKeys.Data),
+		Error: orStr(self.Keys.Error, DefaultEnvelopeKeys.Error),
+		Meta:  orStr(self.Keys.Meta, DefaultEnvelopeKeys.Meta),
+	}
+}
+
+func envelopeField(buf *bytes.Buffer, key string, val interface{}) error {
+	keyBytes, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	valBytes, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(keyBytes)
+	buf.WriteByte(':')
+	buf.Write(valBytes)
+	return nil
+}
+
+// Shortcut for `goh.Json` with the given status and `goh.Envelope` body.
+func JsonEnvelope(status int, data, meta interface{}) Json {
+	return JsonWith(status, Envelope{Data: data, Meta: meta})
+}
+
+/*
+Variant of `goh.ErrJson` that serves `goh.ErrBody` in `goh.Envelope`, with null
+data. When the status is 0, it's determined by `goh.ErrHttpStatus`.
+*/
+func ErrEnvelope(status int, err error) Json {
+	status = orErrStatus(status, err)
+	return Json{Status: status, Header: errHeader(err), Body: Envelope{Error: ErrBodyFrom(err, status)}}
+}
+
+/*
+Implements `goh.ErrRenderer` by writing `goh.ErrBody` in `goh.Envelope` as JSON.
+To render all JSON errors in this format, register it at program startup:
+
+	goh.ErrRenderers[goh.TypeJson] = goh.ErrRenderEnvelope
+*/
+func ErrRenderEnvelope(rew http.ResponseWriter, _ *http.Request, err error, status int) error {
+	rew.Header().Set(HeadType, TypeJson)
+	rew.WriteHeader(status)
+	return json.NewEncoder(rew).Encode(Envelope{Error: ErrBodyFrom(err, status)})
+}
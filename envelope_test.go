@@ -0,0 +1,56 @@
+package goh
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	test := func(exp string, val Envelope) {
+		t.Helper()
+		out, err := json.Marshal(val)
+		try(err)
+		eq(t, exp, string(out))
+	}
+
+	test(`{"data":null,"error":null}`, Envelope{})
+	test(`{"data":[1,2],"error":null,"meta":{"total":2}}`, Envelope{Data: []int{1, 2}, Meta: map[string]int{`total`: 2}})
+	test(`{"result":"one","err":null,"info":10}`, Envelope{Keys: EnvelopeKeys{`result`, `err`, `info`}, Data: `one`, Meta: 10})
+	test(`{"result":"one","error":null}`, Envelope{Keys: EnvelopeKeys{Data: `result`}, Data: `one`})
+
+	_, err := json.Marshal(Envelope{Data: func() {}})
+	eq(t, true, err != nil)
+
+	t.Run(`json`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		JsonEnvelope(http.StatusCreated, `one`, nil).ServeHTTP(rew, nil)
+		eq(t, http.StatusCreated, rew.Code)
+		eq(t, `{"data":"one","error":null}`, strings.TrimSpace(rew.Body.String()))
+	})
+
+	t.Run(`error`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		ErrEnvelope(0, ErrNotFound(`missing`)).ServeHTTP(rew, nil)
+		eq(t, http.StatusNotFound, rew.Code)
+		eq(t, `{"data":null,"error":{"error":"missing","status":404}}`, strings.TrimSpace(rew.Body.String()))
+	})
+
+	t.Run(`renderer`, func(t *testing.T) {
+		prev := ErrRenderers[TypeJson]
+		ErrRenderers[TypeJson] = ErrRenderEnvelope
+		t.Cleanup(func() { ErrRenderers[TypeJson] = prev })
+
+		req := pathReq(`/`)
+		req.Header = http.Header{`Accept`: {TypeJson}}
+
+		rew := ht.NewRecorder()
+		WriteErr(rew, req, errors.New(`fail`), false)
+		eq(t, http.StatusInternalServerError, rew.Code)
+		eq(t, TypeJson, rew.Header().Get(HeadType))
+		eq(t, `{"data":null,"error":{"error":"fail","status":500}}`, strings.TrimSpace(rew.Body.String()))
+	})
+}
@@ -0,0 +1,632 @@
+package goh
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Interface for errors that specify an HTTP status. Used by `goh.Err`,
+`goh.WriteErr`, and the panic recovery in `goh.Handler`, which find the first
+error implementing this interface in the error chain via `errors.As`. See
+`goh.ErrHttpStatus`.
+*/
+type HttpStatus interface{ HTTPStatus() int }
+
+/*
+Error wrapper that carries an HTTP status, and optionally headers to be sent
+with the error response, such as "Retry-After" or "WWW-Authenticate".
+Recognized by `goh.Err`, `goh.WriteErr`, and `goh.Handler`. Example usage:
+
+	func handler(req *http.Request) http.Handler {
+		return goh.Handler(func() http.Handler {
+			user, err := findUser(req)
+			if err != nil {
+				panic(goh.ErrStatus{Status: http.StatusNotFound, Err: err})
+			}
+			return goh.JsonOk(user)
+		})
+	}
+
+When `.Err` is nil, the error message is the standard text of the status.
+
+Also implements `http.Handler`, serving itself via `goh.WriteErr`, which allows to
+return it directly from request->response functions. See the constructors such
+as `goh.ErrNotFound`.
+*/
+type ErrStatus struct {
+	Status int
+	Header http.Header
+	Err    error
+}
+
+// Implement `error`.
+func (self ErrStatus) Error() string {
+	if self.Err != nil {
+		return self.Err.Error()
+	}
+	return http.StatusText(self.Status)
+}
+
+// Implement error unwrapping, for `errors.Is` and `errors.As`.
+func (self ErrStatus) Unwrap() error { return self.Err }
+
+// Implement `goh.HttpStatus`.
+func (self ErrStatus) HTTPStatus() int { return self.Status }
+
+/*
+Implement `http.Handler` by serving the error via `goh.WriteErr`, which uses
+plain text or a structured format depending on the request.
+*/
+func (self ErrStatus) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.ErrStatus`, self.serveHTTP)
+}
+
+func (self ErrStatus) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	WriteErr(rew, req, self, false)
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self ErrStatus) Han(*http.Request) http.Handler { return self }
+
+/*
+Shortcut for `goh.ErrStatus` with the given status and message. Empty message
+is replaced with the standard text of the status. The following shortcuts are
+provided for common statuses, and can be used with panics in `goh.Handler`:
+
+	panic(goh.ErrNotFound(`user not found`))
+*/
+func ErrWith(status int, msg string) ErrStatus {
+	out := ErrStatus{Status: status}
+	if msg != `` {
+		out.Err = errors.New(msg)
+	}
+	return out
+}
+
+// Shortcut for `goh.ErrWith(http.StatusBadRequest, msg)`.
+func ErrBadRequest(msg string) ErrStatus { return ErrWith(http.StatusBadRequest, msg) }
+
+// Shortcut for `goh.ErrWith(http.StatusUnauthorized, msg)`.
+func ErrUnauthorized(msg string) ErrStatus { return ErrWith(http.StatusUnauthorized, msg) }
+
+// Shortcut for `goh.ErrWith(http.StatusForbidden, msg)`.
+func ErrForbidden(msg string) ErrStatus { return ErrWith(http.StatusForbidden, msg) }
+
+// Shortcut for `goh.ErrWith(http.StatusNotFound, msg)`.
+func ErrNotFound(msg string) ErrStatus { return ErrWith(http.StatusNotFound, msg) }
+
+// Shortcut for `goh.ErrWith(http.StatusConflict, msg)`.
+func ErrConflict(msg string) ErrStatus { return ErrWith(http.StatusConflict, msg) }
+
+// Shortcut for `goh.ErrWith(http.StatusTooManyRequests, msg)`.
+func ErrTooManyRequests(msg string) ErrStatus {
+	return ErrWith(http.StatusTooManyRequests, msg)
+}
+
+/*
+Returns the HTTP status for the given error: the status of the first error in
+the chain implementing `goh.HttpStatus`, if it's an error status between 400
+and 599, otherwise 500. Other statuses, such as informational 1xx, would make
+the error response invalid.
+*/
+func ErrHttpStatus(err error) int {
+	var val HttpStatus
+	if errors.As(err, &val) {
+		status := val.HTTPStatus()
+		if status >= 400 && status <= 599 {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// Returns the header of the first `goh.ErrStatus` in the error chain, if any.
+func errHeader(err error) http.Header {
+	var val ErrStatus
+	if errors.As(err, &val) {
+		return val.Header
+	}
+	return nil
+}
+
+/*
+Function that renders an error response with the given status, in a specific
+format. Must write the content type, the status, and the body. Used by
+`goh.WriteErr` via `goh.ErrRenderers`.
+*/
+type ErrRenderer = func(rew http.ResponseWriter, req *http.Request, err error, status int) error
+
+/*
+Registry of structured error formats used by `goh.WriteErr`, keyed by media
+type. May be modified at program startup to add, replace, or remove formats.
+Plain text is the implicit default and is not registered here.
+*/
+var ErrRenderers = map[string]ErrRenderer{
+	TypeJson:        ErrRenderJson,
+	TypeProblemJson: ErrRenderProblem,
+	TypeXml:         ErrRenderXml,
+	TypeJsonApi:     ErrRenderJsonApi,
+}
+
+/*
+Structured body of error responses rendered by `goh.ErrRenderJson` and
+`goh.ErrRenderXml`.
+*/
+type ErrBody struct {
+	XMLName xml.Name `json:"-" xml:"error"`
+	Error   string   `json:"error" xml:"message"`
+	Status  int      `json:"status" xml:"status"`
+}
+
+// Returns the structured body for the given error and status.
+func ErrBodyFrom(err error, status int) ErrBody {
+	return ErrBody{Error: errMsg(err), Status: status}
+}
+
+// Implements `goh.ErrRenderer` by writing `goh.ErrBody` as JSON.
+func ErrRenderJson(rew http.ResponseWriter, _ *http.Request, err error, status int) error {
+	rew.Header().Set(HeadType, TypeJson)
+	rew.WriteHeader(status)
+	return json.NewEncoder(rew).Encode(ErrBodyFrom(err, status))
+}
+
+// Implements `goh.ErrRenderer` by writing `goh.ErrBody` as XML.
+func ErrRenderXml(rew http.ResponseWriter, _ *http.Request, err error, status int) error {
+	rew.Header().Set(HeadType, TypeXml)
+	rew.WriteHeader(status)
+	return xml.NewEncoder(rew).Encode(ErrBodyFrom(err, status))
+}
+
+/*
+Structured variant of `goh.Err` that serves `goh.ErrBody` as JSON. When the
+status is 0, it's determined by `goh.ErrHttpStatus`. The header of
+`goh.ErrStatus` in the error chain, if any, is included.
+*/
+func ErrJson(status int, err error) Json {
+	status = orErrStatus(status, err)
+	return Json{Status: status, Header: errHeader(err), Body: ErrBodyFrom(err, status)}
+}
+
+// Same as `goh.ErrJson`, but serves `goh.ErrBody` as XML.
+func ErrXml(status int, err error) Xml {
+	status = orErrStatus(status, err)
+	return Xml{Status: status, Header: errHeader(err), Body: ErrBodyFrom(err, status)}
+}
+
+func orErrStatus(status int, err error) int {
+	if status != 0 {
+		return status
+	}
+	return ErrHttpStatus(err)
+}
+
+/*
+Body of "problem details" error responses described in RFC 9457, rendered by
+`goh.ErrRenderProblem`.
+*/
+type Problem struct {
+	Type   string `json:"type,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+/*
+Implements `goh.ErrRenderer` by writing `goh.Problem` as JSON, with the content
+type "application/problem+json".
+*/
+func ErrRenderProblem(rew http.ResponseWriter, _ *http.Request, err error, status int) error {
+	rew.Header().Set(HeadType, TypeProblemJson)
+	rew.WriteHeader(status)
+	return json.NewEncoder(rew).Encode(Problem{
+		Type:   `about:blank`,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: errMsg(err),
+	})
+}
+
+/*
+Chooses the error renderer for the response: by the content type already set on
+the response, if registered, otherwise by the most preferred registered type in
+the request's "Accept" header, but only if it ranks strictly above every other
+acceptable type, including "text/html" and the wildcard type. Returns nil for
+plain text. This way, browsers, which accept HTML and the wildcard at least as
+much as any structured type such as XML, receive plain text.
+*/
+func errRenderer(rew http.ResponseWriter, req *http.Request) ErrRenderer {
+	conType, _ := cutStr(rew.Header().Get(HeadType), `;`)
+	if render := ErrRenderers[strings.TrimSpace(conType)]; render != nil {
+		return render
+	}
+
+	var out ErrRenderer
+	var outQuality, otherQuality float64
+
+	for _, val := range acceptedVals(req, `Accept`) {
+		if val.quality <= 0 {
+			continue
+		}
+
+		render := ErrRenderers[val.name]
+		if render == nil {
+			if val.quality > otherQuality {
+				otherQuality = val.quality
+			}
+		} else if out == nil {
+			out, outQuality = render, val.quality
+		}
+	}
+
+	if out != nil && outQuality > otherQuality {
+		return out
+	}
+	return nil
+}
+
+/*
+Single value of an "Accept" or "Accept-*" request header, with the lowercased
+name and the quality, which defaults to 1. Invalid qualities are treated as 0.
+*/
+type acceptedVal struct {
+	name    string
+	quality float64
+}
+
+/*
+Parses the given "Accept" or "Accept-*" request header, such as
+"Accept-Encoding", including values with zero quality, which explicitly
+reject the value. Sorted by quality in descending order, preserving the
+original order among equal qualities.
+*/
+func acceptedVals(req *http.Request, key string) []acceptedVal {
+	if req == nil {
+		return nil
+	}
+
+	var out []acceptedVal
+	for _, head := range req.Header.Values(key) {
+		for _, val := range strings.Split(head, `,`) {
+			name, params := cutStr(strings.TrimSpace(val), `;`)
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != `` {
+				out = append(out, acceptedVal{name, paramQuality(params)})
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(one, two int) bool {
+		return out[one].quality > out[two].quality
+	})
+	return out
+}
+
+// Returns the "q" parameter, defaulting to 1.
+func paramQuality(params string) float64 {
+	for _, val := range strings.Split(params, `;`) {
+		key, val := cutStr(strings.TrimSpace(val), `=`)
+		if strings.TrimSpace(key) == `q` {
+			num, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+			if err != nil {
+				return 0
+			}
+			return num
+		}
+	}
+	return 1
+}
+
+/*
+Package-level registry of error pages keyed by HTTP status, for serving branded
+error pages without wrapping every handler. Consulted by `goh.NotFound`,
+`goh.MethodNotAllowed`, and `goh.WriteErr`, and therefore by the misses of
+`goh.File` and `goh.Dir`, and by `goh.ErrStatus`. `goh.File` and `goh.Dir` also
+have their own `.ErrorPages`, which take priority for their 404 responses.
+Example:
+
+	goh.ErrorPages = map[int]http.Handler{
+		http.StatusNotFound: goh.File{Status: http.StatusNotFound, Path: `static/404.html`},
+	}
+
+Pages are responsible for writing the status. When a page implements
+`goh.HttpHandlerOpt` and doesn't serve the request, for example when a
+`goh.File` page is missing, the default response is used instead. `goh.Err`
+returns a pre-built response and doesn't consult this registry.
+
+Should be set at program startup, before serving requests.
+*/
+var ErrorPages map[int]http.Handler
+
+/*
+Writes the error response with the given status: the page from
+`goh.ErrorPages`, if any, otherwise the error in the format chosen by
+`goh.ErrRenderers`, otherwise plain text. Returns the error from writing the
+response, if any.
+*/
+func writeErrResponse(rew http.ResponseWriter, req *http.Request, err error, status int) error {
+	if serveErrorPage(status, rew, req) {
+		return nil
+	}
+	if render := errRenderer(rew, req); render != nil {
+		return render(rew, req, err, status)
+	}
+	rew.WriteHeader(status)
+
+	msg := err.Error()
+	if ShowStackTraces {
+		if stack := errStack(err); stack != `` {
+			msg += "\n\n" + stack
+		}
+	}
+
+	_, err = io.WriteString(rew, msg)
+	return err
+}
+
+func serveErrorPage(status int, rew http.ResponseWriter, req *http.Request) bool {
+	return servedHTTP(ErrorPages[status], rew, req)
+}
+
+/*
+Production mode for `goh.WriteErr`. When true, errors with statuses 500 and
+above are not sent to clients, since their messages may leak internal details.
+Instead, clients receive `goh.ErrRedacted` with a random error ID, and the
+original error is logged to the standard error stream along with the same ID,
+which allows to match client reports to server logs. Errors with lower
+statuses, such as `goh.ErrNotFound`, are considered safe to show.
+
+Should be set at program startup, before serving requests.
+*/
+var RedactErrors = false
+
+/*
+Error sent to clients instead of the original error when `goh.RedactErrors` is
+enabled. Has a generic message that includes the error ID.
+*/
+type ErrRedacted struct {
+	Status int
+	Id     string
+}
+
+// Implement `error`.
+func (self ErrRedacted) Error() string {
+	return fmt.Sprintf(`internal server error (error id: %v)`, self.Id)
+}
+
+// Implement `goh.HttpStatus`.
+func (self ErrRedacted) HTTPStatus() int { return self.Status }
+
+/*
+Replaces the error with `goh.ErrRedacted`, logging the original error unless it
+already has an ID from `goh.WriteErrWithId`, which logs it.
+*/
+func redactErr(rew http.ResponseWriter, err error, status int) error {
+	id, ok := errId(err)
+	if !ok {
+		logErrId(id, err)
+		rew.Header().Set(HeadErrId, id)
+	}
+	return ErrRedacted{Status: status, Id: id}
+}
+
+/*
+Returns the ID of the first `goh.ErrWithId` in the error chain and true, or a
+new random ID and false.
+*/
+func errId(err error) (string, bool) {
+	var val ErrWithId
+	if errors.As(err, &val) {
+		return val.Id, true
+	}
+	return NewErrId(), false
+}
+
+// Header used by `goh.WriteErrWithId` and `goh.RedactErrors` for error IDs.
+const HeadErrId = `X-Error-Id`
+
+/*
+Error wrapper that carries a correlation ID, used by `goh.WriteErrWithId`. The
+message includes the ID. Unwraps to the original error, preserving its status.
+*/
+type ErrWithId struct {
+	Err error
+	Id  string
+}
+
+// Implement `error`.
+func (self ErrWithId) Error() string {
+	return fmt.Sprintf(`%v (error id: %v)`, errMsg(self.Err), self.Id)
+}
+
+// Implement error unwrapping, for `errors.Is` and `errors.As`.
+func (self ErrWithId) Unwrap() error { return self.Err }
+
+/*
+Variant of `goh.WriteErr` that uses correlation IDs, which allow to match
+client reports to server logs. May be used as `goh.HandleErr` or `.ErrFunc`.
+Generates a random ID via `goh.NewErrId`, and logs the error along with the ID
+to the standard error stream. When the response hasn't been written yet, sends
+the ID in the "X-Error-Id" header, and serves the error via `goh.WriteErr`,
+wrapped in `goh.ErrWithId`, whose message includes the ID.
+*/
+func WriteErrWithId(rew http.ResponseWriter, req *http.Request, err error, wrote bool) {
+	if err == nil {
+		return
+	}
+
+	id := NewErrId()
+	logErrId(id, err)
+	if wrote {
+		return
+	}
+
+	rew.Header().Set(HeadErrId, id)
+	WriteErr(rew, req, ErrWithId{err, id}, false)
+}
+
+func logErrId(id string, err error) {
+	fmt.Fprintf(os.Stderr, "error %v while serving HTTP request: %+v\n", id, err)
+}
+
+// Returns a random error ID: 16 hexadecimal characters.
+func NewErrId() string {
+	var buf [8]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+/*
+Combines several error handlers into one, which calls them in order, for
+example to write the response, then log the error, then report it elsewhere:
+
+	goh.HandleErr = goh.ErrFuncs(goh.WriteErr, reportErr)
+
+Nil functions are skipped. Once a function writes to the response, either the
+header or the body, the following functions receive `wrote = true`, which
+prevents them from writing another error response.
+*/
+func ErrFuncs(funs ...ErrFunc) ErrFunc {
+	return func(rew http.ResponseWriter, req *http.Request, err error, wrote bool) {
+		out := &wroteWriter{ResponseWriter: rew, wrote: wrote}
+		for _, fun := range funs {
+			if fun != nil {
+				fun(out, req, err, out.wrote)
+			}
+		}
+	}
+}
+
+// Response writer that tracks whether the header or body has been written.
+type wroteWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+func (self *wroteWriter) WriteHeader(status int) {
+	self.wrote = true
+	self.ResponseWriter.WriteHeader(status)
+}
+
+func (self *wroteWriter) Write(chunk []byte) (int, error) {
+	self.wrote = true
+	return self.ResponseWriter.Write(chunk)
+}
+
+/*
+Error wrapper with details about the failed response, passed to `goh.ErrFunc`
+by the handlers in this package, which allows error handlers to make better
+decisions than the boolean `wrote` alone. The message and unwrapping are
+delegated to the original error, so `errors.Is` and `goh.ErrHttpStatus` work
+as usual. Use `goh.ErrInfoOf` to obtain the details.
+
+`.Handler` is the name of the handler type that failed, such as "goh.Json".
+`.Status` is the status already sent before the error, or 0 if the header
+wasn't written. `.Written` is the number of body bytes written before the
+error. `.Wrote` is the same as the `wrote` parameter of `goh.ErrFunc`.
+*/
+type ErrInfo struct {
+	Err     error
+	Handler string
+	Status  int
+	Written int64
+	Wrote   bool
+}
+
+// Implement `error`.
+func (self ErrInfo) Error() string { return errMsg(self.Err) }
+
+// Implement error unwrapping, for `errors.Is` and `errors.As`.
+func (self ErrInfo) Unwrap() error { return self.Err }
+
+// Returns the first `goh.ErrInfo` in the error chain, if any.
+func ErrInfoOf(err error) (ErrInfo, bool) {
+	var val ErrInfo
+	ok := errors.As(err, &val)
+	return val, ok
+}
+
+/*
+Error describing a panic caught by `goh.Handler` or `goh.Respond`, along with
+the stack trace captured at recovery. When the panic value is an error, the
+message and unwrapping are delegated to it, which preserves its status for
+`goh.ErrHttpStatus`. Formatting with "%+v" includes the stack trace, so it's
+included in the logs of `goh.WriteErr` and `goh.WriteErrWithId`.
+
+Also implements `http.Handler` by passing itself to `goh.HandleErr`. When
+`goh.ShowStackTraces` is true, plain text error responses include the stack
+trace.
+*/
+type ErrPanic struct {
+	Val   interface{}
+	Stack string
+}
+
+// Implement `error`.
+func (self ErrPanic) Error() string {
+	if err, _ := self.Val.(error); err != nil {
+		return err.Error()
+	}
+	return fmt.Sprint(self.Val)
+}
+
+// Implement error unwrapping, for `errors.Is` and `errors.As`.
+func (self ErrPanic) Unwrap() error {
+	err, _ := self.Val.(error)
+	return err
+}
+
+// Returns the stack trace captured at recovery.
+func (self ErrPanic) StackTrace() string { return self.Stack }
+
+// Implement `fmt.Formatter`. The format "%+v" includes the stack trace.
+func (self ErrPanic) Format(out fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if out.Flag('+') {
+			fmt.Fprintf(out, "%+v\n%v", self.Val, self.Stack)
+			return
+		}
+		_, _ = io.WriteString(out, self.Error())
+	case 'q':
+		fmt.Fprintf(out, `%q`, self.Error())
+	default:
+		_, _ = io.WriteString(out, self.Error())
+	}
+}
+
+// Implement `http.Handler` by passing itself to `goh.HandleErr`.
+func (self ErrPanic) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.ErrPanic`, self.serveHTTP)
+}
+
+func (self ErrPanic) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	HandleErr(rew, req, self, false)
+}
+
+/*
+Development mode for `goh.WriteErr`. When true, plain text error responses
+include the stack trace of errors that provide one via a method
+`.StackTrace() string`, such as `goh.ErrPanic`. Should not be enabled in
+production.
+*/
+var ShowStackTraces = false
+
+// Returns the stack trace of the first error in the chain that has one.
+func errStack(err error) string {
+	var val interface{ StackTrace() string }
+	if errors.As(err, &val) {
+		return val.StackTrace()
+	}
+	return ``
+}
@@ -0,0 +1,457 @@
+package goh
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var (
+	_ = HttpStatus(ErrStatus{})
+	_ = http.Handler(ErrStatus{})
+	_ = Han(ErrStatus{}.Han)
+)
+
+type statusErr int
+
+func (self statusErr) Error() string   { return `status error` }
+func (self statusErr) HTTPStatus() int { return int(self) }
+
+func TestErrStatus(t *testing.T) {
+	eq(t, `Not Found`, ErrStatus{Status: http.StatusNotFound}.Error())
+	eq(t, `fail`, ErrStatus{Status: http.StatusNotFound, Err: errors.New(`fail`)}.Error())
+
+	inner := errors.New(`fail`)
+	eq(t, true, errors.Is(ErrStatus{Err: inner}, inner))
+}
+
+func TestErrHttpStatus(t *testing.T) {
+	eq(t, 500, ErrHttpStatus(nil))
+	eq(t, 500, ErrHttpStatus(errors.New(`fail`)))
+	eq(t, 500, ErrHttpStatus(ErrStatus{}))
+	eq(t, 500, ErrHttpStatus(statusErr(1000)))
+	eq(t, 500, ErrHttpStatus(statusErr(103)))
+	eq(t, 500, ErrHttpStatus(statusErr(302)))
+	eq(t, 500, ErrHttpStatus(statusErr(600)))
+	eq(t, 599, ErrHttpStatus(statusErr(599)))
+	eq(t, 404, ErrHttpStatus(ErrStatus{Status: 404}))
+	eq(t, 409, ErrHttpStatus(statusErr(409)))
+	eq(t, 404, ErrHttpStatus(fmt.Errorf(`wrapped: %w`, ErrStatus{Status: 404})))
+}
+
+func TestErr_ErrStatus(t *testing.T) {
+	err := fmt.Errorf(`wrapped: %w`, ErrStatus{
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{`Retry-After`: {`10`}},
+		Err:    errors.New(`slow down`),
+	})
+
+	rew := ht.NewRecorder()
+	Err(err).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusTooManyRequests, rew.Code)
+	eq(t, `10`, rew.Header().Get(`Retry-After`))
+	eq(t, `wrapped: slow down`, rew.Body.String())
+}
+
+func TestWriteErr_ErrStatus(t *testing.T) {
+	rew := ht.NewRecorder()
+	WriteErr(rew, nil, ErrStatus{
+		Status: http.StatusUnauthorized,
+		Header: http.Header{`Www-Authenticate`: {`Basic`}},
+	}, false)
+
+	eq(t, http.StatusUnauthorized, rew.Code)
+	eq(t, `Basic`, rew.Header().Get(`Www-Authenticate`))
+	eq(t, `Unauthorized`, rew.Body.String())
+}
+
+func TestHandler_ErrStatus(t *testing.T) {
+	rew := ht.NewRecorder()
+	Respond(rew, nil, func() http.Handler { panic(statusErr(http.StatusConflict)) })
+
+	eq(t, http.StatusConflict, rew.Code)
+	eq(t, `status error`, rew.Body.String())
+}
+
+func TestErrWith(t *testing.T) {
+	eq(t, ErrStatus{Status: 400}, ErrWith(400, ``))
+	eq(t, ErrStatus{Status: 400, Err: errors.New(`fail`)}, ErrWith(400, `fail`))
+
+	eq(t, 400, ErrBadRequest(``).Status)
+	eq(t, 401, ErrUnauthorized(``).Status)
+	eq(t, 403, ErrForbidden(``).Status)
+	eq(t, 404, ErrNotFound(``).Status)
+	eq(t, 409, ErrConflict(``).Status)
+	eq(t, 429, ErrTooManyRequests(``).Status)
+}
+
+func TestErrStatus_ServeHTTP(t *testing.T) {
+	rew := ht.NewRecorder()
+	ErrNotFound(`user not found`).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, `user not found`, rew.Body.String())
+}
+
+func TestHandler_ErrNotFound(t *testing.T) {
+	rew := ht.NewRecorder()
+	Respond(rew, nil, func() http.Handler { panic(ErrNotFound(``)) })
+
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, `Not Found`, rew.Body.String())
+}
+
+func TestWriteErr_negotiated(t *testing.T) {
+	test := func(accept, expType, expBody string) {
+		t.Helper()
+
+		req := ht.NewRequest(http.MethodGet, `/`, nil)
+		if accept != `` {
+			req.Header.Set(`Accept`, accept)
+		}
+
+		rew := ht.NewRecorder()
+		WriteErr(rew, req, ErrNotFound(`user not found`), false)
+
+		eq(t, http.StatusNotFound, rew.Code)
+		eq(t, expType, rew.Header().Get(HeadType))
+		eq(t, expBody, rew.Body.String())
+	}
+
+	const text = `user not found`
+	const json = `{"error":"user not found","status":404}` + "\n"
+
+	test(``, ``, text)
+	test(`text/html`, ``, text)
+	test(`*/*`, ``, text)
+	test(`text/plain, application/json`, ``, text)
+	test(`application/json`, TypeJson, json)
+	test(`text/html, application/json`, ``, text)
+	test(`text/html;q=0.9, application/json`, TypeJson, json)
+	test(`application/json, */*`, ``, text)
+	test(`application/json, */*;q=0.8`, TypeJson, json)
+	test(`text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8`, ``, text)
+	test(`text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8`, ``, text)
+	test(`text/plain;q=0.5, application/json`, TypeJson, json)
+	test(`application/json;q=0, */*`, ``, text)
+	test(`Application/JSON`, TypeJson, json)
+	test(`application/xml`, TypeXml, `<error><message>user not found</message><status>404</status></error>`)
+	test(
+		`application/problem+json`,
+		TypeProblemJson,
+		`{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found"}`+"\n",
+	)
+}
+
+func TestWriteErr_response_type(t *testing.T) {
+	rew := ht.NewRecorder()
+	rew.Header().Set(HeadType, TypeJson)
+	WriteErr(rew, nil, errors.New(`fail`), false)
+
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, TypeJson, rew.Header().Get(HeadType))
+	eq(t, `{"error":"fail","status":500}`+"\n", rew.Body.String())
+}
+
+func TestWriteErr_ErrRenderers(t *testing.T) {
+	defer func(prev map[string]ErrRenderer) { ErrRenderers = prev }(ErrRenderers)
+
+	ErrRenderers = map[string]ErrRenderer{
+		`text/html`: func(rew http.ResponseWriter, _ *http.Request, err error, status int) error {
+			rew.Header().Set(HeadType, `text/html`)
+			rew.WriteHeader(status)
+			_, err = fmt.Fprintf(rew, `<p>%v</p>`, err)
+			return err
+		},
+	}
+
+	req := ht.NewRequest(http.MethodGet, `/`, nil)
+	req.Header.Set(`Accept`, `text/html, application/json;q=0.9`)
+
+	rew := ht.NewRecorder()
+	WriteErr(rew, req, errors.New(`fail`), false)
+
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, `text/html`, rew.Header().Get(HeadType))
+	eq(t, `<p>fail</p>`, rew.Body.String())
+}
+
+func Test_acceptedVals(t *testing.T) {
+	eq(t, []acceptedVal(nil), acceptedVals(nil, `Accept`))
+
+	req := ht.NewRequest(http.MethodGet, `/`, nil)
+	req.Header.Add(`Accept`, `text/html;q=0.9, Application/Json, image/png;q=0`)
+	req.Header.Add(`Accept`, `*/*;q=0.1, text/plain;q=0.9`)
+
+	eq(t, []acceptedVal{
+		{`application/json`, 1},
+		{`text/html`, 0.9},
+		{`text/plain`, 0.9},
+		{`*/*`, 0.1},
+		{`image/png`, 0},
+	}, acceptedVals(req, `Accept`))
+}
+
+func TestErrJson(t *testing.T) {
+	eq(t, JsonWith(400, ErrBody{Error: `fail`, Status: 400}), ErrJson(400, errors.New(`fail`)))
+	eq(t, JsonWith(500, ErrBody{Error: `unknown error`, Status: 500}), ErrJson(0, nil))
+
+	rew := ht.NewRecorder()
+	ErrJson(0, ErrStatus{
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{`Retry-After`: {`10`}},
+	}).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusTooManyRequests, rew.Code)
+	eq(t, typeJsonUtf8, rew.Header().Get(HeadType))
+	eq(t, `10`, rew.Header().Get(`Retry-After`))
+	eq(t, `{"error":"Too Many Requests","status":429}`+"\n", rew.Body.String())
+}
+
+func TestErrXml(t *testing.T) {
+	rew := ht.NewRecorder()
+	ErrXml(0, ErrNotFound(`user not found`)).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, typeXmlUtf8, rew.Header().Get(HeadType))
+	eq(t, `<error><message>user not found</message><status>404</status></error>`, rew.Body.String())
+}
+
+func TestErrorPages(t *testing.T) {
+	defer func(prev map[int]http.Handler) { ErrorPages = prev }(ErrorPages)
+
+	ErrorPages = map[int]http.Handler{
+		http.StatusNotFound:            StringWith(http.StatusNotFound, `missing`),
+		http.StatusMethodNotAllowed:    StringWith(http.StatusMethodNotAllowed, `wrong method`),
+		http.StatusInternalServerError: StringWith(http.StatusInternalServerError, `oops`),
+		http.StatusConflict:            File{Status: http.StatusConflict, Path: `missing.html`},
+	}
+
+	test := func(han http.Handler, expStatus int, expBody string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, ht.NewRequest(http.MethodGet, `/one`, nil))
+		eq(t, expStatus, rew.Code)
+		eq(t, expBody, rew.Body.String())
+	}
+
+	test(NotFound{}, http.StatusNotFound, `missing`)
+	test(File{Path: `missing.txt`}, http.StatusNotFound, `missing`)
+	test(Dir{Path: `.`}, http.StatusNotFound, `missing`)
+	test(MethodNotAllowed{Allow: []string{http.MethodPost}}, http.StatusMethodNotAllowed, `wrong method`)
+	test(ErrStatus{Err: errors.New(`fail`)}, http.StatusInternalServerError, `oops`)
+	test(ErrConflict(`conflict`), http.StatusConflict, `conflict`)
+	test(Err(errors.New(`fail`)), http.StatusInternalServerError, `fail`)
+
+	page := StringWith(http.StatusNotFound, `dir missing`)
+	test(File{Path: `missing.txt`, ErrorPages: map[int]http.Handler{404: page}}, http.StatusNotFound, `dir missing`)
+	test(Dir{Path: `.`, ErrorPages: map[int]http.Handler{404: page}}, http.StatusNotFound, `dir missing`)
+}
+
+func TestWriteErr_RedactErrors(t *testing.T) {
+	defer func(prev bool) { RedactErrors = prev }(RedactErrors)
+	RedactErrors = true
+
+	var rew *ht.ResponseRecorder
+	logged := captureStderr(func() {
+		rew = ht.NewRecorder()
+		WriteErr(rew, nil, errors.New(`secret database error`), false)
+	})
+
+	eq(t, http.StatusInternalServerError, rew.Code)
+
+	body := rew.Body.String()
+	match := regexp.MustCompile(`^internal server error \(error id: ([0-9a-f]{16})\)$`).FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf(`unexpected body %q`, body)
+	}
+	eq(t, `error `+match[1]+` while serving HTTP request: secret database error`+"\n", logged)
+	eq(t, match[1], rew.Header().Get(HeadErrId))
+
+	rew = ht.NewRecorder()
+	logged = captureStderr(func() { WriteErr(rew, nil, ErrNotFound(`user not found`), false) })
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, `user not found`, rew.Body.String())
+	eq(t, ``, logged)
+}
+
+func TestWriteErrWithId(t *testing.T) {
+	var rew *ht.ResponseRecorder
+	logged := captureStderr(func() {
+		rew = ht.NewRecorder()
+		WriteErrWithId(rew, nil, ErrConflict(`conflict`), false)
+	})
+
+	id := rew.Header().Get(HeadErrId)
+	eq(t, 16, len(id))
+	eq(t, http.StatusConflict, rew.Code)
+	eq(t, `conflict (error id: `+id+`)`, rew.Body.String())
+	eq(t, `error `+id+` while serving HTTP request: conflict`+"\n", logged)
+
+	rew = ht.NewRecorder()
+	logged = captureStderr(func() { WriteErrWithId(rew, nil, errors.New(`fail`), true) })
+	eq(t, ``, rew.Header().Get(HeadErrId))
+	eq(t, ``, rew.Body.String())
+	eq(t, true, strings.HasSuffix(logged, ` while serving HTTP request: fail`+"\n"))
+}
+
+func TestWriteErrWithId_RedactErrors(t *testing.T) {
+	defer func(prev bool) { RedactErrors = prev }(RedactErrors)
+	RedactErrors = true
+
+	var rew *ht.ResponseRecorder
+	logged := captureStderr(func() {
+		rew = ht.NewRecorder()
+		WriteErrWithId(rew, nil, errors.New(`secret`), false)
+	})
+
+	id := rew.Header().Get(HeadErrId)
+	eq(t, `internal server error (error id: `+id+`)`, rew.Body.String())
+	eq(t, `error `+id+` while serving HTTP request: secret`+"\n", logged)
+}
+
+func captureStderr(fun func()) string {
+	file, err := os.CreateTemp(``, `goh_stderr`)
+	try(err)
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	prev := os.Stderr
+	os.Stderr = file
+	defer func() { os.Stderr = prev }()
+
+	fun()
+	return string(readFile(file.Name()))
+}
+
+func TestErrFuncs(t *testing.T) {
+	var calls []string
+	logFunc := func(name string) ErrFunc {
+		return func(_ http.ResponseWriter, _ *http.Request, err error, wrote bool) {
+			calls = append(calls, fmt.Sprintf(`%v %v %v`, name, err, wrote))
+		}
+	}
+
+	fun := ErrFuncs(logFunc(`one`), nil, WriteErr, logFunc(`two`))
+
+	rew := ht.NewRecorder()
+	fun(rew, nil, ErrConflict(`conflict`), false)
+
+	eq(t, http.StatusConflict, rew.Code)
+	eq(t, `conflict`, rew.Body.String())
+	eq(t, []string{`one conflict false`, `two conflict true`}, calls)
+
+	calls = nil
+	ErrFuncs(logFunc(`one`))(ht.NewRecorder(), nil, errors.New(`fail`), true)
+	eq(t, []string{`one fail true`}, calls)
+}
+
+type failWriter struct {
+	http.ResponseWriter
+	limit int
+}
+
+func (self *failWriter) Write(chunk []byte) (int, error) {
+	if len(chunk) > self.limit {
+		size, _ := self.ResponseWriter.Write(chunk[:self.limit])
+		self.limit = 0
+		return size, errors.New(`broken pipe`)
+	}
+	self.limit -= len(chunk)
+	return self.ResponseWriter.Write(chunk)
+}
+
+func TestErrInfo(t *testing.T) {
+	var infos []ErrInfo
+	errFunc := func(_ http.ResponseWriter, _ *http.Request, err error, wrote bool) {
+		info, ok := ErrInfoOf(err)
+		eq(t, true, ok)
+		eq(t, info.Wrote, wrote)
+		infos = append(infos, info)
+	}
+
+	String{Status: 201, ErrFunc: errFunc, Body: `hello world`}.ServeHTTP(&failWriter{ht.NewRecorder(), 5}, nil)
+	Bytes{ErrFunc: errFunc, Body: []byte(`hello world`)}.ServeHTTP(&failWriter{ht.NewRecorder(), 3}, nil)
+	Json{ErrFunc: errFunc, Body: make(chan int)}.ServeHTTP(ht.NewRecorder(), nil)
+	Redirect{ErrFunc: errFunc}.ServeHTTP(ht.NewRecorder(), nil)
+	Json{Status: 201, ErrFunc: errFunc, Body: make(chan int)}.ServeHTTP(ht.NewRecorder(), nil)
+
+	eq(t, `goh.String`, infos[0].Handler)
+	eq(t, 201, infos[0].Status)
+	eq(t, int64(5), infos[0].Written)
+	eq(t, true, infos[0].Wrote)
+	eq(t, `[goh] failed to write response string: broken pipe`, infos[0].Error())
+
+	eq(t, `goh.Bytes`, infos[1].Handler)
+	eq(t, 200, infos[1].Status)
+	eq(t, int64(3), infos[1].Written)
+
+	eq(t, `goh.Json`, infos[2].Handler)
+	eq(t, 0, infos[2].Status)
+	eq(t, int64(0), infos[2].Written)
+	eq(t, false, infos[2].Wrote)
+
+	eq(t, ErrInfo{Err: errors.New(`[goh] invalid redirect status 0`), Handler: `goh.Redirect`}, infos[3])
+
+	eq(t, `goh.Json`, infos[4].Handler)
+	eq(t, 201, infos[4].Status)
+	eq(t, false, infos[4].Wrote)
+
+	_, ok := ErrInfoOf(errors.New(`fail`))
+	eq(t, false, ok)
+	eq(t, 404, ErrHttpStatus(ErrInfo{Err: ErrNotFound(``)}))
+}
+
+func TestErrPanic(t *testing.T) {
+	base := errors.New(`conflict`)
+	err := ErrPanic{Val: ErrStatus{Status: http.StatusConflict, Err: base}, Stack: `stack`}
+
+	eq(t, `conflict`, err.Error())
+	eq(t, true, errors.Is(err, base))
+	eq(t, http.StatusConflict, ErrHttpStatus(err))
+	eq(t, `stack`, err.StackTrace())
+	eq(t, `conflict`, fmt.Sprintf(`%v`, err))
+	eq(t, "conflict\nstack", fmt.Sprintf(`%+v`, err))
+	eq(t, `one`, ErrPanic{Val: `one`}.Error())
+	eq(t, nil, ErrPanic{Val: `one`}.Unwrap())
+}
+
+func TestHandler_stack(t *testing.T) {
+	handler := Handler(func() http.Handler { panic(`fail`) })
+
+	err, ok := handler.(ErrPanic)
+	eq(t, true, ok)
+	eq(t, `fail`, err.Val)
+	eq(t, true, strings.Contains(err.Stack, `TestHandler_stack`))
+
+	var received error
+	defer func(prev ErrFunc) { HandleErr = prev }(HandleErr)
+	HandleErr = func(rew http.ResponseWriter, req *http.Request, err error, wrote bool) {
+		received = err
+		WriteErr(rew, req, err, wrote)
+	}
+
+	rew := ht.NewRecorder()
+	handler.ServeHTTP(rew, nil)
+
+	eq(t, err, received)
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, `fail`, rew.Body.String())
+}
+
+func TestWriteErr_ShowStackTraces(t *testing.T) {
+	defer func(prev bool) { ShowStackTraces = prev }(ShowStackTraces)
+	ShowStackTraces = true
+
+	rew := ht.NewRecorder()
+	WriteErr(rew, nil, ErrPanic{Val: `fail`, Stack: `stack`}, false)
+
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, "fail\n\nstack", rew.Body.String())
+}
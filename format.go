@@ -0,0 +1,94 @@
+package goh
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Default value of `goh.FormatQuery.Param`.
+const DefaultFormatParam = `format`
+
+/*
+Default value of `goh.FormatQuery.Formats`: format names and the corresponding
+media types. May be modified at program startup.
+*/
+var DefaultFormats = map[string]string{
+	`json`: TypeJson,
+	`xml`:  TypeXml,
+	`csv`:  TypeCsv,
+	`html`: TypeHtml,
+	`text`: TypeText,
+}
+
+/*
+HTTP handler that allows a query parameter to override the "Accept" header,
+then serves the inner handler. Useful for debugging APIs in a browser, which
+always sends its own "Accept" header. For example, with default settings, the
+request "/users?format=json" is served as if it had "Accept: application/json".
+Affects all content negotiation based on the "Accept" header, including error
+responses of `goh.WriteErr`.
+
+`.Param` is the name of the query parameter, defaulting to
+`goh.DefaultFormatParam`. `.Formats` maps format names to media types,
+defaulting to `goh.DefaultFormats`. Format names are case-insensitive. Unknown
+formats are ignored, and the request is served as-is. When `.Handler` is nil,
+responds with `goh.NotFound`.
+*/
+type FormatQuery struct {
+	Handler http.Handler
+	Param   string
+	Formats map[string]string
+}
+
+// Implement `http.Handler`.
+func (self FormatQuery) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.FormatQuery`, self.serveHTTP)
+}
+
+func (self FormatQuery) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	orNotFound(self.Handler).ServeHTTP(rew, self.Request(req))
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self FormatQuery) Han(*http.Request) http.Handler { return self }
+
+// Returns a modified version with the given inner handler.
+func (self FormatQuery) With(val http.Handler) FormatQuery {
+	self.Handler = val
+	return self
+}
+
+/*
+Returns the media type requested via the query parameter, or an empty string
+if the parameter is missing or the format is unknown.
+*/
+func (self FormatQuery) Type(req *http.Request) string {
+	if req == nil || req.URL == nil {
+		return ``
+	}
+
+	formats := self.Formats
+	if formats == nil {
+		formats = DefaultFormats
+	}
+	return formats[strings.ToLower(req.URL.Query().Get(orStr(self.Param, DefaultFormatParam)))]
+}
+
+/*
+Returns the request with the "Accept" header replaced according to the query
+parameter. The original request is not modified. When no format is requested,
+returns the original request.
+*/
+func (self FormatQuery) Request(req *http.Request) *http.Request {
+	typ := self.Type(req)
+	if typ == `` {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	req.Header.Set(`Accept`, typ)
+	return req
+}
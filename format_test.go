@@ -0,0 +1,47 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+var (
+	_ = http.Handler(FormatQuery{})
+	_ = Han(FormatQuery{}.Han)
+)
+
+func TestFormatQuery(t *testing.T) {
+	var accept string
+	inner := http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		accept = req.Header.Get(`Accept`)
+	})
+
+	test := func(han FormatQuery, path, exp string) {
+		t.Helper()
+		req := ht.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(`Accept`, `text/html`)
+		han.ServeHTTP(ht.NewRecorder(), req)
+		eq(t, exp, accept)
+		eq(t, `text/html`, req.Header.Get(`Accept`))
+	}
+
+	han := FormatQuery{}.With(inner)
+	test(han, `/`, `text/html`)
+	test(han, `/?format=json`, TypeJson)
+	test(han, `/?format=XML`, TypeXml)
+	test(han, `/?format=csv`, TypeCsv)
+	test(han, `/?format=unknown`, `text/html`)
+	test(han, `/?fmt=json`, `text/html`)
+
+	custom := FormatQuery{Handler: inner, Param: `fmt`, Formats: map[string]string{`yaml`: `application/yaml`}}
+	test(custom, `/?fmt=yaml`, `application/yaml`)
+	test(custom, `/?fmt=json`, `text/html`)
+
+	t.Run(`errors`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		FormatQuery{Handler: ErrNotFound(`missing`)}.ServeHTTP(rew, ht.NewRequest(http.MethodGet, `/?format=json`, nil))
+		eq(t, http.StatusNotFound, rew.Code)
+		eq(t, TypeJson, rew.Header().Get(HeadType))
+	})
+}
@@ -0,0 +1,413 @@
+package goh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+HTTP handler that serves files out of an `fs.FS`, such as `embed.FS`. Mirrors
+`goh.Dir`, and has the same semantics for its fields, with the following
+differences:
+
+  - `.Path` is a slash-separated path of a directory within `.FS`. Empty means
+    the FS root.
+  - The filter receives slash-separated FS paths, which begin with `.Path`
+    unless it's empty or ".".
+  - Directory listings and `.OnFile` are not supported.
+
+Files are served via `http.ServeContent`. When a file has no modification time,
+as is the case for `embed.FS`, the entity tag used by `.Conditional` is derived
+from the file content instead, and is computed once per file, assuming such
+files never change. Like `goh.Dir`, each requested file is opened once, and
+served from the same handle. Example usage:
+
+	//go:embed static
+	var staticFs embed.FS
+
+	var han = goh.FS{FS: staticFs, Path: `static`, DetectType: true}
+*/
+type FS struct {
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	FS           fs.FS
+	Path         string
+	Prefix       string
+	Rewrite      Rewrite
+	Filter       Filter
+	Index        string
+	Fallback     http.Handler
+	NotFound     http.Handler
+	MaxSize      int64
+
+	DetectType    bool
+	Conditional   bool
+	NoRanges      bool
+	AllowDotfiles bool
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self FS) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self FS) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.FS`, self.serveHTTP)
+}
+
+func (self FS) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		orNotFound(self.NotFound).ServeHTTP(rew, req)
+	}
+}
+
+/*
+Implement `HttpHandlerOpt`. If possible, serves the requested file or
+`.Fallback`, and returns true. Otherwise returns false.
+*/
+func (self FS) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.FS`, self.servedHTTP)
+}
+
+func (self FS) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	file, handle, stat := self.resolveOpened(req)
+	if handle == nil {
+		return servedHTTP(self.Fallback, rew, req)
+	}
+	defer handle.Close()
+
+	return observed(rew, req, `goh.FileFS`, func(rew http.ResponseWriter, req *http.Request) bool {
+		file.file().serveOpened(rew, req, handle, stat)
+		return true
+	})
+}
+
+// Conforms to `goh.Han`. Always returns non-nil.
+func (self FS) Han(req *http.Request) http.Handler {
+	return orNotFound(self.HanOpt(req), self.NotFound)
+}
+
+/*
+Conforms to `goh.Han`. If the requested file is not found, returns
+`.Fallback`, which may be nil.
+*/
+func (self FS) HanOpt(req *http.Request) http.Handler {
+	file, ok := self.ResolveExisting(req)
+	if ok {
+		return file
+	}
+	return self.Fallback
+}
+
+/*
+Resolves the request to an existing `goh.FileFS`, returning false if the file
+is not found. Uses the same path resolution rules as `goh.Dir`.
+*/
+func (self FS) ResolveExisting(req *http.Request) (FileFS, bool) {
+	file, handle, _ := self.resolveOpened(req)
+	if handle == nil {
+		return FileFS{}, false
+	}
+	handle.Close()
+	return file, true
+}
+
+/*
+Same as `.ResolveExisting`, but also returns the opened file and its stat,
+which allows `.ServedHTTP` to serve the file without opening it again. The
+returned file is nil if not found, and must be closed by the caller.
+*/
+func (self FS) resolveOpened(req *http.Request) (FileFS, fs.File, fs.FileInfo) {
+	if self.FS == nil {
+		return FileFS{}, nil, nil
+	}
+
+	reqPath, ok := resolveReqPath(req, self.Prefix, self.Rewrite, self.AllowDotfiles)
+	if !ok {
+		return FileFS{}, nil, nil
+	}
+
+	name := path.Join(self.root(), reqPath)
+	if reqPath == `` || strings.HasSuffix(reqPath, `/`) || fsDirExists(self.FS, name) {
+		name = path.Join(name, orDefaultIndex(self.Index))
+	}
+
+	if !fs.ValidPath(name) || !self.Allow(name) {
+		return FileFS{}, nil, nil
+	}
+
+	file := self.File(name)
+	handle, stat := file.file().openExisting(self.FS.Open)
+	if handle == nil {
+		return FileFS{}, nil, nil
+	}
+	return file, handle, stat
+}
+
+// Same as `goh.Dir.Allow`, but for slash-separated FS paths.
+func (self FS) Allow(name string) bool {
+	if self.Filter != nil {
+		return self.Filter.Allow(name)
+	}
+	return true
+}
+
+func (self FS) root() string {
+	if self.Path == `` {
+		return `.`
+	}
+	return self.Path
+}
+
+// Returns a `goh.FileFS` at the given FS path, with settings copied from self.
+func (self FS) File(name string) FileFS {
+	return FileFS{
+		Status:       self.Status,
+		Header:       self.Header,
+		ErrFunc:      self.ErrFunc,
+		AppendHeader: self.AppendHeader,
+		FS:           self.FS,
+		Path:         name,
+		NotFound:     self.NotFound,
+		MaxSize:      self.MaxSize,
+		DetectType:   self.DetectType,
+		Conditional:  self.Conditional,
+		NoRanges:     self.NoRanges,
+	}
+}
+
+/*
+HTTP handler that always serves a file at a specific path in an `fs.FS`, such
+as `embed.FS`. Analogous to `goh.File`, with the same semantics for its fields,
+except that `.Path` is a slash-separated FS path. Uses `http.ServeContent`. See
+`goh.FS` for notes on entity tags. Example usage:
+
+	//go:embed favicon.ico
+	var faviconFs embed.FS
+
+	var faviconHan = goh.FileFS{FS: faviconFs, Path: `favicon.ico`, DetectType: true}
+*/
+type FileFS struct {
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	FS           fs.FS
+	Path         string
+	NotFound     http.Handler
+	MaxSize      int64
+	ContentType  string
+	DetectType   bool
+	Conditional  bool
+	NoRanges     bool
+
+	Disposition     string
+	DispositionName string
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self FileFS) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self FileFS) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.FileFS`, self.serveHTTP)
+}
+
+func (self FileFS) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		orNotFound(self.NotFound).ServeHTTP(rew, req)
+	}
+}
+
+/*
+Implement `HttpHandlerOpt`. If the file exists, serves it and returns true.
+Otherwise returns false. Like `goh.File`, opens the file only once.
+*/
+func (self FileFS) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.FileFS`, self.servedHTTP)
+}
+
+func (self FileFS) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	if self.FS == nil {
+		return false
+	}
+	return self.file().serve(rew, req, self.FS.Open)
+}
+
+func (self FileFS) file() File {
+	return File{
+		Status:          self.Status,
+		Header:          self.Header,
+		ErrFunc:         self.ErrFunc,
+		AppendHeader:    self.AppendHeader,
+		Path:            self.Path,
+		MaxSize:         self.MaxSize,
+		ContentType:     self.ContentType,
+		DetectType:      self.DetectType,
+		Conditional:     self.Conditional,
+		NoRanges:        self.NoRanges,
+		Disposition:     self.Disposition,
+		DispositionName: self.DispositionName,
+		fsys:            self.FS,
+	}
+}
+
+/*
+True if a file exists at `.Path` in `.FS`. If `.MaxSize` is positive, the file
+must also not exceed that size.
+*/
+func (self FileFS) Exists() bool { return self.stat() != nil }
+
+// Same as `goh.File.ContentDisposition`.
+func (self FileFS) ContentDisposition() string {
+	if self.Disposition == `` {
+		return ``
+	}
+	name := self.DispositionName
+	if name == `` && self.Path != `` {
+		name = path.Base(self.Path)
+	}
+	return ContentDisposition(self.Disposition, name)
+}
+
+// If `.Exists()`, returns itself as-is. Otherwise returns zero.
+func (self FileFS) Existing() (_ FileFS) {
+	if self.Exists() {
+		return self
+	}
+	return
+}
+
+// Conforms to `goh.Han`. Always returns non-nil.
+func (self FileFS) Han(*http.Request) http.Handler { return self }
+
+/*
+Conforms to `goh.Han`. Returns self if file exists, otherwise returns nil.
+Can be used to "try" serving a file.
+*/
+func (self FileFS) HanOpt(*http.Request) http.Handler {
+	if self.Exists() {
+		return self
+	}
+	return nil
+}
+
+// True if `.MaxSize` is unset or the given size doesn't exceed it.
+func (self FileFS) AllowSize(size int64) bool {
+	return self.MaxSize <= 0 || size <= self.MaxSize
+}
+
+func (self FileFS) stat() fs.FileInfo {
+	if self.FS == nil {
+		return nil
+	}
+	stat, _ := fs.Stat(self.FS, self.Path)
+	if stat == nil || stat.IsDir() || !self.AllowSize(stat.Size()) {
+		return nil
+	}
+	return stat
+}
+
+func fsDirExists(fsys fs.FS, name string) bool {
+	stat, _ := fs.Stat(fsys, name)
+	return stat != nil && stat.IsDir()
+}
+
+/*
+Returns the file as `io.ReadSeeker` if it implements that interface, which is
+the case for `os.File` and `embed.FS` files. Otherwise reads it into memory.
+*/
+func readSeeker(file fs.File) (io.ReadSeeker, error) {
+	out, ok := file.(io.ReadSeeker)
+	if ok {
+		return out, nil
+	}
+
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(body), nil
+}
+
+/*
+Detects the content type by the file extension, falling back on sniffing the
+content. Rewinds the content afterwards.
+*/
+func contentType(name string, content io.ReadSeeker) string {
+	out := mime.TypeByExtension(path.Ext(name))
+	if out != `` {
+		return out
+	}
+
+	var buf [512]byte
+	size, _ := io.ReadFull(content, buf[:])
+	_, _ = content.Seek(0, io.SeekStart)
+	return http.DetectContentType(buf[:size])
+}
+
+/*
+Returns a weak entity tag via `goh.FileEtag` when the file has a modification
+time. Otherwise, returns a strong entity tag derived from the content hash,
+rewinding the content afterwards. Such files are assumed to be immutable, as is
+the case for `embed.FS`, so the hash is cached per FS, path, and size, when
+the FS is comparable.
+*/
+func contentEtag(fsys fs.FS, name string, stat fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if !stat.ModTime().IsZero() {
+		return FileEtag(stat), nil
+	}
+
+	key := contentEtagKey{fsys, name, stat.Size()}
+	cache := fsys != nil && reflect.TypeOf(fsys).Comparable()
+	if cache {
+		val, ok := contentEtags.Load(key)
+		if ok {
+			return val.(string), nil
+		}
+	}
+
+	out, err := hashEtag(content)
+	if err == nil && cache {
+		contentEtags.Store(key, out)
+	}
+	return out, err
+}
+
+// Cache used by `contentEtag`.
+var contentEtags sync.Map
+
+type contentEtagKey struct {
+	fsys fs.FS
+	name string
+	size int64
+}
+
+func hashEtag(content io.ReadSeeker) (string, error) {
+	hash := sha256.New()
+	_, err := io.Copy(hash, content)
+	if err != nil {
+		return ``, err
+	}
+
+	_, err = content.Seek(0, io.SeekStart)
+	if err != nil {
+		return ``, err
+	}
+	return strconv.Quote(base64.RawURLEncoding.EncodeToString(hash.Sum(nil)[:12])), nil
+}
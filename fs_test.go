@@ -0,0 +1,211 @@
+package goh
+
+import (
+	"io/fs"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var (
+	_ = http.Handler(FS{})
+	_ = HttpHandlerOpt(FS{})
+	_ = Han(FS{}.Han)
+	_ = Han(FS{}.HanOpt)
+	_ = http.Handler(FileFS{})
+	_ = HttpHandlerOpt(FileFS{})
+	_ = Han(FileFS{}.Han)
+	_ = Han(FileFS{}.HanOpt)
+)
+
+func testFs() fstest.MapFS {
+	return fstest.MapFS{
+		`static/index.html`:     {Data: []byte(`<p>index</p>`)},
+		`static/one/two.css`:    {Data: []byte(`body {}`)},
+		`static/one/index.html`: {Data: []byte(`<p>one</p>`)},
+		`static/three`:          {Data: []byte(`<!doctype html><p>three</p>`)},
+		`static/.env`:           {Data: []byte(`secret`)},
+		`other.txt`:             {Data: []byte(`other`)},
+		`static/dated.txt`: {
+			Data:    []byte(`dated`),
+			ModTime: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+}
+
+func testFsOk(t testing.TB, han http.Handler, req *http.Request, exp string) *ht.ResponseRecorder {
+	t.Helper()
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, req)
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, exp, rew.Body.String())
+	return rew
+}
+
+func testFs404(t testing.TB, han FS, req *http.Request) {
+	t.Helper()
+	eq(t, nil, han.HanOpt(req))
+	eq(t, NotFound{}, han.Han(req))
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, req)
+	eq(t, http.StatusNotFound, rew.Code)
+}
+
+func TestFS(t *testing.T) {
+	han := FS{FS: testFs(), Path: `static`}
+
+	testFsOk(t, han, pathReq(`/`), `<p>index</p>`)
+	testFsOk(t, han, pathReq(`/one`), `<p>one</p>`)
+	testFsOk(t, han, pathReq(`/one/`), `<p>one</p>`)
+	testFsOk(t, han, pathReq(`/one/two.css`), `body {}`)
+
+	testFs404(t, han, pathReq(`/missing`))
+	testFs404(t, han, pathReq(`/.env`))
+	testFs404(t, han, pathReq(`/../other.txt`))
+	testFs404(t, han, pathReq(`/other.txt`))
+	testFs404(t, FS{}, pathReq(`/`))
+
+	testFsOk(t, FS{FS: testFs()}, pathReq(`/other.txt`), `other`)
+
+	t.Run(`prefix and filter`, func(t *testing.T) {
+		han := FS{
+			FS:     testFs(),
+			Path:   `static`,
+			Prefix: `/static/`,
+			Filter: AllowExts{`.css`},
+		}
+		testFsOk(t, han, pathReq(`/static/one/two.css`), `body {}`)
+		testFs404(t, han, pathReq(`/one/two.css`))
+		testFs404(t, han, pathReq(`/static/one/`))
+	})
+
+	t.Run(`detect type`, func(t *testing.T) {
+		han := FS{FS: testFs(), Path: `static`, Status: 201, DetectType: true}
+
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, pathReq(`/one/two.css`))
+		eq(t, 201, rew.Code)
+		eq(t, `text/css; charset=utf-8`, rew.Result().Header.Get(HeadType))
+
+		rew = ht.NewRecorder()
+		han.ServeHTTP(rew, pathReq(`/three`))
+		eq(t, `text/html; charset=utf-8`, rew.Result().Header.Get(HeadType))
+		eq(t, `<!doctype html><p>three</p>`, rew.Body.String())
+	})
+
+	t.Run(`conditional`, func(t *testing.T) {
+		han := FS{FS: testFs(), Path: `static`, Conditional: true}
+
+		rew := testFsOk(t, han, ht.NewRequest(http.MethodGet, `/one/two.css`, nil), `body {}`)
+		etag := rew.Result().Header.Get(`Etag`)
+		eq(t, true, strings.HasPrefix(etag, `"`))
+		eq(t, ``, rew.Result().Header.Get(`Last-Modified`))
+
+		req := ht.NewRequest(http.MethodGet, `/one/two.css`, nil)
+		req.Header.Set(`If-None-Match`, etag)
+		rew = ht.NewRecorder()
+		han.ServeHTTP(rew, req)
+		eq(t, http.StatusNotModified, rew.Code)
+
+		req = ht.NewRequest(http.MethodGet, `/dated.txt`, nil)
+		req.Header.Set(`If-Modified-Since`, `Thu, 02 Jan 2020 03:04:05 GMT`)
+		rew = ht.NewRecorder()
+		han.ServeHTTP(rew, req)
+		eq(t, http.StatusNotModified, rew.Code)
+		eq(t, true, strings.HasPrefix(rew.Result().Header.Get(`Etag`), `W/`))
+	})
+
+	t.Run(`fallback`, func(t *testing.T) {
+		han := FS{FS: testFs(), Path: `static`, Fallback: StringOk(`fallback`)}
+		testFsOk(t, han, pathReq(`/missing`), `fallback`)
+	})
+	t.Run(`single open and cached digest`, func(t *testing.T) {
+		fsys := &countingFs{MapFS: testFs()}
+		han := FS{FS: fsys, Path: `static`, Conditional: true}
+
+		rew := testFsOk(t, han, pathReq(`/one/two.css`), `body {}`)
+		etag := rew.Result().Header.Get(`Etag`)
+		eq(t, 1, fsys.opens)
+
+		// Files without a modification time are assumed to be immutable.
+		fsys.MapFS[`static/one/two.css`].Data = []byte(`head {}`)
+		rew = testFsOk(t, han, pathReq(`/one/two.css`), `head {}`)
+		eq(t, etag, rew.Result().Header.Get(`Etag`))
+		eq(t, 2, fsys.opens)
+	})
+}
+
+// Comparable FS which counts opened files.
+type countingFs struct {
+	fstest.MapFS
+	opens int
+}
+
+func (self *countingFs) Open(name string) (fs.File, error) {
+	self.opens++
+	return self.MapFS.Open(name)
+}
+
+func TestFileFS(t *testing.T) {
+	t.Run(`exists`, func(t *testing.T) {
+		file := FileFS{FS: testFs(), Path: `static/one/two.css`, ContentType: `text/plain`}
+		eq(t, true, file.Exists())
+		eq(t, file, file.Existing())
+		eq(t, file, file.HanOpt(nil))
+		eq(t, file, file.Han(nil))
+
+		rew := testFsOk(t, file, pathReq(`/`), `body {}`)
+		eq(t, `text/plain`, rew.Header().Get(HeadType))
+	})
+
+	t.Run(`missing`, func(t *testing.T) {
+		for _, file := range []FileFS{
+			{},
+			{FS: testFs(), Path: `missing`},
+			{FS: testFs(), Path: `static`},
+			{FS: testFs(), Path: `static/one/two.css`, MaxSize: 3},
+		} {
+			eq(t, false, file.Exists())
+			eq(t, FileFS{}, file.Existing())
+			eq(t, nil, file.HanOpt(nil))
+			eq(t, false, file.ServedHTTP(ht.NewRecorder(), pathReq(`/`)))
+
+			rew := ht.NewRecorder()
+			file.ServeHTTP(rew, pathReq(`/`))
+			eq(t, http.StatusNotFound, rew.Code)
+		}
+	})
+
+	t.Run(`ranges`, func(t *testing.T) {
+		req := ht.NewRequest(http.MethodGet, `/`, nil)
+		req.Header.Set(`Range`, `bytes=0-3`)
+
+		rew := ht.NewRecorder()
+		FileFS{FS: testFs(), Path: `static/one/two.css`}.ServeHTTP(rew, req)
+		eq(t, http.StatusPartialContent, rew.Code)
+		eq(t, `body`, rew.Body.String())
+
+		rew = ht.NewRecorder()
+		FS{FS: testFs(), NoRanges: true}.File(`static/one/two.css`).ServeHTTP(rew, req)
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `body {}`, rew.Body.String())
+		eq(t, `none`, rew.Header().Get(`Accept-Ranges`))
+	})
+
+	t.Run(`disposition`, func(t *testing.T) {
+		file := FileFS{FS: testFs(), Path: `static/one/two.css`, Disposition: DispositionAttachment}
+		rew := testFsOk(t, file, pathReq(`/`), `body {}`)
+		eq(t, `attachment; filename="two.css"`, rew.Header().Get(`Content-Disposition`))
+	})
+
+	t.Run(`not found handler`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		FileFS{FS: testFs(), Path: `missing`, NotFound: StringWith(404, `custom`)}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusNotFound, rew.Code)
+		eq(t, `custom`, rew.Body.String())
+	})
+}
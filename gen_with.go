@@ -0,0 +1,101 @@
+//go:build ignore
+// +build ignore
+
+/*
+Generates "with_gen.go": the `.WithStatus`, `.WithHeader`, `.WithErrFunc`
+builder methods and the `goh.Res` implementations of `.WithHead`. Run via
+`go generate` after adding a handler type.
+*/
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"text/template"
+)
+
+type spec struct {
+	Name string
+
+	// Has the `.WithStatus`, `.WithHeader`, `.WithErrFunc` builder methods.
+	Builder bool
+
+	// Has the `.NoContentLength` and `.Language` fields.
+	Full bool
+}
+
+var specs = []spec{
+	{`Reader`, true, true},
+	{`Bytes`, true, true},
+	{`String`, true, true},
+	{`Text`, true, true},
+	{`HtmlString`, true, true},
+	{`HtmlBytes`, true, true},
+	{`Json`, true, true},
+	{`Xml`, true, true},
+	{`Template`, true, true},
+	{`Markdown`, false, true},
+	{`Redirect`, true, false},
+	{`File`, true, false},
+	{`Dir`, true, false},
+	{`FS`, false, false},
+	{`FileFS`, false, false},
+	{`DirList`, false, false},
+	{`Zip`, false, false},
+	{`Tar`, false, false},
+	{`Sitemap`, false, false},
+	{`SitemapIndex`, false, false},
+	{`Robots`, false, false},
+	{`RuntimeStats`, false, false},
+}
+
+var tpl = template.Must(template.New(``).Parse(`// Code generated by "go run gen_with.go"; DO NOT EDIT.
+
+package goh
+
+import "net/http"
+{{range .}}{{if .Builder}}
+// Returns a modified version with the given status.
+func (self {{.Name}}) WithStatus(val int) {{.Name}} {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self {{.Name}}) WithHeader(val http.Header) {{.Name}} {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self {{.Name}}) WithErrFunc(val ErrFunc) {{.Name}} {
+	self.ErrFunc = val
+	return self
+}
+{{end}}
+// Implement ` + "`goh.Res`" + `. Returns a modified version with the given head.
+func (self {{.Name}}) WithHead(val Head) Res {
+{{- if .Full}}
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+{{- else}}
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+{{- end}}
+	return self
+}
+{{end}}`))
+
+func main() {
+	var buf bytes.Buffer
+	try(tpl.Execute(&buf, specs))
+
+	src, err := format.Source(buf.Bytes())
+	try(err)
+	try(ioutil.WriteFile(`with_gen.go`, src, 0666))
+}
+
+func try(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -13,21 +13,93 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"html/template"
 	"io"
+	"io/fs"
+	"mime"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 const (
-	HeadType  = `Content-Type`
-	TypeJson  = `application/json`
-	TypeForm  = `application/x-www-form-urlencoded`
-	TypeMulti = `multipart/form-data`
+	HeadType        = `Content-Type`
+	TypeText        = `text/plain`
+	TypeHtml        = `text/html`
+	TypeJs          = `text/javascript`
+	TypeCss         = `text/css`
+	TypeSvg         = `image/svg+xml`
+	TypeWasm        = `application/wasm`
+	TypeCsv         = `text/csv`
+	TypeJson        = `application/json`
+	TypeXml         = `application/xml`
+	TypeProblemJson = `application/problem+json`
+	TypeForm        = `application/x-www-form-urlencoded`
+	TypeMulti       = `multipart/form-data`
+	TypeZip         = `application/zip`
+	TypeTar         = `application/x-tar`
+	TypeGzip        = `application/gzip`
 )
 
+/*
+Charset appended to the content types of `goh.Json`, `goh.Xml`, and similar
+handlers when their own `.Charset` is empty. Set to empty to omit the charset
+by default. To omit it for one handler, specify the content type in its
+header.
+*/
+var DefaultCharset = `utf-8`
+
+/*
+Default predicate of `goh.Json.Pretty` and `goh.Xml.Pretty`, which may enable
+indented output for specific requests. Nil by default. See `goh.PrettyQuery`.
+Should be set once, at startup.
+*/
+var DefaultPretty func(*http.Request) bool
+
+// Indentation used when pretty output is requested, see `goh.DefaultPretty`.
+var DefaultIndent = `  `
+
+/*
+Predicate for `goh.DefaultPretty` or `goh.Json.Pretty`. True if the request has
+the query parameter "pretty" with an empty or true value, such as "?pretty",
+"?pretty=1", or "?pretty=true".
+*/
+func PrettyQuery(req *http.Request) bool {
+	if req == nil || req.URL == nil {
+		return false
+	}
+
+	vals, ok := req.URL.Query()[`pretty`]
+	if !ok {
+		return false
+	}
+
+	val := vals[0]
+	if val == `` {
+		return true
+	}
+	out, _ := strconv.ParseBool(val)
+	return out
+}
+
+// Default value of `goh.Dir.Index`.
+const DefaultIndex = `index.html`
+
+/*
+Default for `goh.Dir.FollowSymlinks`. When true, every `goh.Dir` follows
+symlinks that remain inside its directory, regardless of its own setting.
+*/
+var DefaultFollowSymlinks = false
+
 /*
 Signature of a "request->response" function. All Goh handler types have a method
 `.Han` that conforms to this signature.
@@ -55,18 +127,37 @@ var HandleErr = WriteErr
 /*
 Default implementation of `goh.ErrFunc`. Used by `http.Handler` types, such as
 `goh.String`, when no `goh.ErrFunc` was provided by user code. If possible,
-writes the error to the response writer as plain text. If not, logs the error
-to the standard error stream. When implementing a custom error handler, use
-this function's source as an example.
+writes the error to the response writer, with the status determined by
+`goh.ErrHttpStatus` and the header of `goh.ErrStatus`, if any. If not, logs the
+error to the standard error stream. When implementing a custom error handler,
+use this function's source as an example.
+
+When `goh.ErrorPages` has a page for the status, the page is served instead.
+Otherwise, the error is written as plain text, unless a structured format is
+chosen by `goh.ErrRenderers`: when the response already has a content type
+registered there, for example because the error occurred in `goh.Json`, or when
+the request's "Accept" header prefers a registered type over plain text.
+
+When `goh.RedactErrors` is true, errors with statuses 500 and above are
+replaced with `goh.ErrRedacted`, which has a generic message and a random
+error ID, while the original error is logged along with the ID. The ID is also
+sent in the "X-Error-Id" header. See `goh.WriteErrWithId` for using error IDs
+for all errors.
 */
-func WriteErr(rew http.ResponseWriter, _ *http.Request, err error, wrote bool) {
+func WriteErr(rew http.ResponseWriter, req *http.Request, err error, wrote bool) {
 	if err == nil {
 		return
 	}
 
 	if !wrote {
-		rew.WriteHeader(http.StatusInternalServerError)
-		_, inner := io.WriteString(rew, err.Error())
+		Head{Header: errHeader(err)}.writeHeaders(rew)
+		status := ErrHttpStatus(err)
+
+		if RedactErrors && status >= http.StatusInternalServerError {
+			err = redactErr(rew, err, status)
+		}
+
+		inner := writeErrResponse(rew, req, err, status)
 		if inner == nil {
 			return
 		}
@@ -85,10 +176,16 @@ func WriteErr(rew http.ResponseWriter, _ *http.Request, err error, wrote bool) {
 
 /*
 Makes an extremely simple `http.Handler` that serves the error's message as
-plain text. The status is always 500.
+plain text. The status is determined by `goh.ErrHttpStatus`, defaulting to 500.
+When the error chain contains `goh.ErrStatus` with a header, the header is
+included.
 */
 func Err(err error) String {
-	return StringWith(http.StatusInternalServerError, errMsg(err))
+	return String{
+		Status: ErrHttpStatus(err),
+		Header: errHeader(err),
+		Body:   errMsg(err),
+	}
 }
 
 /*
@@ -105,11 +202,34 @@ Pseudo-embedded in the various handler types, obtained via `.Head()` methods.
 Goh uses pseudo-embedding instead of actual embedding because Go doesn't allow
 promoted fields to be used at the top level of embedding type. With embedding,
 literals of various handler types would have to use `Head: Head{}`.
+
+By default, each key of `.Header` replaces the same key in the response
+header, which may have been set by middleware. When `.AppendHeader` is true,
+the values are appended instead. See `goh.ReplaceHeader` and
+`goh.AppendHeader`.
+
+When `.NoContentLength` is true, the "Content-Length" header is suppressed,
+including the one automatically added by the Go HTTP server for small bodies.
+This is needed when downstream code, such as compression middleware, transforms
+the body, making a precomputed length wrong.
+
+When `.Language` is set, it's written as the "Content-Language" header, and
+"Accept-Language" is added to the "Vary" header, since a response with a
+specific language is typically negotiated. See `goh.AddVary`.
+
+Writing is copy-on-write: `.Header` is only read, and the response receives
+its own copies of the value slices. This makes it safe to serve the same
+handler value, such as a global variable, concurrently, even when downstream
+code modifies the response header, as long as user code doesn't modify
+`.Header` itself after the handler is created.
 */
 type Head struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
+	Status          int
+	Header          http.Header
+	ErrFunc         ErrFunc
+	AppendHeader    bool
+	NoContentLength bool
+	Language        string
 }
 
 /*
@@ -118,9 +238,29 @@ internally by the various handler types. You shouldn't have to call this
 yourself, unless implementing a new type.
 
 This must be called exactly once, and only before writing the body.
+
+The status 200, whether explicit or implicit, is not written unless
+`goh.WriteStatusOk` is true.
 */
 func (self Head) Write(rew http.ResponseWriter) {
+	self.write(rew, WriteStatusOk)
+}
+
+/*
+When true, `goh.Head.Write` always calls `http.ResponseWriter.WriteHeader`,
+including for the implicit status 200. Useful with wrapping response writers,
+such as metrics or logging recorders, which rely on an explicit call to record
+the status. `goh.File` is exempt, since `http.ServeContent` writes its own
+status. Should be set once, at startup.
+*/
+var WriteStatusOk = false
+
+func (self Head) write(rew http.ResponseWriter, writeOk bool) {
 	self.writeHeaders(rew)
+	if self.NoContentLength {
+		// A nil value suppresses the header, including the automatic one.
+		rew.Header()[`Content-Length`] = nil
+	}
 
 	/**
 	The status `http.StatusOK` is implicit, and writing it should be equivalent to
@@ -130,14 +270,152 @@ func (self Head) Write(rew http.ResponseWriter) {
 	*/
 	if self.Status != 0 && self.Status != http.StatusOK {
 		rew.WriteHeader(self.Status)
+	} else if writeOk {
+		rew.WriteHeader(http.StatusOK)
 	}
 }
 
 func (self Head) writeHeaders(rew http.ResponseWriter) {
 	target := rew.Header()
-	for key, vals := range self.Header {
-		target[key] = vals
+	writeDefaultHeader(target)
+	if self.AppendHeader {
+		AppendHeader(target, self.Header)
+	} else {
+		ReplaceHeader(target, self.Header)
+	}
+
+	if self.Language != `` {
+		target.Set(`Content-Language`, self.Language)
+		AddVary(target, `Accept-Language`)
+	}
+}
+
+/*
+Adds the given header names to the "Vary" header of the response, skipping
+names already present, case-insensitively. Existing values may be
+comma-separated lists. Does nothing when "Vary" is "*", which already implies
+all names. Used by Goh handlers and middleware which negotiate their responses,
+and may be used by user code for the same purpose:
+
+	goh.AddVary(rew.Header(), `Accept`, `Accept-Encoding`)
+*/
+func AddVary(head http.Header, names ...string) {
+	prev := varyNames(head)
+	if prev[`*`] {
+		return
+	}
+
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == `` || prev[key] {
+			continue
+		}
+		prev[key] = true
+		head.Add(`Vary`, name)
+	}
+}
+
+// Returns the lowercased names listed in the "Vary" header.
+func varyNames(head http.Header) map[string]bool {
+	out := map[string]bool{}
+	for _, val := range head.Values(`Vary`) {
+		for _, name := range strings.Split(val, `,`) {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != `` {
+				out[name] = true
+			}
+		}
+	}
+	return out
+}
+
+/*
+Copies the source header into the target, replacing any existing values of the
+same keys. Used by `goh.Head.Write` by default. Keys are canonicalized unless
+`goh.RawHeaderKeys` is true. Value slices are copied, so later changes to the
+target don't affect the source.
+*/
+func ReplaceHeader(target, src http.Header) {
+	for key, vals := range src {
+		target[headerKey(key)] = append([]string(nil), vals...)
+	}
+}
+
+/*
+Appends the values of the source header to the target, preserving any existing
+values of the same keys. Used by `goh.Head.Write` when `.AppendHeader` is true.
+Keys are canonicalized unless `goh.RawHeaderKeys` is true. Value slices are
+copied, so later changes to the target don't affect the source.
+*/
+func AppendHeader(target, src http.Header) {
+	for key, vals := range src {
+		key = headerKey(key)
+		prev := target[key]
+		target[key] = append(prev[:len(prev):len(prev)], vals...)
+	}
+}
+
+/*
+When false (default), header keys written by Goh handlers are canonicalized
+via `http.CanonicalHeaderKey`, which makes them visible to `http.Header.Get`
+and merges keys that differ only in case, such as "content-type" and
+"Content-Type". When true, keys are written verbatim, which may be useful for
+pass-through proxies. Should be set once, at startup.
+*/
+var RawHeaderKeys = false
+
+func headerKey(key string) string {
+	if RawHeaderKeys {
+		return key
+	}
+	return http.CanonicalHeaderKey(key)
+}
+
+/*
+Headers included in every response written by Goh handlers, for example
+"Server" or "X-Content-Type-Options". A key is skipped when the response header
+already has it, and is overridden by the same key in the handler's own header.
+Should be set once, at startup. Example usage:
+
+	goh.DefaultHeader = http.Header{`X-Content-Type-Options`: {`nosniff`}}
+*/
+var DefaultHeader http.Header
+
+// Adds the missing keys of `goh.DefaultHeader`, copying the values.
+func writeDefaultHeader(target http.Header) {
+	for key, vals := range DefaultHeader {
+		key = headerKey(key)
+		_, ok := target[key]
+		if !ok {
+			target[key] = append([]string(nil), vals...)
+		}
+	}
+}
+
+/*
+Passes the error, wrapped in the given `goh.ErrInfo`, to the error handler.
+*/
+func (self Head) fail(rew http.ResponseWriter, req *http.Request, info ErrInfo) {
+	self.errFunc()(rew, req, info, info.Wrote)
+}
+
+// Status sent by `.Write`: `.Status`, or the implicit 200.
+func (self Head) sentStatus() int {
+	if self.Status != 0 {
+		return self.Status
+	}
+	return http.StatusOK
+}
+
+/*
+Status sent by `.Write` before an error, given whether any of the body was
+written, or 0 if nothing was sent. See `goh.ErrInfo.Status`.
+*/
+func (self Head) sentStatusIf(wrote bool) int {
+	if wrote || WriteStatusOk || (self.Status != 0 && self.Status != http.StatusOK) {
+		return self.sentStatus()
 	}
+	return 0
 }
 
 func (self Head) errFunc() ErrFunc {
@@ -154,27 +432,34 @@ Caution: if the reader is also `io.Closer`, it must be closed in your code.
 This type does NOT attempt that.
 */
 type Reader struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
-	Body    io.Reader
+	Status          int
+	Header          http.Header
+	ErrFunc         ErrFunc
+	AppendHeader    bool
+	NoContentLength bool
+	Language        string
+	Body            io.Reader
 }
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self Reader) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
 }
 
 // Implement `http.Handler`.
 func (self Reader) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Reader`, self.serveHTTP)
+}
+
+func (self Reader) serveHTTP(rew http.ResponseWriter, req *http.Request) {
 	head := self.Head()
 	head.Write(rew)
 
 	if self.Body != nil {
-		_, err := io.Copy(rew, self.Body)
+		size, err := io.Copy(rew, self.Body)
 		if err != nil {
 			err = fmt.Errorf(`[goh] failed to copy response from reader: %w`, err)
-			head.errFunc()(rew, req, err, true)
+			head.fail(rew, req, ErrInfo{err, `goh.Reader`, head.sentStatusIf(size > 0), size, size > 0})
 		}
 	}
 }
@@ -187,26 +472,38 @@ HTTP handler that writes bytes. Note: for sending a string, use `goh.String`,
 avoiding a bytes-to-string conversion.
 */
 type Bytes struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
-	Body    []byte
+	Status          int
+	Header          http.Header
+	ErrFunc         ErrFunc
+	AppendHeader    bool
+	NoContentLength bool
+	Language        string
+	Body            []byte
 }
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self Bytes) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
 }
 
 // Implement `http.Handler`.
 func (self Bytes) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Bytes`, self.serveHTTP)
+}
+
+func (self Bytes) serveHTTP(rew http.ResponseWriter, req *http.Request) {
 	head := self.Head()
 	head.Write(rew)
 
-	_, err := rew.Write(self.Body)
+	// Writing even an empty body fails for statuses such as 204 and 304.
+	if len(self.Body) == 0 {
+		return
+	}
+
+	size, err := rew.Write(self.Body)
 	if err != nil {
 		err = fmt.Errorf(`[goh] failed to write response bytes: %w`, err)
-		head.errFunc()(rew, req, err, true)
+		head.fail(rew, req, ErrInfo{err, `goh.Bytes`, head.sentStatusIf(size > 0), int64(size), size > 0})
 	}
 }
 
@@ -223,31 +520,152 @@ func BytesWith(status int, body []byte) Bytes {
 	return Bytes{Status: status, Body: body}
 }
 
+/*
+Shortcut for `goh.Bytes` with specific status, content type, and body. The
+content type is used as-is. Useful for generated assets:
+
+	goh.BytesTyped(http.StatusOK, goh.TypeCss, css)
+	goh.BytesTyped(http.StatusOK, goh.TypeJs+`; charset=utf-8`, bundle)
+*/
+func BytesTyped(status int, conType string, body []byte) Bytes {
+	return bytesFrom(Head{Status: status}, conType, body)
+}
+
+/*
+HTTP handler that writes bytes as HTML, automatically setting the content type
+`goh.TypeHtml` with `goh.DefaultCharset`. Useful for pre-rendered pages and
+fragments. For a custom charset, use `goh.HtmlString`.
+*/
+type HtmlBytes Bytes
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self HtmlBytes) Head() Head {
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
+}
+
+// Implement `http.Handler`.
+func (self HtmlBytes) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.HtmlBytes`, self.serveHTTP)
+}
+
+func (self HtmlBytes) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(HeadType, withCharset(TypeHtml, ``))
+
+	head := self.Head()
+	head.Write(rew)
+
+	if len(self.Body) == 0 {
+		return
+	}
+
+	size, err := rew.Write(self.Body)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write response HTML: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.HtmlBytes`, head.sentStatusIf(size > 0), int64(size), size > 0})
+	}
+}
+
+// Conforms to `goh.Han`.
+func (self HtmlBytes) Han(*http.Request) http.Handler { return self }
+
+// Shortcut for `goh.HtmlBytesWith(http.StatusOK, body)`.
+func HtmlBytesOk(body []byte) HtmlBytes {
+	return HtmlBytesWith(http.StatusOK, body)
+}
+
+// Shortcut for `goh.HtmlBytes` with specific status and body.
+func HtmlBytesWith(status int, body []byte) HtmlBytes {
+	return HtmlBytes{Status: status, Body: body}
+}
+
+/*
+Shortcut for a response with status 204 and no body. The Go HTTP server doesn't
+send "Content-Length" for such responses.
+*/
+func NoContent() Bytes {
+	return BytesWith(http.StatusNoContent, nil)
+}
+
+/*
+Shortcut for a response with status 201, indicating that a resource was created
+at the given location, which is sent in the "Location" header. The body is
+optional:
+
+	goh.Created(`/users/123`)
+	goh.Created(`/users/123`, userJson...)
+
+Empty location is omitted.
+*/
+func Created(location string, body ...byte) Bytes {
+	return Bytes{
+		Status: http.StatusCreated,
+		Header: locationHeader(location, `Location`),
+		Body:   body,
+	}
+}
+
+/*
+Shortcut for a response with status 202 and no body, indicating that a request
+was accepted for asynchronous processing. The given URL, where the client may
+check the status of processing, is sent in the "Location" and
+"Content-Location" headers. Empty URL is omitted.
+*/
+func Accepted(statusUrl string) Bytes {
+	return Bytes{
+		Status: http.StatusAccepted,
+		Header: locationHeader(statusUrl, `Location`, `Content-Location`),
+	}
+}
+
+func locationHeader(val string, keys ...string) http.Header {
+	if val == `` {
+		return nil
+	}
+	out := make(http.Header, len(keys))
+	for _, key := range keys {
+		out.Set(key, val)
+	}
+	return out
+}
+
 /*
 HTTP handler that writes a string. Note: for sending bytes, use `goh.Bytes`,
 avoiding a string-to-bytes conversion.
+
+When `.Header` has a "text/*" content type without a charset, `.Charset` is
+appended to it, defaulting to `goh.DefaultCharset`. Without a content type, the
+Go HTTP server detects one, which for text is "text/plain; charset=utf-8".
 */
 type String struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
-	Body    string
+	Status          int
+	Header          http.Header
+	ErrFunc         ErrFunc
+	AppendHeader    bool
+	NoContentLength bool
+	Language        string
+	Charset         string
+	Body            string
 }
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self String) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
 }
 
 // Implement `http.Handler`.
 func (self String) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.String`, self.serveHTTP)
+}
+
+func (self String) serveHTTP(rew http.ResponseWriter, req *http.Request) {
 	head := self.Head()
+	head.Header = textCharset(head.Header, self.Charset)
 	head.Write(rew)
 
-	_, err := io.WriteString(rew, self.Body)
+	size, err := io.WriteString(rew, self.Body)
 	if err != nil {
 		err = fmt.Errorf(`[goh] failed to write response string: %w`, err)
-		head.errFunc()(rew, req, err, true)
+		head.fail(rew, req, ErrInfo{err, `goh.String`, head.sentStatusIf(size > 0), int64(size), size > 0})
 	}
 }
 
@@ -264,38 +682,144 @@ func StringWith(status int, body string) String {
 	return String{Status: status, Body: body}
 }
 
+/*
+HTTP handler that writes a string as plain text, automatically setting the
+content type `goh.TypeText` with `.Charset`, defaulting to
+`goh.DefaultCharset`. Unlike `goh.String`, doesn't rely on content type
+detection.
+*/
+type Text String
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self Text) Head() Head {
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
+}
+
+// Implement `http.Handler`.
+func (self Text) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Text`, self.serveHTTP)
+}
+
+func (self Text) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(HeadType, withCharset(TypeText, self.Charset))
+
+	head := self.Head()
+	head.Write(rew)
+
+	size, err := io.WriteString(rew, self.Body)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write response text: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.Text`, head.sentStatusIf(size > 0), int64(size), size > 0})
+	}
+}
+
+// Conforms to `goh.Han`.
+func (self Text) Han(*http.Request) http.Handler { return self }
+
+// Shortcut for `goh.TextWith(http.StatusOK, body)`.
+func TextOk(body string) Text {
+	return TextWith(http.StatusOK, body)
+}
+
+// Shortcut for `goh.Text` with specific status and body.
+func TextWith(status int, body string) Text {
+	return Text{Status: status, Body: body}
+}
+
+/*
+HTTP handler that writes a string as HTML, automatically setting the content
+type `goh.TypeHtml` with `.Charset`, defaulting to `goh.DefaultCharset`.
+Useful for server-rendered fragments. The string is written as-is, without
+escaping.
+*/
+type HtmlString String
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self HtmlString) Head() Head {
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
+}
+
+// Implement `http.Handler`.
+func (self HtmlString) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.HtmlString`, self.serveHTTP)
+}
+
+func (self HtmlString) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(HeadType, withCharset(TypeHtml, self.Charset))
+
+	head := self.Head()
+	head.Write(rew)
+
+	size, err := io.WriteString(rew, self.Body)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write response HTML: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.HtmlString`, head.sentStatusIf(size > 0), int64(size), size > 0})
+	}
+}
+
+// Conforms to `goh.Han`.
+func (self HtmlString) Han(*http.Request) http.Handler { return self }
+
+// Shortcut for `goh.HtmlStringWith(http.StatusOK, body)`.
+func HtmlStringOk(body string) HtmlString {
+	return HtmlStringWith(http.StatusOK, body)
+}
+
+// Shortcut for `goh.HtmlString` with specific status and body.
+func HtmlStringWith(status int, body string) HtmlString {
+	return HtmlString{Status: status, Body: body}
+}
+
 /*
 HTTP handler that automatically sets the appropriate JSON headers and encodes
-its body as JSON. The field `.Indent` is passed to the JSON encoder.
+its body as JSON. The field `.Indent` is passed to the JSON encoder. The field
+`.Charset` is appended to the content type, defaulting to
+`goh.DefaultCharset`.
+
+When `.Indent` is empty, the predicate `.Pretty`, defaulting to
+`goh.DefaultPretty`, may enable indentation for specific requests, using
+`goh.DefaultIndent`. For example, to honor "?pretty=1" in every `goh.Json`
+response:
+
+	goh.DefaultPretty = goh.PrettyQuery
 */
 type Json struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
-	Indent  string
-	Body    interface{}
+	Status          int
+	Header          http.Header
+	ErrFunc         ErrFunc
+	AppendHeader    bool
+	NoContentLength bool
+	Language        string
+	Indent          string
+	Pretty          func(*http.Request) bool
+	Charset         string
+	Body            interface{}
 }
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self Json) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
 }
 
 // Implement `http.Handler`.
 func (self Json) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
-	rew.Header().Set(HeadType, TypeJson)
+	observe(rew, req, `goh.Json`, self.serveHTTP)
+}
+
+func (self Json) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(HeadType, withCharset(TypeJson, self.Charset))
 
 	head := self.Head()
 	head.Write(rew)
 
 	writer := spyingWriter{Writer: rew}
 	enc := json.NewEncoder(&writer)
-	enc.SetIndent(``, self.Indent)
+	enc.SetIndent(``, self.indent(req))
 
 	err := enc.Encode(self.Body)
 	if err != nil {
 		err = fmt.Errorf(`[goh] failed to write response as JSON: %w`, err)
-		head.errFunc()(rew, req, err, writer.wrote)
+		head.fail(rew, req, ErrInfo{err, `goh.Json`, head.sentStatusIf(writer.wrote), writer.size, writer.wrote})
 	}
 }
 
@@ -324,7 +848,23 @@ func (self Json) TryBytes() Bytes {
 	if err != nil {
 		panic(err)
 	}
-	return bytesFrom(self.Head(), TypeJson, body)
+	return bytesFrom(self.Head(), withCharset(TypeJson, self.Charset), body)
+}
+
+// Returns `.Indent`, or `goh.DefaultIndent` when pretty output is requested.
+func (self Json) indent(req *http.Request) string {
+	if self.Indent != `` || req == nil {
+		return self.Indent
+	}
+
+	pretty := self.Pretty
+	if pretty == nil {
+		pretty = DefaultPretty
+	}
+	if pretty != nil && pretty(req) {
+		return DefaultIndent
+	}
+	return ``
 }
 
 // Shortcut for `goh.JsonWith(http.StatusOK, body)`.
@@ -339,7 +879,9 @@ func JsonWith(status int, body interface{}) Json {
 
 /*
 HTTP handler that automatically sets the appropriate XML headers and encodes its
-body as XML. The field `.Indent` is passed to the JSON encoder.
+body as XML. The field `.Indent` is passed to the JSON encoder. The field
+`.Charset` is appended to the content type, defaulting to
+`goh.DefaultCharset`.
 
 Caution: this does NOT prepend the processing instruction `<?xml?>`. When you
 don't need to specify the encoding, this instruction is entirely skippable.
@@ -350,24 +892,28 @@ type Xml Json
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self Xml) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
 }
 
 // Implement `http.Handler`.
 func (self Xml) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
-	rew.Header().Set(HeadType, `application/xml`)
+	observe(rew, req, `goh.Xml`, self.serveHTTP)
+}
+
+func (self Xml) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(HeadType, withCharset(TypeXml, self.Charset))
 
 	head := self.Head()
 	head.Write(rew)
 
 	writer := spyingWriter{Writer: rew}
 	enc := xml.NewEncoder(&writer)
-	enc.Indent(``, self.Indent)
+	enc.Indent(``, Json(self).indent(req))
 
 	err := enc.Encode(self.Body)
 	if err != nil {
 		err = fmt.Errorf(`[goh] failed to write response as XML: %w`, err)
-		head.errFunc()(rew, req, err, writer.wrote)
+		head.fail(rew, req, ErrInfo{err, `goh.Xml`, head.sentStatusIf(writer.wrote), writer.size, writer.wrote})
 	}
 }
 
@@ -396,7 +942,7 @@ func (self Xml) TryBytes() Bytes {
 	if err != nil {
 		panic(err)
 	}
-	return bytesFrom(self.Head(), `application/xml`, body)
+	return bytesFrom(self.Head(), withCharset(TypeXml, self.Charset), body)
 }
 
 // Shortcut for `goh.XmlWith(http.StatusOK, body)`.
@@ -409,23 +955,119 @@ func XmlWith(status int, body interface{}) Xml {
 	return Xml{Status: status, Body: body}
 }
 
-// HTTP handler that performs an HTTP redirect.
+/*
+HTTP handler that performs an HTTP redirect. `.Status` must be a redirect
+status between 300 and 399. Otherwise, the redirect is not performed, and the
+error is passed to `.ErrFunc`.
+
+Clients may change the method of non-GET requests to GET when following
+redirects with 301 and 302, which makes these statuses unsuitable for
+endpoints such as form submissions. Use 307 or 308 to preserve the method,
+see `goh.RedirectTemporary` and `goh.RedirectPermanent`, or 303 to switch to
+GET explicitly, see `goh.SeeOther`.
+
+When `.KeepQuery` is true, the query parameters of the incoming request are
+carried over to the target, except for parameters already present in `.Link`.
+When `.Query` is set, its parameters are merged into the target, replacing
+existing parameters with the same keys. This is useful for canonicalization,
+and for login flows which pass along a "next" link:
+
+	goh.Redirect{
+		Status:    http.StatusSeeOther,
+		Link:      `/login`,
+		KeepQuery: true,
+		Query:     url.Values{`next`: {req.URL.Path}},
+	}
+
+See `goh.Redirect.Location`.
+
+By default, `http.Redirect` writes a short HTML body only for GET and HEAD
+requests. When `.HtmlBody` is true, the response always includes a minimal HTML
+document with a link to the target and a "meta refresh" tag, for clients that
+don't follow the "Location" header, and for responses such as 300 "Multiple
+Choices", where the body is expected to present the choice.
+*/
 type Redirect struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
-	Link    string
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Link         string
+
+	KeepQuery bool
+	Query     url.Values
+	HtmlBody  bool
 }
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self Redirect) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
 }
 
 // Implement `http.Handler`.
 func (self Redirect) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
-	self.Head().writeHeaders(rew)
-	http.Redirect(rew, req, self.Link, self.Status)
+	observe(rew, req, `goh.Redirect`, self.serveHTTP)
+}
+
+func (self Redirect) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	head := self.Head()
+	if !isRedirectStatus(self.Status) {
+		err := fmt.Errorf(`[goh] invalid redirect status %v`, self.Status)
+		head.fail(rew, req, ErrInfo{Err: err, Handler: `goh.Redirect`})
+		return
+	}
+
+	head.writeHeaders(rew)
+	if !self.HtmlBody {
+		http.Redirect(rew, req, self.Location(req), self.Status)
+		return
+	}
+
+	// Prevents `http.Redirect` from writing its own body.
+	rew.Header().Set(HeadType, withCharset(TypeHtml, ``))
+	http.Redirect(rew, req, self.Location(req), self.Status)
+
+	link := template.HTMLEscapeString(rew.Header().Get(`Location`))
+	size, err := fmt.Fprintf(
+		rew,
+		`<!doctype html><meta http-equiv="refresh" content="0; url=%[1]v"><a href="%[1]v">%[1]v</a>`+"\n",
+		link,
+	)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write redirect body: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.Redirect`, self.Status, int64(size), true})
+	}
+}
+
+/*
+Returns the redirect target for the given request: `.Link` with query
+parameters merged according to `.KeepQuery` and `.Query`. When neither is
+used, or when `.Link` is malformed, returns `.Link` as-is.
+*/
+func (self Redirect) Location(req *http.Request) string {
+	if !self.KeepQuery && len(self.Query) == 0 {
+		return self.Link
+	}
+
+	link, err := url.Parse(self.Link)
+	if err != nil {
+		return self.Link
+	}
+
+	query := link.Query()
+	if self.KeepQuery && req != nil && req.URL != nil {
+		for key, vals := range req.URL.Query() {
+			if _, ok := query[key]; !ok {
+				query[key] = vals
+			}
+		}
+	}
+	for key, vals := range self.Query {
+		query[key] = vals
+	}
+
+	link.RawQuery = query.Encode()
+	return link.String()
 }
 
 // Conforms to `goh.Han`.
@@ -437,36 +1079,117 @@ func RedirectWith(status int, link string) Redirect {
 }
 
 /*
-Utility type for use together with `goh.Xml`. When encoded as XML, this prepends
-the `<?xml?>` header with version 1.0 and the specified encoding, if any.
-Example usage:
-
-	myXmlDoc := SomeType{SomeField: someValue}
-
-	res := goh.XmlOk(goh.XmlDoc{
-		Encoding: "utf-8",
-		Val: myXmlDoc,
-	})
+Shortcut for `goh.Redirect` with status 303 "See Other", which makes the client
+follow the redirect with GET. Intended for the Post-Redirect-Get pattern in form
+handlers:
 
-Eventual output:
+	func postForm(req *http.Request) http.Handler {
+		// ... process the form ...
+		return goh.SeeOther(`/done`)
+	}
+*/
+func SeeOther(link string) Redirect {
+	return RedirectWith(http.StatusSeeOther, link)
+}
 
-	<?xml version="1.0" encoding="utf-8"?>
-	<SomeType ...>
+/*
+Shortcut for `goh.Redirect` with status 307 "Temporary Redirect", which
+preserves the request method and body, unlike 302.
 */
-type XmlDoc struct {
-	Encoding string
-	Val      interface{}
+func RedirectTemporary(link string) Redirect {
+	return RedirectWith(http.StatusTemporaryRedirect, link)
+}
+
+/*
+Shortcut for `goh.Redirect` with status 308 "Permanent Redirect", which
+preserves the request method and body, unlike 301.
+*/
+func RedirectPermanent(link string) Redirect {
+	return RedirectWith(http.StatusPermanentRedirect, link)
+}
+
+/*
+Same as `goh.SeeOther`, but also sets the given cookies, typically short-lived
+"flash" cookies with messages to be shown on the next page. Nil and invalid
+cookies are ignored.
+*/
+func SeeOtherFlash(link string, cookies ...*http.Cookie) Redirect {
+	out := SeeOther(link)
+	for _, val := range cookies {
+		if val == nil {
+			continue
+		}
+		str := val.String()
+		if str == `` {
+			continue
+		}
+		if out.Header == nil {
+			out.Header = http.Header{}
+		}
+		out.Header.Add(`Set-Cookie`, str)
+	}
+	return out
+}
+
+/*
+Utility type for use together with `goh.Xml`. When encoded as XML, this prepends
+the `<?xml?>` header with the specified version, defaulting to 1.0, the
+specified encoding, if any, and the "standalone" declaration, if specified.
+It's followed by the `<?xml-stylesheet?>` instructions in `.Stylesheets`, and
+by `<!DOCTYPE>` with the content of `.Doctype`, if any. Example usage:
+
+	myXmlDoc := SomeType{SomeField: someValue}
+
+	res := goh.XmlOk(goh.XmlDoc{
+		Encoding: "utf-8",
+		Val: myXmlDoc,
+	})
+
+Eventual output:
+
+	<?xml version="1.0" encoding="utf-8"?>
+	<SomeType ...>
+*/
+type XmlDoc struct {
+	Version     string
+	Encoding    string
+	Standalone  *bool
+	Stylesheets []XmlStylesheet
+	Doctype     string
+	Val         interface{}
+}
+
+/*
+Describes an `<?xml-stylesheet?>` processing instruction, emitted by
+`goh.XmlDoc`. Empty `.Type` defaults to "text/xsl".
+*/
+type XmlStylesheet struct {
+	Href string
+	Type string
 }
 
 // Implement `encoding/xml.Marshaler`, prepending the `<?xml?>` processing
-// instruction, with the specified encoding if available.
+// instruction, with the specified version, encoding, and standalone
+// declaration if available.
 func (self XmlDoc) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
-	inst := xmlVersionInst
+	inst := make([]byte, 0, 64)
+	inst = append(inst, `version=`...)
+	inst = strconv.AppendQuote(inst, orStr(self.Version, `1.0`))
+
 	if self.Encoding != `` {
 		inst = append(inst, ` encoding=`...)
 		inst = strconv.AppendQuote(inst, self.Encoding)
 	}
 
+	if self.Standalone != nil {
+		inst = append(inst, ` standalone=`...)
+		if *self.Standalone {
+			inst = strconv.AppendQuote(inst, `yes`)
+		} else {
+			inst = strconv.AppendQuote(inst, `no`)
+		}
+	}
+
 	err := enc.EncodeToken(xml.ProcInst{
 		Target: `xml`,
 		Inst:   inst,
@@ -475,13 +1198,37 @@ func (self XmlDoc) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
 		return err
 	}
 
+	for _, val := range self.Stylesheets {
+		err := enc.EncodeToken(val.procInst())
+		if err != nil {
+			return err
+		}
+	}
+
+	if self.Doctype != `` {
+		err := enc.EncodeToken(xml.Directive(`DOCTYPE ` + self.Doctype))
+		if err != nil {
+			return err
+		}
+	}
+
 	return enc.Encode(self.Val)
 }
 
+func (self XmlStylesheet) procInst() xml.ProcInst {
+	inst := make([]byte, 0, 64)
+	inst = append(inst, `type=`...)
+	inst = strconv.AppendQuote(inst, orStr(self.Type, `text/xsl`))
+	inst = append(inst, ` href=`...)
+	inst = strconv.AppendQuote(inst, self.Href)
+	return xml.ProcInst{Target: `xml-stylesheet`, Inst: inst}
+}
+
 /*
 HTTP handler that always serves a file at a specific FS path. For each request,
-it verifies that the file exists and delegates to `http.ServeFile`. If the file
-doesn't exist, this responds with 404 without calling `http.ServeFile`,
+it opens the file, verifies that it's not a directory, and serves it via
+`http.ServeContent`. If the file doesn't exist, this responds with 404. Unlike
+`http.ServeFile`, this doesn't redirect or otherwise inspect the request path,
 avoiding its undesirable "smarts".
 
 Unlike `http.ServeFile` and `http.FileServer`, this does not automatically add
@@ -492,44 +1239,331 @@ or a higher-level tool.
 
 Unlike `http.ServeFile` and `http.FileServer`, responding with 404 is optional.
 `goh.File.HanOpt` returns a nil handler if the file is not found. You can use
-this to "try" serving a file, and fall back on something else.
+this to "try" serving a file, and fall back on something else. When the file is
+not found, `.ServeHTTP` uses `.NotFound` if provided, otherwise the 404 page
+in `.ErrorPages`, otherwise `goh.NotFound`, which consults the package-level
+`goh.ErrorPages`. This can be used for custom error pages.
+
+When `.MaxSize` is positive, files larger than this many bytes are considered
+not found. This protects against accidentally exposing large files such as logs
+or database dumps.
+
+When `.ContentType` is set, it's used as the "Content-Type" header. Otherwise,
+when `.DetectType` is true, the content type is detected from the file
+extension via `mime.TypeByExtension`, falling back on sniffing the file content
+via `http.DetectContentType`. Either way, a "Content-Type" in `.Header` takes
+priority. When neither is set, `http.ServeContent` detects the content type in
+the same way, but only for the default status, since a custom `.Status` is
+written first. To send no "Content-Type" at all, set it to nil in `.Header`:
+
+	goh.File{Path: `data.bin`, Header: http.Header{goh.HeadType: nil}}
+
+Like `http.ServeFile`, the response includes "Last-Modified", and
+conditional requests such as "If-Modified-Since" are handled by
+`http.ServeContent`. Entity tags are opt-in. When `.Conditional` is true, the
+response also includes a weak "Etag" derived from the file size and
+modification time (see `goh.FileEtag`). If the request's "If-None-Match" or
+"If-Modified-Since" header matches, responds with 304 without a body,
+regardless of `.Status`. Other than that, when `.Status` is set to anything
+other than 200, conditional headers are ignored, since the status is written
+first.
+
+Byte ranges are supported via `http.ServeContent`: requests with a valid
+"Range" header receive 206 with the requested part, or a multipart response
+for multiple ranges, and "If-Range" is honored, comparing against the
+"Etag" and "Last-Modified" validators. Since the "Etag" generated by
+`.Conditional` is weak, "If-Range" effectively matches only dates. Ranges are
+unsafe for files whose content or length changes between requests, such as
+logs being appended to, since a client may combine parts of different versions.
+For such files, set `.NoRanges`, which ignores "Range" and "If-Range", always
+serves the full content, and responds with "Accept-Ranges: none". Ranges are
+also ignored when `.Status` is set to anything other than 200.
+
+When `.Disposition` is set, usually to `goh.DispositionInline` or
+`goh.DispositionAttachment`, the response includes the "Content-Disposition"
+header, telling the browser whether to display or download the file. The file
+name is `.DispositionName`, defaulting to the base name of `.Path`. See
+`goh.ContentDisposition`.
 */
 type File struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
-	Path    string
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Path         string
+	NotFound     http.Handler
+	ErrorPages   map[int]http.Handler
+	MaxSize      int64
+	ContentType  string
+	DetectType   bool
+	Conditional  bool
+	NoRanges     bool
+
+	Disposition     string
+	DispositionName string
+
+	root string
+	fsys fs.FS
 }
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self File) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
 }
 
 // Implement `http.Handler`.
 func (self File) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
-	if self.Exists() {
-		self.Head().Write(rew)
-		http.ServeFile(rew, req, self.Path)
-	} else {
-		NotFound{}.ServeHTTP(rew, req)
+	observe(rew, req, `goh.File`, self.serveHTTP)
+}
+
+func (self File) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		orNotFound(self.NotFound, self.ErrorPages[http.StatusNotFound]).ServeHTTP(rew, req)
 	}
 }
 
 /*
-Implement `HttpHandlerOpt`. If `.Exists()`, uses `.ServeHTTP` to serve the file
-and returns true. Otherwise returns false.
+Implement `HttpHandlerOpt`. If the file exists, serves it and returns true.
+Otherwise returns false. The file is opened once, and the existence check,
+size check, content type detection, and validators all use the opened handle,
+which is then served via `http.ServeContent`.
 */
 func (self File) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
-	if self.Exists() {
-		self.ServeHTTP(rew, req)
-		return true
+	return observed(rew, req, `goh.File`, self.servedHTTP)
+}
+
+func (self File) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return self.serve(rew, req, self.open)
+}
+
+/*
+Shared implementation of `goh.File` and `goh.FileFS`. Returns false without
+side effects if the file can't be opened, is a directory, or exceeds
+`.MaxSize`.
+*/
+func (self File) serve(rew http.ResponseWriter, req *http.Request, open func(string) (fs.File, error)) bool {
+	file, stat := self.openExisting(open)
+	if file == nil {
+		return false
 	}
-	return false
+	defer file.Close()
+
+	self.serveOpened(rew, req, file, stat)
+	return true
+}
+
+/*
+Opens the file at `.Path`, returning nil if it can't be opened, is a directory,
+or exceeds `.MaxSize`. The caller must close the returned file.
+*/
+func (self File) openExisting(open func(string) (fs.File, error)) (fs.File, os.FileInfo) {
+	if self.Path == `` {
+		return nil, nil
+	}
+
+	file, err := open(self.Path)
+	if err != nil {
+		return nil, nil
+	}
+
+	stat, err := file.Stat()
+	if err != nil || stat.IsDir() || !self.AllowSize(stat.Size()) {
+		file.Close()
+		return nil, nil
+	}
+	return file, stat
 }
 
-// True if a file exists at `.Path`.
-func (self File) Exists() bool { return fileExists(self.Path) }
+// Serves the already opened file. See `.openExisting`.
+func (self File) serveOpened(rew http.ResponseWriter, req *http.Request, file fs.File, stat os.FileInfo) {
+	head := self.Head()
+
+	content, err := readSeeker(file)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to read file %q: %w`, self.Path, err)
+		head.fail(rew, req, ErrInfo{Err: err, Handler: `goh.File`})
+		return
+	}
+
+	header := rew.Header()
+	setNonEmpty(header, `Content-Disposition`, self.ContentDisposition())
+
+	if self.ContentType != `` {
+		header.Set(HeadType, self.ContentType)
+	} else if self.DetectType {
+		header.Set(HeadType, contentType(self.Path, content))
+	}
+
+	if self.Conditional {
+		etag, err := contentEtag(self.fsys, self.Path, stat, content)
+		if err != nil {
+			err = fmt.Errorf(`[goh] failed to read file %q: %w`, self.Path, err)
+			head.fail(rew, req, ErrInfo{Err: err, Handler: `goh.File`})
+			return
+		}
+
+		mtime := stat.ModTime().UTC().Truncate(time.Second)
+		header.Set(`Etag`, etag)
+		if !stat.ModTime().IsZero() {
+			header.Set(`Last-Modified`, mtime.Format(http.TimeFormat))
+		}
+
+		if notModified(req, etag, mtime) {
+			head.writeHeaders(rew)
+			rew.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	/**
+	A custom status is written before `http.ServeContent`, which must not write
+	another one, such as 206, 304, or 412. With `.Conditional`, conditional
+	requests were handled above.
+	*/
+	custom := self.Status != 0 && self.Status != http.StatusOK
+	head.write(rew, false)
+	if self.NoRanges || custom {
+		rew, req = withoutRanges(rew, req, custom)
+	}
+	if custom {
+		req = withoutReqHeaders(req, `If-Match`, `If-None-Match`, `If-Modified-Since`, `If-Unmodified-Since`)
+	}
+
+	// A zero time prevents "Last-Modified" and date-based conditional handling.
+	var mtime time.Time
+	if !custom {
+		mtime = stat.ModTime()
+	}
+	http.ServeContent(rew, req, stat.Name(), mtime, content)
+}
+
+/*
+Used by `goh.File` with `.NoRanges` or a custom status. Returns a request
+without range headers, and a writer which overrides the "Accept-Ranges" header
+set by `http.ServeContent`. When the status was already sent, the writer drops
+the status written by `http.ServeContent`.
+*/
+func withoutRanges(rew http.ResponseWriter, req *http.Request, sent bool) (http.ResponseWriter, *http.Request) {
+	return &noRangesWriter{ResponseWriter: rew, sent: sent}, withoutReqHeaders(req, `Range`, `If-Range`)
+}
+
+// Returns a copy of the request without the given headers, if any are present.
+func withoutReqHeaders(req *http.Request, keys ...string) *http.Request {
+	for ind, key := range keys {
+		if req.Header.Get(key) == `` {
+			continue
+		}
+
+		req = req.Clone(req.Context())
+		for _, key := range keys[ind:] {
+			req.Header.Del(key)
+		}
+		break
+	}
+	return req
+}
+
+type noRangesWriter struct {
+	http.ResponseWriter
+	wrote bool
+	sent  bool
+}
+
+func (self *noRangesWriter) WriteHeader(status int) {
+	if self.sent {
+		return
+	}
+	if !self.wrote && status >= http.StatusOK {
+		self.wrote = true
+		self.Header().Set(`Accept-Ranges`, `none`)
+	}
+	self.ResponseWriter.WriteHeader(status)
+}
+
+func (self *noRangesWriter) Write(src []byte) (int, error) {
+	if !self.wrote {
+		self.WriteHeader(http.StatusOK)
+	}
+	return self.ResponseWriter.Write(src)
+}
+
+func (self *noRangesWriter) Flush() {
+	if !self.wrote {
+		self.WriteHeader(http.StatusOK)
+	}
+	flusher, _ := self.ResponseWriter.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+func (self *noRangesWriter) Unwrap() http.ResponseWriter { return self.ResponseWriter }
+
+/*
+True if a file exists at `.Path`. If `.MaxSize` is positive, the file must also
+not exceed that size.
+*/
+func (self File) Exists() bool { return self.stat() != nil }
+
+func (self File) stat() os.FileInfo {
+	file, stat := self.openExisting(self.open)
+	if file != nil {
+		file.Close()
+	}
+	return stat
+}
+
+/*
+Opens the file at the given path. For files resolved by a `goh.Dir` with
+`.UseRoot`, the file is opened via `os.Root` where supported, see
+`goh.Dir.UseRoot`.
+*/
+func (self File) open(path string) (fs.File, error) {
+	if self.root != `` {
+		return openInRoot(self.root, path)
+	}
+	return os.Open(path)
+}
+
+// True if `.MaxSize` is unset or the given size doesn't exceed it.
+func (self File) AllowSize(size int64) bool {
+	return self.MaxSize <= 0 || size <= self.MaxSize
+}
+
+/*
+Returns the content type to be used for the file: `.ContentType` if set, or the
+detected type if `.DetectType` is true, or an empty string. See the comment on
+`goh.File` for the detection rules.
+*/
+func (self File) Type() string {
+	if self.ContentType != `` {
+		return self.ContentType
+	}
+	if !self.DetectType {
+		return ``
+	}
+
+	out := mime.TypeByExtension(filepath.Ext(self.Path))
+	if out != `` {
+		return out
+	}
+	return sniffFile(self.open, self.Path)
+}
+
+/*
+Returns the "Content-Disposition" header value according to `.Disposition` and
+`.DispositionName`, or an empty string if `.Disposition` is empty.
+*/
+func (self File) ContentDisposition() string {
+	if self.Disposition == `` {
+		return ``
+	}
+	name := self.DispositionName
+	if name == `` && self.Path != `` {
+		name = filepath.Base(self.Path)
+	}
+	return ContentDisposition(self.Disposition, name)
+}
 
 /*
 If `.Exists()`, returns itself as-is. Otherwise returns zero.
@@ -559,7 +1593,8 @@ func (self File) HanOpt(*http.Request) http.Handler {
 /*
 HTTP handler that serves files out of a given directory. Similar to
 `http.FileServer`, but without its undesirable "smarts". This will serve only
-individual files, without directory listings or redirects. In addition, the
+individual files, without redirects, and without directory listings unless
+enabled via `.List`. In addition, the
 method `goh.Dir.HanOpt` supports "try file" functionality, allowing you to
 fall back on serving something else when a requested file is not found.
 
@@ -567,31 +1602,159 @@ The status, header, and err func are copied to each `goh.File` used for each
 response. Because this uses `goh.File` for each request, it doesn't support
 automatically adding headers such as `Content-Type`. See the comment on
 `goh.File`.
+
+When `.Prefix` is set, it's stripped from the request path before resolving
+it against `.Path`. Requests whose path doesn't begin with the prefix are
+considered not found. This allows to mount a dir under a URL path without
+wrapping it in `http.StripPrefix`:
+
+	var han = goh.Dir{Path: `static`, Prefix: `/static/`}
+
+When `.Rewrite` is set, it's applied to the request path after stripping the
+prefix and before resolving the file. This can be used for vanity paths,
+legacy URLs, or hashed-asset manifests. See `goh.Rewrite`.
+
+By default, requests for "dotfiles", where any segment of the request path
+begins with a dot, such as `.env` or `.git/config`, are considered not found,
+regardless of the filter. Such files are also excluded from listings. Set
+`.AllowDotfiles` to opt out, for example to serve `.well-known`.
+
+By default, symlinks inside the directory are not followed: files whose real
+path differs from their path under `.Path` are considered not found, and are
+excluded from listings. When `.FollowSymlinks` is true, or when
+`goh.DefaultFollowSymlinks` is true, symlinks are followed, but only when their
+real path remains inside the real path of `.Path`. Symlinks can never be used
+to escape the directory. `.Path` itself may be a symlink.
+
+Request paths are cleaned and validated before resolving. Paths with invalid
+UTF-8, control characters such as NUL, or `..` segments are considered not
+found.
+
+When `.UseRoot` is true and the package is built with Go 1.24 or later, files
+are opened via `os.Root` rooted at `.Path`, which additionally enforces
+containment at the OS level, including against symlinks and concurrent
+changes to the directory tree. With earlier Go versions, `.UseRoot` has no
+effect.
+
+Requests for directories, including paths ending with a slash, are resolved to
+the index file in that directory. The index file name is `.Index`, defaulting
+to `goh.DefaultIndex`. The filter is applied to the resulting index path.
+
+When `.List` is true, requests for allowed directories without an index file
+are served with an HTML listing of the directory contents, see `goh.DirList`.
+When `.ListJson` is also true, the listing is served as JSON instead, see
+`goh.DirList.Json`.
+
+When `.Fallback` is set, it's used whenever the requested file is not found,
+instead of responding with 404. In this case, the dir never "misses": methods
+`.HanOpt` and `.ServedHTTP` also use the fallback. This is intended for
+single-page apps:
+
+	var han = goh.Dir{
+		Path:     `static`,
+		Fallback: goh.File{Path: `static/index.html`},
+	}
+
+When nothing is found and there's no fallback, `.ServeHTTP` and `.Han` use
+`.NotFound` if provided, otherwise the 404 page in `.ErrorPages`, otherwise
+`goh.NotFound`, which consults the package-level `goh.ErrorPages`. Unlike
+`.Fallback`, this doesn't affect `.HanOpt` and `.ServedHTTP`.
+
+`.NotFound`, `.ErrorPages`, `.MaxSize`, `.DetectType`, `.Conditional`, and
+`.NoRanges` are copied to each `goh.File`.
+Files exceeding `.MaxSize` are considered not found, and are excluded from
+listings. To override the content type for specific files, use `.OnFile`.
+
+When `.OnFile` is set, it's invoked after resolving an existing file and before
+serving it. It may modify the file, for example to add headers based on the
+file extension, or log which files are served. The file's header is a copy,
+and is safe to modify:
+
+	var han = goh.Dir{
+		Path: `static`,
+		OnFile: func(file *goh.File, req *http.Request) {
+			log.Println(`serving`, file.Path, `for`, req.URL.Path)
+		},
+	}
+
+`.CacheControl` maps lowercase file extensions, including the leading dot, to
+values of the "Cache-Control" header, which is set for matching files before
+invoking `.OnFile`:
+
+	var han = goh.Dir{
+		Path: `static`,
+		CacheControl: map[string]string{
+			`.html`: `no-cache`,
+			`.js`:   `public, max-age=31536000, immutable`,
+		},
+	}
 */
 type Dir struct {
-	Status  int
-	Header  http.Header
-	ErrFunc ErrFunc
-	Path    string
-	Filter  Filter
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Path         string
+	Prefix       string
+	Rewrite      Rewrite
+	Filter       Filter
+	Index        string
+	List         bool
+	ListJson     bool
+	ListTmpl     *template.Template
+	Fallback     http.Handler
+	NotFound     http.Handler
+	ErrorPages   map[int]http.Handler
+	MaxSize      int64
+	OnFile       func(*File, *http.Request)
+
+	CacheControl map[string]string
+
+	DetectType     bool
+	Conditional    bool
+	NoRanges       bool
+	AllowDotfiles  bool
+	FollowSymlinks bool
+	UseRoot        bool
 }
 
 // Returns the pseudo-embedded `goh.Head` part.
 func (self Dir) Head() Head {
-	return Head{self.Status, self.Header, self.ErrFunc}
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
 }
 
 // Implement `http.Handler`.
 func (self Dir) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
-	self.Resolve(req).ServeHTTP(rew, req)
+	observe(rew, req, `goh.Dir`, self.serveHTTP)
+}
+
+func (self Dir) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		self.notFound().ServeHTTP(rew, req)
+	}
 }
 
 /*
-Implement `HttpHandlerOpt`. If possible, serves the requested file and returns
-true. Otherwise returns false.
+Implement `HttpHandlerOpt`. If possible, serves the requested file or
+`.Fallback`, and returns true. Otherwise returns false. The requested file is
+opened once, and served from the same handle that was used to check that it
+exists.
 */
 func (self Dir) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
-	return self.Resolve(req).ServedHTTP(rew, req)
+	return observed(rew, req, `goh.Dir`, self.servedHTTP)
+}
+
+func (self Dir) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	file, handle, stat := self.resolveOpened(req)
+	if handle == nil {
+		return servedHTTP(self.hanMissing(req), rew, req)
+	}
+	defer handle.Close()
+
+	return observed(rew, req, `goh.File`, func(rew http.ResponseWriter, req *http.Request) bool {
+		file.serveOpened(rew, req, handle, stat)
+		return true
+	})
 }
 
 // Conforms to `goh.Han`. Always returns non-nil.
@@ -600,28 +1763,230 @@ func (self Dir) Han(req *http.Request) http.Handler {
 	if res != nil {
 		return res
 	}
-	return NotFound{}
+	return self.notFound()
+}
+
+func (self Dir) notFound() http.Handler {
+	return orNotFound(self.NotFound, self.ErrorPages[http.StatusNotFound])
 }
 
-// Conforms to `goh.Han`. Returns nil if the requested file is not found.
+/*
+Conforms to `goh.Han`. If the requested file is not found, returns a directory
+listing (see `.List`), or `.Fallback`, which may be nil.
+*/
 func (self Dir) HanOpt(req *http.Request) http.Handler {
-	return self.Resolve(req).HanOpt(req)
+	file, ok := self.ResolveExisting(req)
+	if ok {
+		return file
+	}
+	return self.hanMissing(req)
 }
 
+// Handler for requests which don't resolve to an existing file.
+func (self Dir) hanMissing(req *http.Request) http.Handler {
+	list := self.ListOpt(req)
+	if list != nil {
+		return list
+	}
+	return self.Fallback
+}
+
+/*
+Resolves the request to a `goh.File`. If the request path is not allowed, the
+resulting file has an empty path and is considered missing. If the request path
+ends with a slash or refers to a directory, it resolves to the index file in
+that directory, see `.Index`.
+*/
 func (self Dir) Resolve(req *http.Request) File {
-	reqPath := strings.TrimPrefix(req.URL.Path, `/`)
-	if strings.Contains(reqPath, `..`) || strings.HasSuffix(reqPath, `/`) {
+	reqPath, ok := self.reqPath(req)
+	if !ok {
 		return self.File(``)
 	}
 
 	filePath := filepath.Join(self.Path, reqPath)
-	if !self.Allow(filePath) {
-		return self.File(``)
+	if reqPath == `` || strings.HasSuffix(reqPath, `/`) || dirExists(filePath) {
+		filePath = filepath.Join(filePath, self.index())
 	}
 
+	if !self.Allow(filePath) || !self.allowReal(filePath) {
+		return self.File(``)
+	}
 	return self.File(filePath)
 }
 
+/*
+Resolves the request to an existing `goh.File`, returning false if the file is
+not found. Applies `.CacheControl`, then invokes `.OnFile` if set. When either
+is used, the file's header is copied first, and is never nil.
+*/
+func (self Dir) ResolveExisting(req *http.Request) (File, bool) {
+	file, handle, _ := self.resolveOpened(req)
+	if handle == nil {
+		return File{}, false
+	}
+	handle.Close()
+	return file, true
+}
+
+/*
+Same as `.ResolveExisting`, but also returns the opened file and its stat,
+which allows `.ServedHTTP` to serve the file without opening it again. The
+returned file is nil if not found, and must be closed by the caller.
+*/
+func (self Dir) resolveOpened(req *http.Request) (File, fs.File, os.FileInfo) {
+	file := self.Resolve(req)
+	handle, stat := file.openExisting(file.open)
+	if handle == nil {
+		return File{}, nil, nil
+	}
+
+	cache := self.CacheControl[strings.ToLower(filepath.Ext(file.Path))]
+	if cache != `` || self.OnFile != nil {
+		file.Header = cloneHeader(file.Header)
+	}
+
+	setNonEmpty(file.Header, `Cache-Control`, cache)
+	if self.OnFile == nil {
+		return file, handle, stat
+	}
+
+	prev := file
+	self.OnFile(&file, req)
+	if file.Path == prev.Path && file.root == prev.root && file.MaxSize == prev.MaxSize {
+		return file, handle, stat
+	}
+
+	// `.OnFile` may redirect to another file, which must be opened instead.
+	handle.Close()
+	handle, stat = file.openExisting(file.open)
+	if handle == nil {
+		return File{}, nil, nil
+	}
+	return file, handle, stat
+}
+
+func (self Dir) reqPath(req *http.Request) (string, bool) {
+	return resolveReqPath(req, self.Prefix, self.Rewrite, self.AllowDotfiles)
+}
+
+func (self Dir) index() string { return orDefaultIndex(self.Index) }
+
+/*
+Common request path resolution used by `goh.Dir` and `goh.FS`: strips the
+prefix, applies the rewrite, and rejects traversal and dotfiles. The output is
+relative, without a leading slash.
+*/
+func resolveReqPath(req *http.Request, prefix string, rewrite Rewrite, allowDotfiles bool) (string, bool) {
+	reqPath, ok := stripPrefix(req.URL.Path, prefix)
+	if !ok {
+		return ``, false
+	}
+
+	if rewrite != nil {
+		reqPath = rewrite.Rewrite(`/` + strings.TrimPrefix(reqPath, `/`))
+	}
+
+	reqPath, ok = cleanReqPath(strings.TrimPrefix(reqPath, `/`))
+	if !ok {
+		return ``, false
+	}
+	if !allowDotfiles && hasDotSegment(reqPath) {
+		return ``, false
+	}
+	return reqPath, true
+}
+
+/*
+Validates and cleans a slash-separated request path without a leading slash.
+Rejects invalid UTF-8, control characters including NUL, `..` segments, and
+paths considered unsafe on the current platform. Collapses redundant slashes
+and `.` segments, preserving a trailing slash, which indicates a directory.
+*/
+func cleanReqPath(val string) (string, bool) {
+	if !utf8.ValidString(val) {
+		return ``, false
+	}
+	for _, char := range val {
+		if char < 0x20 || char == 0x7f {
+			return ``, false
+		}
+	}
+	for _, seg := range strings.Split(val, `/`) {
+		if seg == `..` {
+			return ``, false
+		}
+	}
+
+	if val == `` {
+		return ``, true
+	}
+
+	out := strings.TrimPrefix(path.Clean(`/`+val), `/`)
+	if runtime.GOOS == `windows` && !validWindowsPath(out) {
+		return ``, false
+	}
+	if out != `` && strings.HasSuffix(val, `/`) {
+		out += `/`
+	}
+	return out, true
+}
+
+/*
+True if the slash-separated path can be safely resolved on Windows. Rejects
+backslashes, drive letters and alternate data streams such as
+`readme.md::$DATA`, characters reserved by Windows, segments with trailing dots
+or spaces, which Windows silently strips, and reserved device names such as
+`CON` or `nul.txt`, which refer to devices regardless of the directory or
+extension. Enforced by `goh.Dir` and `goh.FS` only on Windows.
+*/
+func validWindowsPath(val string) bool {
+	if val == `` {
+		return true
+	}
+	if strings.ContainsAny(val, `\:*?"<>|`) {
+		return false
+	}
+
+	for _, seg := range strings.Split(val, `/`) {
+		if strings.HasSuffix(seg, `.`) || strings.HasSuffix(seg, ` `) {
+			return false
+		}
+		if isWindowsDevice(seg) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWindowsDevice(seg string) bool {
+	name := strings.ToUpper(seg)
+	if ind := strings.IndexByte(name, '.'); ind >= 0 {
+		name = strings.TrimRight(name[:ind], ` `)
+	}
+
+	switch name {
+	case `CON`, `PRN`, `AUX`, `NUL`, `CONIN$`, `CONOUT$`:
+		return true
+	}
+
+	if !strings.HasPrefix(name, `COM`) && !strings.HasPrefix(name, `LPT`) {
+		return false
+	}
+
+	switch name[3:] {
+	case `0`, `1`, `2`, `3`, `4`, `5`, `6`, `7`, `8`, `9`, `¹`, `²`, `³`:
+		return true
+	}
+	return false
+}
+
+func orDefaultIndex(val string) string {
+	if val != `` {
+		return val
+	}
+	return DefaultIndex
+}
+
 func (self Dir) Allow(path string) bool {
 	if self.Filter != nil {
 		return self.Filter.Allow(filepath.ToSlash(path))
@@ -629,13 +1994,117 @@ func (self Dir) Allow(path string) bool {
 	return true
 }
 
+/*
+True if the given path is allowed by the symlink policy of this dir, see
+`.FollowSymlinks`. Paths that don't exist are allowed, since they can't be
+served anyway.
+*/
+func (self Dir) allowReal(path string) bool {
+	rootAbs, err := filepath.Abs(self.Path)
+	if err != nil {
+		return false
+	}
+	root, err := filepath.EvalSymlinks(rootAbs)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+
+	if self.followSymlinks() {
+		return real == root || isSubpath(
+			strings.TrimSuffix(filepath.ToSlash(root), `/`),
+			filepath.ToSlash(real),
+		)
+	}
+
+	rel, err := filepath.Rel(rootAbs, abs)
+	return err == nil && real == filepath.Join(root, rel)
+}
+
+func (self Dir) followSymlinks() bool {
+	return self.FollowSymlinks || DefaultFollowSymlinks
+}
+
 func (self Dir) File(path string) File {
+	var root string
+	if self.UseRoot && path != `` {
+		root = self.Path
+	}
+
 	return File{
-		Status:  self.Status,
-		Header:  self.Header,
-		ErrFunc: self.ErrFunc,
-		Path:    path,
+		Status:       self.Status,
+		Header:       self.Header,
+		ErrFunc:      self.ErrFunc,
+		AppendHeader: self.AppendHeader,
+		Path:         path,
+		NotFound:     self.NotFound,
+		ErrorPages:   self.ErrorPages,
+		MaxSize:      self.MaxSize,
+		DetectType:   self.DetectType,
+		Conditional:  self.Conditional,
+		NoRanges:     self.NoRanges,
+		root:         root,
+	}
+}
+
+/*
+Sequence of `goh.Dir`, tried in order. Serves the first match, such as an
+existing file, falling back on `goh.NotFound`. This can be used for layered
+directories, such as overrides followed by defaults:
+
+	var han = goh.Dirs{
+		{Path: `theme/static`},
+		{Path: `default/static`},
+	}
+
+Each dir is tried via `goh.Dir.HanOpt`, which means settings such as
+`.Fallback` and `.List` apply per dir. Usually, only the last dir should have a
+fallback.
+*/
+type Dirs []Dir
+
+// Implement `http.Handler`.
+func (self Dirs) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Dirs`, self.serveHTTP)
+}
+
+func (self Dirs) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		NotFound{}.ServeHTTP(rew, req)
+	}
+}
+
+// Implement `goh.HttpHandlerOpt`. Returns true if any dir served the request.
+func (self Dirs) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.Dirs`, self.servedHTTP)
+}
+
+func (self Dirs) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return servedHTTP(self.HanOpt(req), rew, req)
+}
+
+// Conforms to `goh.Han`. Always returns non-nil.
+func (self Dirs) Han(req *http.Request) http.Handler {
+	return orNotFound(self.HanOpt(req))
+}
+
+// Conforms to `goh.Han`. Returns the first match, or nil.
+func (self Dirs) HanOpt(req *http.Request) http.Handler {
+	for _, dir := range self {
+		han := dir.HanOpt(req)
+		if han != nil {
+			return han
+		}
 	}
+	return nil
 }
 
 /*
@@ -653,6 +2122,47 @@ type Filter interface {
 	Allow(string) bool
 }
 
+/*
+Used by `goh.Dir` to rewrite request paths before resolving them. The input to
+`.Rewrite` is the URL path after stripping `goh.Dir.Prefix`, always with a
+leading slash. The output is resolved against `goh.Dir.Path`. For example:
+
+	dir := goh.Dir{Path: `static`, Rewrite: goh.Rewrites{`/old`: `/new`}}
+	req := &http.Request{URL: &url.URL{Path: `/old`}}
+	dir.Han(req)
+	->
+	goh.File{Path: `static/new`}
+*/
+type Rewrite interface {
+	Rewrite(string) string
+}
+
+// Function type that implements `goh.Rewrite`.
+type RewriteFunc func(string) string
+
+// Implement `goh.Rewrite` by calling itself. If nil, returns the input as-is.
+func (self RewriteFunc) Rewrite(val string) string {
+	if self != nil {
+		return self(val)
+	}
+	return val
+}
+
+/*
+Implements `goh.Rewrite` by looking up the input path in the map. Paths missing
+from the map are returned as-is.
+*/
+type Rewrites map[string]string
+
+// Implement `goh.Rewrite`.
+func (self Rewrites) Rewrite(val string) string {
+	out, ok := self[val]
+	if ok {
+		return out
+	}
+	return val
+}
+
 /*
 Variant of `http.Handler` that may or may not serve the request. If capable
 of serving the request, must serve it and return true. Otherwise, must return
@@ -666,7 +2176,9 @@ type HttpHandlerOpt interface {
 /*
 Function type that implements `goh.Filter`. Example usage:
 
-	goh.Dir{Path: `.`, Filter: goh.FilterFunc(regexp.MustCompile(`^status/`))}
+	goh.Dir{Path: `.`, Filter: goh.FilterFunc(regexp.MustCompile(`^static/`).MatchString)}
+
+For regexps, also see `goh.RegexpFilter` and `goh.DenyRegexp`.
 */
 type FilterFunc func(string) bool
 
@@ -678,6 +2190,32 @@ func (self FilterFunc) Allow(val string) bool {
 	return false
 }
 
+/*
+Implements `goh.Filter` by allowing only paths that match the regexp. A nil
+regexp allows nothing. Example usage:
+
+	goh.Dir{Path: `.`, Filter: goh.RegexpFilter{Regexp: regexp.MustCompile(`^static/`)}}
+*/
+type RegexpFilter struct{ *regexp.Regexp }
+
+// Implement `goh.Filter`.
+func (self RegexpFilter) Allow(val string) bool {
+	return self.Regexp != nil && self.MatchString(val)
+}
+
+/*
+Implements `goh.Filter` by denying paths that match the regexp. A nil regexp
+denies nothing. Example usage:
+
+	goh.Dir{Path: `.`, Filter: goh.DenyRegexp{Regexp: regexp.MustCompile(`\.map$`)}}
+*/
+type DenyRegexp struct{ *regexp.Regexp }
+
+// Implement `goh.Filter`.
+func (self DenyRegexp) Allow(val string) bool {
+	return self.Regexp == nil || !self.MatchString(val)
+}
+
 /*
 Implements `goh.Filter` by requiring that the input path is contained within one
 of the given directories. "Contained" means it begins with the directory path
@@ -695,22 +2233,131 @@ func (self AllowDirs) Allow(val string) bool {
 	return false
 }
 
+/*
+Implements `goh.Filter` by requiring that the input path has one of the given
+extensions, such as ".css". Comparison is case-insensitive. Example usage:
+
+	goh.Dir{Path: `static`, Filter: goh.AllowExts{`.css`, `.js`, `.png`}}
+*/
+type AllowExts []string
+
+// Implement `goh.Filter`.
+func (self AllowExts) Allow(val string) bool {
+	ext := path.Ext(val)
+	if ext == `` {
+		return false
+	}
+	for _, val := range self {
+		if strings.EqualFold(val, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Implements `goh.Filter` via glob patterns. The input path is allowed if it
+// matches any pattern in `.Include` (or if `.Include` is empty) and doesn't
+// match any pattern in `.Exclude`. Patterns use the syntax of `path.Match`,
+// with the addition of "**" as a path segment that matches any number of
+// segments, including zero. Malformed patterns never match. Example usage:
+//
+//	goh.Dir{Path: `static`, Filter: goh.GlobFilter{
+//		Include: []string{`static/**`},
+//		Exclude: []string{`static/**/*.map`},
+//	}}
+type GlobFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Implement `goh.Filter`.
+func (self GlobFilter) Allow(val string) bool {
+	return (len(self.Include) == 0 || matchGlobs(self.Include, val)) &&
+		!matchGlobs(self.Exclude, val)
+}
+
+func matchGlobs(patterns []string, val string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(strings.Split(pattern, `/`), strings.Split(val, `/`)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGlob(pattern, val []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == `**` {
+			for ind := range val {
+				if matchGlob(pattern[1:], val[ind:]) {
+					return true
+				}
+			}
+			return matchGlob(pattern[1:], nil)
+		}
+
+		if len(val) == 0 {
+			return false
+		}
+		ok, _ := path.Match(pattern[0], val[0])
+		if !ok {
+			return false
+		}
+
+		pattern, val = pattern[1:], val[1:]
+	}
+	return len(val) == 0
+}
+
 /*
 Zero-sized handler that returns with 404 without any additional headers or body
-content. Used internally by `goh.File`.
+content. Used internally by `goh.File` and `goh.Dir` when no custom `.NotFound`
+handler is provided. When `goh.ErrorPages` has a 404 page, serves that instead.
 */
 type NotFound struct{}
 
-func (NotFound) ServeHTTP(rew http.ResponseWriter, _ *http.Request) {
-	rew.WriteHeader(http.StatusNotFound)
+func (self NotFound) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.NotFound`, self.serveHTTP)
+}
+
+func (NotFound) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !serveErrorPage(http.StatusNotFound, rew, req) {
+		writeDefaultHeader(rew.Header())
+		rew.WriteHeader(http.StatusNotFound)
+	}
 }
 
 // Conforms to `goh.Han`, returning self.
 func (self NotFound) Han(req *http.Request) http.Handler { return self }
 
+/*
+Handler that responds with 405, setting the "Allow" header to the given
+methods, without any body content. Used internally by `goh.ByMethod`. When
+`goh.ErrorPages` has a 405 page, serves that after setting the header.
+*/
+type MethodNotAllowed struct{ Allow []string }
+
+// Implement `http.Handler`.
+func (self MethodNotAllowed) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.MethodNotAllowed`, self.serveHTTP)
+}
+
+func (self MethodNotAllowed) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(`Allow`, strings.Join(self.Allow, `, `))
+	if !serveErrorPage(http.StatusMethodNotAllowed, rew, req) {
+		writeDefaultHeader(rew.Header())
+		rew.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self MethodNotAllowed) Han(*http.Request) http.Handler { return self }
+
 /*
 Runs the provided function, returning the resulting `http.Handler`. Catches
-panics and converts them to a simple error responder via `Err`.
+panics and converts them to `goh.ErrPanic`, which captures the stack trace, and
+serves itself via `goh.HandleErr`. Errors implementing `goh.HttpStatus`, such
+as `goh.ErrStatus`, determine the status of the response.
 */
 func Handler(fun func() http.Handler) (out http.Handler) {
 	defer recHandler(&out)
@@ -719,22 +2366,96 @@ func Handler(fun func() http.Handler) (out http.Handler) {
 
 /*
 Shortcut for serving the response generated by the provided function. Catches
-panics, serving the resulting errors as plain text via `Err`.
+panics, serving the resulting errors via `goh.HandleErr`. See `goh.Handler`.
 */
 func Respond(rew http.ResponseWriter, req *http.Request, fun func() http.Handler) {
 	Handler(fun).ServeHTTP(rew, req)
 }
 
-var xmlVersionInst = []byte(`version="1.0"`)
+/*
+Variant of `goh.Handler` for functions conforming to `goh.Han`, which receive
+the request, avoiding the need for a closure. Catches panics like
+`goh.Handler`.
+*/
+func HandlerReq(req *http.Request, fun Han) (out http.Handler) {
+	defer recHandler(&out)
+	return fun(req)
+}
+
+/*
+Wraps the given function with panic recovery, using `goh.HandlerReq`. This
+allows to apply panic safety when registering routes, rather than in each
+request:
+
+	var routes = map[string]goh.Han{
+		`/users`: goh.TryHan(getUsers),
+	}
+*/
+func TryHan(fun Han) Han {
+	return func(req *http.Request) http.Handler { return HandlerReq(req, fun) }
+}
+
+/*
+Variant of `goh.Respond` for functions conforming to `goh.Han`. Serves the
+response generated by `goh.HandlerReq`. Example usage:
+
+	func handleRequest(rew http.ResponseWriter, req *http.Request) {
+		goh.RespondReq(rew, req, someHan)
+	}
+
+	func someHan(req *http.Request) http.Handler {
+		return goh.StringOk(req.URL.Path)
+	}
+*/
+func RespondReq(rew http.ResponseWriter, req *http.Request, fun Han) {
+	HandlerReq(req, fun).ServeHTTP(rew, req)
+}
+
+/*
+Variant of `goh.Respond` for functions that return an error instead of
+panicking. A non-nil error is served via `goh.HandleErr`, which uses the
+status-aware mapping of `goh.ErrHttpStatus`. Panics are caught like in
+`goh.Handler`. A nil handler without an error is served as `goh.NotFound`.
+Example usage:
+
+	func handleRequest(rew http.ResponseWriter, req *http.Request) {
+		goh.RespondErr(rew, req, func() (http.Handler, error) {
+			user, err := findUser(req)
+			if err != nil {
+				return nil, err
+			}
+			return goh.JsonOk(user), nil
+		})
+	}
+*/
+func RespondErr(rew http.ResponseWriter, req *http.Request, fun func() (http.Handler, error)) {
+	Handler(func() http.Handler {
+		han, err := fun()
+		if err != nil {
+			return errHandler{err}
+		}
+		return orNotFound(han)
+	}).ServeHTTP(rew, req)
+}
+
+// Serves the error via `goh.HandleErr`.
+type errHandler struct{ err error }
+
+func (self errHandler) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	HandleErr(rew, req, self.err, false)
+}
 
 type spyingWriter struct {
 	io.Writer
 	wrote bool
+	size  int64
 }
 
 func (self *spyingWriter) Write(chunk []byte) (int, error) {
 	self.wrote = true
-	return self.Writer.Write(chunk)
+	size, err := self.Writer.Write(chunk)
+	self.size += int64(size)
+	return size, err
 }
 
 func errMsg(err error) (msg string) {
@@ -753,13 +2474,7 @@ func recHandler(ptr *http.Handler) {
 		return
 	}
 
-	err, _ := val.(error)
-	if err != nil {
-		*ptr = Err(err)
-		return
-	}
-
-	*ptr = StringWith(http.StatusInternalServerError, fmt.Sprint(val))
+	*ptr = ErrPanic{Val: val, Stack: string(debug.Stack())}
 }
 
 func bytesFrom(head Head, contentType string, body []byte) Bytes {
@@ -773,22 +2488,171 @@ func bytesFrom(head Head, contentType string, body []byte) Bytes {
 	}
 
 	return Bytes{
-		Status:  head.Status,
-		Header:  head.Header,
-		ErrFunc: head.ErrFunc,
-		Body:    body,
+		Status:          head.Status,
+		Header:          head.Header,
+		ErrFunc:         head.ErrFunc,
+		AppendHeader:    head.AppendHeader,
+		NoContentLength: head.NoContentLength,
+		Language:        head.Language,
+		Body:            body,
+	}
+}
+
+/*
+Returns a weak entity tag derived from the file's size and modification time,
+such as `W/"1f4-17c2a5c3e8a0b000"`. Used by `goh.File` when `.Conditional` is
+true.
+*/
+func FileEtag(stat os.FileInfo) string {
+	return `W/"` + strconv.FormatInt(stat.Size(), 16) + `-` +
+		strconv.FormatInt(stat.ModTime().UnixNano(), 16) + `"`
+}
+
+/*
+True if the request's "If-None-Match" or "If-Modified-Since" header indicates
+that the client already has the version with the given validators. Zero mtime
+is ignored.
+*/
+func notModified(req *http.Request, etag string, mtime time.Time) bool {
+	if req == nil || !isSafeMethod(req.Method) {
+		return false
+	}
+
+	match := req.Header.Get(`If-None-Match`)
+	if match != `` {
+		return etag != `` && etagMatch(match, etag)
+	}
+
+	if mtime.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get(`If-Modified-Since`))
+	return err == nil && !mtime.After(since)
+}
+
+// Weak comparison of entity tags, as used for "If-None-Match".
+func etagMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, `W/`)
+	for _, val := range strings.Split(header, `,`) {
+		val = strings.TrimSpace(val)
+		if val == `*` || strings.TrimPrefix(val, `W/`) == etag {
+			return true
+		}
 	}
+	return false
 }
 
-func fileExists(path string) bool {
+func isRedirectStatus(val int) bool { return val >= 300 && val <= 399 }
+
+func isSafeMethod(val string) bool {
+	return val == `` || val == http.MethodGet || val == http.MethodHead
+}
+
+// Returns a copy of the header, which is never nil.
+func cloneHeader(val http.Header) http.Header {
+	if val == nil {
+		return http.Header{}
+	}
+	return val.Clone()
+}
+
+func sniffFile(open func(string) (fs.File, error), path string) string {
+	file, err := open(path)
+	if err != nil {
+		return ``
+	}
+	defer file.Close()
+
+	var buf [512]byte
+	size, _ := io.ReadFull(file, buf[:])
+	return http.DetectContentType(buf[:size])
+}
+
+// Returns the first non-nil handler, falling back on `goh.NotFound`.
+func orNotFound(vals ...http.Handler) http.Handler {
+	for _, val := range vals {
+		if val != nil {
+			return val
+		}
+	}
+	return NotFound{}
+}
+
+func setNonEmpty(head http.Header, key, val string) {
+	if val != `` {
+		head.Set(key, val)
+	}
+}
+
+/*
+Strips the given URL path prefix, requiring it to be followed by a slash or
+the end of the path, unless the prefix itself ends with a slash.
+*/
+func stripPrefix(path, prefix string) (string, bool) {
+	if prefix == `` {
+		return path, true
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return ``, false
+	}
+
+	rem := path[len(prefix):]
+	if strings.HasSuffix(prefix, `/`) || rem == `` || strings.HasPrefix(rem, `/`) {
+		return rem, true
+	}
+	return ``, false
+}
+
+// True if any segment of the slash-separated path begins with a dot.
+func hasDotSegment(path string) bool {
+	for _, val := range strings.Split(path, `/`) {
+		if strings.HasPrefix(val, `.`) {
+			return true
+		}
+	}
+	return false
+}
+
+func dirExists(path string) bool {
 	if path == `` {
 		return false
 	}
 	stat, _ := os.Stat(path)
-	return stat != nil && !stat.IsDir()
+	return stat != nil && stat.IsDir()
 }
 
 func isSubpath(sup, sub string) bool {
 	return strings.HasPrefix(sub, sup) &&
 		strings.HasPrefix(sub[len(sup):], `/`)
 }
+
+// Appends the charset parameter, defaulting to `goh.DefaultCharset`.
+func withCharset(typ, charset string) string {
+	if charset == `` {
+		charset = DefaultCharset
+	}
+	if charset == `` {
+		return typ
+	}
+	return typ + `; charset=` + charset
+}
+
+/*
+If the header has a "text/*" content type without a charset, returns a copy
+with the charset appended. Otherwise returns the header as-is.
+*/
+func textCharset(head http.Header, charset string) http.Header {
+	val := head.Get(HeadType)
+	if !strings.HasPrefix(val, `text/`) || strings.Contains(val, `charset=`) {
+		return head
+	}
+
+	typ := withCharset(val, charset)
+	if typ == val {
+		return head
+	}
+
+	head = head.Clone()
+	head.Set(HeadType, typ)
+	return head
+}
@@ -7,9 +7,14 @@ import (
 	ht "net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var (
@@ -21,6 +26,8 @@ var (
 	_ = http.Handler(Redirect{})
 	_ = http.Handler(File{})
 	_ = http.Handler(Dir{})
+	_ = http.Handler(Dirs{})
+	_ = http.Flusher((*noRangesWriter)(nil))
 	_ = http.Handler(NotFound{})
 )
 
@@ -35,6 +42,8 @@ var (
 	_ = Han(File{}.HanOpt)
 	_ = Han(Dir{}.Han)
 	_ = Han(Dir{}.HanOpt)
+	_ = Han(Dirs{}.Han)
+	_ = Han(Dirs{}.HanOpt)
 	_ = Han(NotFound{}.Han)
 )
 
@@ -49,7 +58,10 @@ type XmlVal struct {
 
 var (
 	headSrc = http.Header{`One`: {`two`}, `three`: {`four`}}
-	headExp = http.Header{`One`: {`two`}, `three`: {`four`}}
+	headExp = http.Header{`One`: {`two`}, `Three`: {`four`}}
+
+	typeJsonUtf8 = TypeJson + `; charset=utf-8`
+	typeXmlUtf8  = TypeXml + `; charset=utf-8`
 )
 
 type Dict = map[string]interface{}
@@ -75,7 +87,7 @@ func TestTryJsonBytes(t *testing.T) {
 		t,
 		Bytes{
 			Status: http.StatusOK,
-			Header: http.Header{HeadType: {TypeJson}},
+			Header: http.Header{HeadType: {typeJsonUtf8}},
 			Body:   []byte(`{"val":"one"}`),
 		},
 		TryJsonBytes(JsonVal{`one`}),
@@ -100,6 +112,204 @@ func TestHead_full(t *testing.T) {
 	eq(t, headExp, rew.Result().Header)
 }
 
+type statusRecorder struct {
+	*ht.ResponseRecorder
+	statuses []int
+}
+
+func (self *statusRecorder) WriteHeader(val int) {
+	self.statuses = append(self.statuses, val)
+	self.ResponseRecorder.WriteHeader(val)
+}
+
+func TestWriteStatusOk(t *testing.T) {
+	test := func(exp []int, han http.Handler) {
+		t.Helper()
+		rew := &statusRecorder{ResponseRecorder: ht.NewRecorder()}
+		han.ServeHTTP(rew, pathReq(`/readme.md`))
+		eq(t, exp, rew.statuses)
+	}
+
+	test(nil, StringOk(`hello`))
+	test(nil, String{Body: `hello`})
+	test([]int{http.StatusCreated}, StringWith(http.StatusCreated, `hello`))
+
+	WriteStatusOk = true
+	t.Cleanup(func() { WriteStatusOk = false })
+
+	test([]int{http.StatusOK}, StringOk(`hello`))
+	test([]int{http.StatusOK}, String{Body: `hello`})
+	test([]int{http.StatusCreated}, StringWith(http.StatusCreated, `hello`))
+	test([]int{http.StatusOK}, File{Path: `readme.md`})
+}
+
+func TestNoContentLength(t *testing.T) {
+	srv := ht.NewServer(ByMethod{
+		http.MethodGet: If{
+			Cond: func(req *http.Request) bool { return req.URL.Path == `/skip` },
+			Then: String{Body: `hello`, NoContentLength: true},
+			Else: String{Body: `hello`},
+		},
+	})
+	t.Cleanup(srv.Close)
+
+	test := func(path string, exp int64) {
+		t.Helper()
+		res, err := http.Get(srv.URL + path)
+		try(err)
+		defer res.Body.Close()
+		eq(t, exp, res.ContentLength)
+	}
+
+	test(`/`, 5)
+	test(`/skip`, -1)
+}
+
+func TestHead_AppendHeader(t *testing.T) {
+	src := http.Header{`Vary`: {`Accept`}, `One`: {`two`}}
+
+	t.Run(`replace`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		rew.Header().Set(`Vary`, `Origin`)
+		Head{Header: src}.Write(rew)
+		eq(t, http.Header{`Vary`: {`Accept`}, `One`: {`two`}}, rew.Result().Header)
+	})
+
+	t.Run(`append`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		rew.Header().Set(`Vary`, `Origin`)
+		String{Header: src, AppendHeader: true}.ServeHTTP(rew, nil)
+		eq(t, []string{`Origin`, `Accept`}, rew.Header().Values(`Vary`))
+		eq(t, `two`, rew.Header().Get(`One`))
+	})
+
+	t.Run(`no aliasing`, func(t *testing.T) {
+		for _, appending := range []bool{false, true} {
+			rew := ht.NewRecorder()
+			Head{Header: src, AppendHeader: appending}.Write(rew)
+			rew.Header()[`One`][0] = `mutated`
+			rew.Header().Add(`Vary`, `Origin`)
+			eq(t, http.Header{`Vary`: {`Accept`}, `One`: {`two`}}, src)
+		}
+	})
+}
+
+func TestHead_Language(t *testing.T) {
+	rew := ht.NewRecorder()
+	String{
+		Header:   http.Header{`Vary`: {`Accept-Encoding, accept-language`}},
+		Language: `en-US`,
+		Body:     `hello`,
+	}.ServeHTTP(rew, nil)
+
+	eq(t, `en-US`, rew.Header().Get(`Content-Language`))
+	eq(t, []string{`Accept-Encoding, accept-language`}, rew.Header().Values(`Vary`))
+
+	rew = ht.NewRecorder()
+	Json{Language: `de`, Body: 10}.ServeHTTP(rew, nil)
+	eq(t, `de`, rew.Header().Get(`Content-Language`))
+	eq(t, []string{`Accept-Language`}, rew.Header().Values(`Vary`))
+
+	rew = ht.NewRecorder()
+	String{Body: `hello`}.ServeHTTP(rew, nil)
+	eq(t, ``, rew.Header().Get(`Content-Language`))
+	eq(t, ``, rew.Header().Get(`Vary`))
+}
+
+func TestAddVary(t *testing.T) {
+	head := http.Header{}
+	AddVary(head, `Accept`, `Origin`)
+	AddVary(head, `origin`, ` `, `Accept-Encoding`)
+	eq(t, []string{`Accept`, `Origin`, `Accept-Encoding`}, head.Values(`Vary`))
+
+	head = http.Header{`Vary`: {`*`}}
+	AddVary(head, `Accept`)
+	eq(t, []string{`*`}, head.Values(`Vary`))
+}
+
+func TestHead_shared_concurrent(t *testing.T) {
+	han := StringWith(http.StatusOK, `hello`)
+	han.Header = http.Header{`Vary`: make([]string, 1, 8)}
+	han.Header[`Vary`][0] = `Accept`
+
+	var group sync.WaitGroup
+	for ind := 0; ind < 8; ind++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			rew := ht.NewRecorder()
+			han.ServeHTTP(rew, nil)
+			rew.Header().Add(`Vary`, `Origin`)
+			rew.Header()[`Vary`][0] = `mutated`
+		}()
+	}
+	group.Wait()
+
+	eq(t, []string{`Accept`}, han.Header[`Vary`])
+	eq(t, `Accept`, han.Header[`Vary`][:2][0])
+	eq(t, ``, han.Header[`Vary`][:2][1])
+}
+
+func TestHead_canonical_keys(t *testing.T) {
+	t.Run(`canonical`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		rew.Header().Set(`X-One`, `existing`)
+		Head{Header: http.Header{`x-one`: {`two`}, `content-type`: {`text/css`}}}.Write(rew)
+
+		eq(t, http.Header{`X-One`: {`two`}, `Content-Type`: {`text/css`}}, rew.Header())
+		eq(t, `text/css`, rew.Header().Get(HeadType))
+	})
+
+	t.Run(`raw`, func(t *testing.T) {
+		RawHeaderKeys = true
+		t.Cleanup(func() { RawHeaderKeys = false })
+
+		rew := ht.NewRecorder()
+		Head{Header: http.Header{`x-one`: {`two`}}}.Write(rew)
+		eq(t, http.Header{`x-one`: {`two`}}, rew.Header())
+	})
+}
+
+func TestDefaultHeader(t *testing.T) {
+	prev := DefaultHeader
+	DefaultHeader = http.Header{`Server`: {`goh`}, `X-One`: {`default`}}
+	t.Cleanup(func() { DefaultHeader = prev })
+
+	t.Run(`merged`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		rew.Header().Set(`X-Two`, `existing`)
+		String{Header: http.Header{`X-One`: {`own`}}, Body: `hello`}.ServeHTTP(rew, nil)
+
+		eq(t, `goh`, rew.Header().Get(`Server`))
+		eq(t, `own`, rew.Header().Get(`X-One`))
+		eq(t, `existing`, rew.Header().Get(`X-Two`))
+	})
+
+	t.Run(`not overriding response`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		rew.Header().Set(`Server`, `other`)
+		Bytes{}.ServeHTTP(rew, nil)
+		eq(t, `other`, rew.Header().Get(`Server`))
+	})
+
+	t.Run(`bodiless`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		NotFound{}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, `goh`, rew.Header().Get(`Server`))
+
+		rew = ht.NewRecorder()
+		MethodNotAllowed{}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, `goh`, rew.Header().Get(`Server`))
+	})
+
+	t.Run(`copied`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Bytes{}.ServeHTTP(rew, nil)
+		rew.Header()[`Server`][0] = `mutated`
+		eq(t, `goh`, DefaultHeader.Get(`Server`))
+	})
+}
+
 func TestReader(t *testing.T) {
 	rew := ht.NewRecorder()
 
@@ -122,6 +332,54 @@ func TestBytes(t *testing.T) {
 	eq(t, src, rew.Body.String())
 }
 
+func TestBytesTyped(t *testing.T) {
+	eq(
+		t,
+		Bytes{Status: http.StatusOK, Header: http.Header{HeadType: {TypeWasm}}, Body: []byte(`one`)},
+		BytesTyped(http.StatusOK, TypeWasm, []byte(`one`)),
+	)
+	eq(t, Bytes{Status: http.StatusOK}, BytesTyped(http.StatusOK, ``, nil))
+
+	rew := ht.NewRecorder()
+	BytesTyped(http.StatusCreated, TypeSvg, []byte(`<svg/>`)).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusCreated, rew.Code)
+	eq(t, TypeSvg, rew.Header().Get(HeadType))
+	eq(t, `<svg/>`, rew.Body.String())
+}
+
+func TestNoContent(t *testing.T) {
+	rew := ht.NewRecorder()
+	NoContent().ServeHTTP(rew, nil)
+
+	eq(t, http.StatusNoContent, rew.Code)
+	eq(t, http.Header{}, rew.Result().Header)
+	eq(t, ``, rew.Body.String())
+}
+
+func TestCreated(t *testing.T) {
+	eq(t, Bytes{Status: http.StatusCreated}, Created(``))
+
+	rew := ht.NewRecorder()
+	Created(`/one/two`, []byte(`{"id":"two"}`)...).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusCreated, rew.Code)
+	eq(t, `/one/two`, rew.Header().Get(`Location`))
+	eq(t, `{"id":"two"}`, rew.Body.String())
+}
+
+func TestAccepted(t *testing.T) {
+	eq(t, Bytes{Status: http.StatusAccepted}, Accepted(``))
+
+	rew := ht.NewRecorder()
+	Accepted(`/jobs/one`).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusAccepted, rew.Code)
+	eq(t, `/jobs/one`, rew.Header().Get(`Location`))
+	eq(t, `/jobs/one`, rew.Header().Get(`Content-Location`))
+	eq(t, ``, rew.Body.String())
+}
+
 func TestString(t *testing.T) {
 	rew := ht.NewRecorder()
 
@@ -133,11 +391,52 @@ func TestString(t *testing.T) {
 	eq(t, src, rew.Body.String())
 }
 
+func TestText(t *testing.T) {
+	rew := ht.NewRecorder()
+	Text{Status: 201, Header: headSrc, Body: `<p>hello</p>`}.ServeHTTP(rew, nil)
+
+	headExp := headExp.Clone()
+	headExp.Set(HeadType, `text/plain; charset=utf-8`)
+
+	eq(t, 201, rew.Code)
+	eq(t, headExp, rew.Result().Header)
+	eq(t, `<p>hello</p>`, rew.Body.String())
+
+	eq(t, Text{Status: http.StatusOK, Body: `hello`}, TextOk(`hello`))
+	eq(t, Text{Status: http.StatusAccepted, Body: `hello`}, TextWith(http.StatusAccepted, `hello`))
+}
+
+func TestHtmlString(t *testing.T) {
+	rew := ht.NewRecorder()
+	HtmlString{Status: 201, Header: headSrc, Body: `<p>hello</p>`}.ServeHTTP(rew, nil)
+
+	headExp := headExp.Clone()
+	headExp.Set(HeadType, `text/html; charset=utf-8`)
+
+	eq(t, 201, rew.Code)
+	eq(t, headExp, rew.Result().Header)
+	eq(t, `<p>hello</p>`, rew.Body.String())
+
+	eq(t, HtmlString{Status: http.StatusOK, Body: `hello`}, HtmlStringOk(`hello`))
+	eq(t, HtmlString{Status: http.StatusAccepted, Body: `hello`}, HtmlStringWith(http.StatusAccepted, `hello`))
+}
+
+func TestHtmlBytes(t *testing.T) {
+	rew := ht.NewRecorder()
+	HtmlBytesWith(http.StatusAccepted, []byte(`<p>hello</p>`)).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusAccepted, rew.Code)
+	eq(t, `text/html; charset=utf-8`, rew.Header().Get(HeadType))
+	eq(t, `<p>hello</p>`, rew.Body.String())
+
+	eq(t, HtmlBytes{Status: http.StatusOK, Body: []byte(`hello`)}, HtmlBytesOk([]byte(`hello`)))
+}
+
 func TestJson(t *testing.T) {
 	rew := ht.NewRecorder()
 
 	headExp := headExp.Clone()
-	headExp.Set(`content-type`, TypeJson)
+	headExp.Set(`content-type`, typeJsonUtf8)
 
 	Json{Status: 201, Header: headSrc, Body: JsonVal{`hello world`}}.ServeHTTP(rew, nil)
 
@@ -146,6 +445,33 @@ func TestJson(t *testing.T) {
 	eq(t, `{"val":"hello world"}`, strings.TrimSpace(rew.Body.String()))
 }
 
+func TestCharset(t *testing.T) {
+	test := func(exp string, han http.Handler) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, nil)
+		eq(t, exp, rew.Header().Get(HeadType))
+	}
+
+	test(TypeJson+`; charset=utf-8`, JsonOk(nil))
+	test(TypeJson+`; charset=iso-8859-1`, Json{Charset: `iso-8859-1`})
+	test(TypeXml+`; charset=utf-16`, Xml{Charset: `utf-16`})
+	test(TypeJson, Json{Header: http.Header{HeadType: {TypeJson}}})
+
+	test(`text/plain; charset=utf-8`, StringOk(`hello`))
+	test(`text/csv; charset=utf-8`, String{Header: http.Header{HeadType: {`text/csv`}}})
+	test(`text/csv; charset=ascii`, String{Header: http.Header{HeadType: {`text/csv`}}, Charset: `ascii`})
+	test(`text/csv; charset=ascii`, String{Header: http.Header{HeadType: {`text/csv; charset=ascii`}}})
+	test(`image/svg+xml`, String{Header: http.Header{HeadType: {`image/svg+xml`}}})
+
+	DefaultCharset = ``
+	t.Cleanup(func() { DefaultCharset = `utf-8` })
+
+	test(TypeJson, JsonOk(nil))
+	test(`text/csv`, String{Header: http.Header{HeadType: {`text/csv`}}})
+	test(TypeJson+`; charset=ascii`, Json{Charset: `ascii`})
+}
+
 func TestJson_TryBytes_nil_head(t *testing.T) {
 	res := Json{
 		Status:  201,
@@ -154,7 +480,7 @@ func TestJson_TryBytes_nil_head(t *testing.T) {
 	}.TryBytes()
 
 	headExp := http.Header{}
-	headExp.Set(`content-type`, TypeJson)
+	headExp.Set(`content-type`, typeJsonUtf8)
 
 	eq(t, 201, res.Status)
 	eq(t, headExp, res.Header)
@@ -171,7 +497,7 @@ func TestJson_TryBytes_non_nil_head(t *testing.T) {
 	}.TryBytes()
 
 	headExp := headSrc.Clone()
-	headExp.Set(`content-type`, TypeJson)
+	headExp.Set(`content-type`, typeJsonUtf8)
 
 	eq(t, 201, res.Status)
 	eq(t, headExp, res.Header)
@@ -200,11 +526,45 @@ func TestJson_TryBytes_indent(t *testing.T) {
 	}.TryBytes().Body))
 }
 
+func TestJson_ServeHTTP_pretty(t *testing.T) {
+	test := func(han Json, path, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, ht.NewRequest(http.MethodGet, path, nil))
+		eq(t, exp, strings.TrimSpace(rew.Body.String()))
+	}
+
+	compact := string(Json{Body: jsonIndentSrc}.TryBytes().Body)
+
+	han := Json{Body: jsonIndentSrc, Pretty: PrettyQuery}
+	test(han, `/`, compact)
+	test(han, `/?pretty`, jsonIndentOut)
+	test(han, `/?pretty=1`, jsonIndentOut)
+	test(han, `/?pretty=true`, jsonIndentOut)
+	test(han, `/?pretty=0`, compact)
+	test(han, `/?pretty=other`, compact)
+	test(Json{Body: jsonIndentSrc}, `/?pretty`, compact)
+
+	t.Run(`default`, func(t *testing.T) {
+		prev := DefaultPretty
+		DefaultPretty = PrettyQuery
+		t.Cleanup(func() { DefaultPretty = prev })
+
+		test(Json{Body: jsonIndentSrc}, `/?pretty`, jsonIndentOut)
+		test(Json{Body: jsonIndentSrc, Pretty: func(*http.Request) bool { return false }}, `/?pretty`, compact)
+		test(Json{Body: jsonIndentSrc, Indent: "\t"}, `/?pretty`, strings.ReplaceAll(jsonIndentOut, `  `, "\t"))
+
+		rew := ht.NewRecorder()
+		Xml{Body: xmlIndentSrc}.ServeHTTP(rew, ht.NewRequest(http.MethodGet, `/?pretty`, nil))
+		eq(t, xmlIndentOut, strings.TrimSpace(rew.Body.String()))
+	})
+}
+
 func TestXml(t *testing.T) {
 	rew := ht.NewRecorder()
 
 	headExp := headExp.Clone()
-	headExp.Set(`content-type`, `application/xml`)
+	headExp.Set(`content-type`, typeXmlUtf8)
 
 	Xml{Status: 201, Header: headSrc, Body: XmlVal{xml.Name{Local: `tag`}, `hello world`}}.ServeHTTP(rew, nil)
 
@@ -221,7 +581,7 @@ func TestXml_TryBytes_nil_head(t *testing.T) {
 	}.TryBytes()
 
 	headExp := http.Header{}
-	headExp.Set(`content-type`, `application/xml`)
+	headExp.Set(`content-type`, typeXmlUtf8)
 
 	eq(t, 201, res.Status)
 	eq(t, headExp, res.Header)
@@ -238,7 +598,7 @@ func TestXml_TryBytes_non_nil_head(t *testing.T) {
 	}.TryBytes()
 
 	headExp := headSrc.Clone()
-	headExp.Set(`content-type`, `application/xml`)
+	headExp.Set(`content-type`, typeXmlUtf8)
 
 	eq(t, 201, res.Status)
 	eq(t, headExp, res.Header)
@@ -279,6 +639,95 @@ func TestRedirect(t *testing.T) {
 	eq(t, ``, rew.Body.String())
 }
 
+func TestRedirect_Location(t *testing.T) {
+	req := ht.NewRequest(http.MethodGet, `/one?two=three&four=five`, nil)
+
+	eq(t, `/six?seven`, Redirect{Link: `/six?seven`}.Location(req))
+	eq(t, `/six?four=five&two=three`, Redirect{Link: `/six`, KeepQuery: true}.Location(req))
+	eq(t, `/six?four=seven&two=three`, Redirect{Link: `/six?four=seven`, KeepQuery: true}.Location(req))
+	eq(t, `/six?next=%2Fone`, Redirect{Link: `/six`, Query: url.Values{`next`: {`/one`}}}.Location(req))
+	eq(t, `https://example.com/six?two=eight#nine`, Redirect{
+		Link:  `https://example.com/six?two=seven#nine`,
+		Query: url.Values{`two`: {`eight`}},
+	}.Location(req))
+
+	eq(t, `/six?four=five&next=%2Fone&two=three`, Redirect{
+		Link:      `/six`,
+		KeepQuery: true,
+		Query:     url.Values{`next`: {`/one`}},
+	}.Location(req))
+
+	rew := ht.NewRecorder()
+	Redirect{Status: http.StatusFound, Link: `/six`, KeepQuery: true}.ServeHTTP(rew, req)
+	eq(t, `/six?four=five&two=three`, rew.Header().Get(`Location`))
+}
+
+func TestRedirect_HtmlBody(t *testing.T) {
+	rew := ht.NewRecorder()
+	req := ht.NewRequest(http.MethodPost, `/one/two`, nil)
+	Redirect{Status: http.StatusMultipleChoices, Link: `three?four=five&six`, HtmlBody: true}.ServeHTTP(rew, req)
+
+	eq(t, http.StatusMultipleChoices, rew.Code)
+	eq(t, `/one/three?four=five&six`, rew.Header().Get(`Location`))
+	eq(t, `text/html; charset=utf-8`, rew.Header().Get(HeadType))
+	eq(
+		t,
+		`<!doctype html><meta http-equiv="refresh" content="0; url=/one/three?four=five&amp;six"><a href="/one/three?four=five&amp;six">/one/three?four=five&amp;six</a>`+"\n",
+		rew.Body.String(),
+	)
+}
+
+func TestRedirect_invalid_status(t *testing.T) {
+	for _, status := range []int{0, 200, 404} {
+		rew := ht.NewRecorder()
+		Redirect{Status: status, Link: `/one`}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusInternalServerError, rew.Code)
+		eq(t, ``, rew.Header().Get(`Location`))
+		eq(t, fmt.Sprintf(`[goh] invalid redirect status %v`, status), rew.Body.String())
+	}
+}
+
+func TestRedirectTemporary(t *testing.T) {
+	eq(t, Redirect{Status: http.StatusTemporaryRedirect, Link: `/one`}, RedirectTemporary(`/one`))
+
+	rew := ht.NewRecorder()
+	RedirectTemporary(`/one`).ServeHTTP(rew, ht.NewRequest(http.MethodPost, `/`, nil))
+
+	eq(t, http.StatusTemporaryRedirect, rew.Code)
+	eq(t, `/one`, rew.Header().Get(`Location`))
+}
+
+func TestRedirectPermanent(t *testing.T) {
+	eq(t, Redirect{Status: http.StatusPermanentRedirect, Link: `/one`}, RedirectPermanent(`/one`))
+}
+
+func TestSeeOther(t *testing.T) {
+	eq(t, Redirect{Status: http.StatusSeeOther, Link: `/one`}, SeeOther(`/one`))
+
+	rew := ht.NewRecorder()
+	SeeOther(`/one`).ServeHTTP(rew, ht.NewRequest(http.MethodPost, `/`, nil))
+
+	eq(t, http.StatusSeeOther, rew.Code)
+	eq(t, `/one`, rew.Header().Get(`Location`))
+}
+
+func TestSeeOtherFlash(t *testing.T) {
+	eq(t, SeeOther(`/one`), SeeOtherFlash(`/one`))
+	eq(t, SeeOther(`/one`), SeeOtherFlash(`/one`, nil, &http.Cookie{}))
+
+	rew := ht.NewRecorder()
+	SeeOtherFlash(
+		`/one`,
+		&http.Cookie{Name: `flash`, Value: `saved`, Path: `/`},
+		&http.Cookie{Name: `two`, Value: `three`},
+	).ServeHTTP(rew, ht.NewRequest(http.MethodPost, `/`, nil))
+
+	eq(t, http.StatusSeeOther, rew.Code)
+	eq(t, `/one`, rew.Header().Get(`Location`))
+	eq(t, []string{`flash=saved; Path=/`, `two=three`}, rew.Header().Values(`Set-Cookie`))
+}
+
 func TestXmlDoc(t *testing.T) {
 	bytes, err := xml.Marshal(XmlDoc{
 		Encoding: "utf-8",
@@ -289,6 +738,42 @@ func TestXmlDoc(t *testing.T) {
 	eq(t, `<?xml version="1.0" encoding="utf-8"?><string>text</string>`, string(bytes))
 }
 
+func TestXmlDoc_prolog(t *testing.T) {
+	bytes, err := xml.Marshal(XmlDoc{
+		Stylesheets: []XmlStylesheet{{Href: `/feed.xsl`}, {Href: `/feed.css`, Type: `text/css`}},
+		Doctype:     `html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd"`,
+		Val:         `text`,
+	})
+	try(err)
+
+	eq(
+		t,
+		`<?xml version="1.0"?>`+
+			`<?xml-stylesheet type="text/xsl" href="/feed.xsl"?>`+
+			`<?xml-stylesheet type="text/css" href="/feed.css"?>`+
+			`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd">`+
+			`<string>text</string>`,
+		string(bytes),
+	)
+}
+
+func TestXmlDoc_Standalone(t *testing.T) {
+	test := func(exp string, doc XmlDoc) {
+		t.Helper()
+		doc.Val = `text`
+		bytes, err := xml.Marshal(doc)
+		try(err)
+		eq(t, exp+`<string>text</string>`, string(bytes))
+	}
+
+	yes, no := true, false
+
+	test(`<?xml version="1.0"?>`, XmlDoc{})
+	test(`<?xml version="1.1"?>`, XmlDoc{Version: `1.1`})
+	test(`<?xml version="1.0" standalone="yes"?>`, XmlDoc{Standalone: &yes})
+	test(`<?xml version="1.0" encoding="utf-8" standalone="no"?>`, XmlDoc{Encoding: `utf-8`, Standalone: &no})
+}
+
 func TestFile(t *testing.T) {
 	t.Run(`missing`, func(t *testing.T) {
 		testFile404(t, File{Path: `0589a8bfe3854d499c5e3beef89660c1`})
@@ -315,6 +800,274 @@ func TestFile(t *testing.T) {
 	})
 }
 
+func TestFile_NotFound(t *testing.T) {
+	file := File{
+		Path:     `1ea0a2ed0f8e4bd2a5d4f0e2d3f2b8c1`,
+		NotFound: StringWith(http.StatusNotFound, `custom`),
+	}
+	eq(t, nil, file.HanOpt(nil))
+
+	rew := ht.NewRecorder()
+	file.ServeHTTP(rew, nil)
+
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, `custom`, rew.Body.String())
+}
+
+func TestFile_MaxSize(t *testing.T) {
+	size := int64(len(readFile(`readme.md`)))
+
+	testFileOk(t, File{Path: `readme.md`, MaxSize: size}, Head{Status: 200})
+	testFile404(t, File{Path: `readme.md`, MaxSize: size - 1})
+
+	eq(t, File{Path: `readme.md`, MaxSize: size}, Dir{Path: `.`, MaxSize: size}.HanOpt(pathReq(`/readme.md`)))
+	testDir404(t, Dir{Path: `.`, MaxSize: size - 1}, pathReq(`/readme.md`))
+}
+
+func TestFile_Type(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `one.css`), `body {}`)
+	writeFile(filepath.Join(root, `two`), `<!doctype html><p>two</p>`)
+
+	eq(t, ``, File{Path: filepath.Join(root, `one.css`)}.Type())
+	eq(t, `text/css; charset=utf-8`, File{Path: filepath.Join(root, `one.css`), DetectType: true}.Type())
+	eq(t, `text/html; charset=utf-8`, File{Path: filepath.Join(root, `two`), DetectType: true}.Type())
+	eq(t, `text/plain`, File{Path: filepath.Join(root, `two`), DetectType: true, ContentType: `text/plain`}.Type())
+
+	t.Run(`serve with status`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		File{Status: 201, Path: filepath.Join(root, `one.css`), DetectType: true}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, 201, rew.Code)
+		eq(t, `text/css; charset=utf-8`, rew.Result().Header.Get(HeadType))
+	})
+
+	t.Run(`header priority`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		File{
+			Status:      201,
+			Header:      http.Header{HeadType: {`text/one`}},
+			Path:        filepath.Join(root, `one.css`),
+			ContentType: `text/two`,
+		}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, `text/one`, rew.Result().Header.Get(HeadType))
+	})
+
+	t.Run(`dir`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Dir{Status: 201, Path: root, DetectType: true}.ServeHTTP(rew, pathReq(`/one.css`))
+		eq(t, 201, rew.Code)
+		eq(t, `text/css; charset=utf-8`, rew.Result().Header.Get(HeadType))
+	})
+
+	t.Run(`default detection`, func(t *testing.T) {
+		get := func(han http.Handler, path string) *http.Response {
+			srv := ht.NewServer(han)
+			defer srv.Close()
+
+			res, err := http.Get(srv.URL + path)
+			try(err)
+			res.Body.Close()
+			eq(t, http.StatusOK, res.StatusCode)
+			return res
+		}
+
+		eq(t, `text/css; charset=utf-8`, get(Dir{Path: root}, `/one.css`).Header.Get(HeadType))
+		eq(t, `text/html; charset=utf-8`, get(Dir{Path: root}, `/two`).Header.Get(HeadType))
+
+		dir := Dir{Path: root, Header: http.Header{HeadType: nil}}
+		eq(t, []string(nil), get(dir, `/one.css`).Header.Values(HeadType))
+
+		rew := ht.NewRecorder()
+		rew.Header().Set(HeadType, `text/three`)
+		File{Path: filepath.Join(root, `one.css`)}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, `text/three`, rew.Result().Header.Get(HeadType))
+	})
+}
+
+func TestFile_Conditional(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `one.txt`)
+	writeFile(path, `one`)
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 600, time.UTC)
+	try(os.Chtimes(path, mtime, mtime))
+
+	stat, err := os.Stat(path)
+	try(err)
+	etag := FileEtag(stat)
+	eq(t, `W/"3-`+strconv.FormatInt(mtime.UnixNano(), 16)+`"`, etag)
+
+	file := File{Status: 201, Path: path, Conditional: true}
+
+	test := func(key, val string, code int, body string) {
+		t.Helper()
+		req := ht.NewRequest(http.MethodGet, `/`, nil)
+		if key != `` {
+			req.Header.Set(key, val)
+		}
+
+		rew := ht.NewRecorder()
+		file.ServeHTTP(rew, req)
+
+		eq(t, code, rew.Code)
+		eq(t, body, rew.Body.String())
+		eq(t, etag, rew.Result().Header.Get(`Etag`))
+		eq(t, `Thu, 02 Jan 2020 03:04:05 GMT`, rew.Result().Header.Get(`Last-Modified`))
+	}
+
+	test(``, ``, 201, `one`)
+	test(`If-None-Match`, etag, http.StatusNotModified, ``)
+	test(`If-None-Match`, `"other", `+strings.TrimPrefix(etag, `W/`), http.StatusNotModified, ``)
+	test(`If-None-Match`, `*`, http.StatusNotModified, ``)
+	test(`If-None-Match`, `"other"`, 201, `one`)
+	test(`If-Modified-Since`, `Thu, 02 Jan 2020 03:04:05 GMT`, http.StatusNotModified, ``)
+	test(`If-Modified-Since`, `Thu, 02 Jan 2020 03:04:04 GMT`, 201, `one`)
+
+	// The custom status is already sent when `http.ServeContent` runs.
+	test(`If-Match`, `"other"`, 201, `one`)
+	test(`If-Unmodified-Since`, `Thu, 02 Jan 2019 03:04:05 GMT`, 201, `one`)
+
+	t.Run(`disabled`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		File{Status: 201, Path: path}.ServeHTTP(rew, ht.NewRequest(http.MethodGet, `/`, nil))
+		eq(t, ``, rew.Result().Header.Get(`Etag`))
+
+		// Dates are still handled by `http.ServeContent`.
+		serve := func(file File, date string) *ht.ResponseRecorder {
+			req := ht.NewRequest(http.MethodGet, `/`, nil)
+			req.Header.Set(`If-Modified-Since`, date)
+			rew := ht.NewRecorder()
+			file.ServeHTTP(rew, req)
+			return rew
+		}
+
+		rew = serve(File{Path: path}, `Thu, 02 Jan 2020 03:04:04 GMT`)
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `one`, rew.Body.String())
+		eq(t, `Thu, 02 Jan 2020 03:04:05 GMT`, rew.Header().Get(`Last-Modified`))
+
+		rew = serve(File{Path: path}, `Thu, 02 Jan 2020 03:04:05 GMT`)
+		eq(t, http.StatusNotModified, rew.Code)
+		eq(t, ``, rew.Body.String())
+
+		rew = serve(File{Status: 201, Path: path}, `Thu, 02 Jan 2030 03:04:05 GMT`)
+		eq(t, 201, rew.Code)
+		eq(t, `one`, rew.Body.String())
+	})
+}
+
+func TestFile_ranges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), `one.txt`)
+	writeFile(path, `0123456789`)
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	try(os.Chtimes(path, mtime, mtime))
+
+	serve := func(file File, head http.Header) *ht.ResponseRecorder {
+		t.Helper()
+		req := ht.NewRequest(http.MethodGet, `/`, nil)
+		req.Header = head
+		rew := ht.NewRecorder()
+		file.ServeHTTP(rew, req)
+		return rew
+	}
+
+	test := func(file File, head http.Header, code int, body string) {
+		t.Helper()
+		rew := serve(file, head)
+		eq(t, code, rew.Code)
+		eq(t, body, rew.Body.String())
+	}
+
+	file := File{Path: path, Conditional: true}
+	date := `Thu, 02 Jan 2020 03:04:05 GMT`
+
+	t.Run(`range`, func(t *testing.T) {
+		rew := serve(file, http.Header{`Range`: {`bytes=2-4`}})
+		eq(t, http.StatusPartialContent, rew.Code)
+		eq(t, `234`, rew.Body.String())
+		eq(t, `bytes 2-4/10`, rew.Header().Get(`Content-Range`))
+		eq(t, `bytes`, rew.Header().Get(`Accept-Ranges`))
+
+		test(file, http.Header{`Range`: {`bytes=-3`}}, http.StatusPartialContent, `789`)
+		test(file, http.Header{`Range`: {`bytes=20-`}}, http.StatusRequestedRangeNotSatisfiable, "invalid range: failed to overlap\n")
+
+		rew = serve(file, http.Header{`Range`: {`bytes=0-1,8-9`}})
+		eq(t, http.StatusPartialContent, rew.Code)
+		eq(t, true, strings.HasPrefix(rew.Header().Get(HeadType), `multipart/byteranges`))
+	})
+
+	t.Run(`If-Range`, func(t *testing.T) {
+		test(file, http.Header{`Range`: {`bytes=2-4`}, `If-Range`: {date}}, http.StatusPartialContent, `234`)
+		test(file, http.Header{`Range`: {`bytes=2-4`}, `If-Range`: {`Thu, 02 Jan 2020 03:04:06 GMT`}}, http.StatusOK, `0123456789`)
+
+		// Weak entity tags never match "If-Range".
+		etag := serve(file, http.Header{}).Header().Get(`Etag`)
+		test(file, http.Header{`Range`: {`bytes=2-4`}, `If-Range`: {etag}}, http.StatusOK, `0123456789`)
+
+		// Strong entity tags set by the user are compared as usual.
+		strong := File{Path: path, Header: http.Header{`Etag`: {`"one"`}}}
+		test(strong, http.Header{`Range`: {`bytes=2-4`}, `If-Range`: {`"one"`}}, http.StatusPartialContent, `234`)
+		test(strong, http.Header{`Range`: {`bytes=2-4`}, `If-Range`: {`"two"`}}, http.StatusOK, `0123456789`)
+	})
+
+	t.Run(`NoRanges`, func(t *testing.T) {
+		file := File{Path: path, NoRanges: true}
+
+		rew := serve(file, http.Header{`Range`: {`bytes=2-4`}, `If-Range`: {date}})
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `0123456789`, rew.Body.String())
+		eq(t, `none`, rew.Header().Get(`Accept-Ranges`))
+		eq(t, ``, rew.Header().Get(`Content-Range`))
+
+		rew = serve(Dir{Path: filepath.Dir(path), NoRanges: true}.File(path), http.Header{`Range`: {`bytes=2-4`}})
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `none`, rew.Header().Get(`Accept-Ranges`))
+	})
+
+	t.Run(`custom status`, func(t *testing.T) {
+		test(File{Status: http.StatusCreated, Path: path}, http.Header{`Range`: {`bytes=2-4`}}, http.StatusCreated, `0123456789`)
+
+		srv := ht.NewServer(File{Status: http.StatusCreated, Path: path})
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		try(err)
+		req.Header.Set(`Range`, `bytes=2-4`)
+
+		res, err := srv.Client().Do(req)
+		try(err)
+		defer res.Body.Close()
+		eq(t, http.StatusCreated, res.StatusCode)
+		eq(t, ``, res.Header.Get(`Content-Range`))
+	})
+}
+
+func TestFile_Disposition(t *testing.T) {
+	eq(t, ``, File{Path: `readme.md`}.ContentDisposition())
+	eq(t, `inline; filename="readme.md"`, File{Path: `readme.md`, Disposition: DispositionInline}.ContentDisposition())
+	eq(t, `attachment; filename="one.md"`, File{
+		Path:            `readme.md`,
+		Disposition:     DispositionAttachment,
+		DispositionName: `one.md`,
+	}.ContentDisposition())
+
+	rew := ht.NewRecorder()
+	File{Status: 201, Path: `readme.md`, Disposition: DispositionAttachment}.ServeHTTP(rew, pathReq(`/`))
+	eq(t, `attachment; filename="readme.md"`, rew.Result().Header.Get(`Content-Disposition`))
+
+	rew = ht.NewRecorder()
+	File{Path: `4c2b4e7c2a8e4f7b9f0f6f1e2d3c4b5a`, Disposition: DispositionAttachment}.ServeHTTP(rew, pathReq(`/`))
+	eq(t, ``, rew.Result().Header.Get(`Content-Disposition`))
+}
+
+func TestFile_no_redirect(t *testing.T) {
+	rew := ht.NewRecorder()
+	File{Path: `readme.md`}.ServeHTTP(rew, pathReq(`/index.html`))
+
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, readFile(`readme.md`), rew.Body.Bytes())
+}
+
 func testFile404(t testing.TB, file File) {
 	eq(t, nil, file.HanOpt(nil))
 	eq(t, file, file.Han(nil))
@@ -395,6 +1148,383 @@ func TestDir(t *testing.T) {
 	})
 }
 
+func TestDir_Index(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `index.html`), `root index`)
+	writeFile(filepath.Join(root, `one/index.html`), `one index`)
+	writeFile(filepath.Join(root, `one/main.html`), `one main`)
+
+	test := func(dir Dir, path, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		dir.ServeHTTP(rew, pathReq(path))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, exp, rew.Body.String())
+	}
+
+	dir := Dir{Path: root}
+	test(dir, `/`, `root index`)
+	test(dir, `/one/`, `one index`)
+	test(dir, `/one`, `one index`)
+	test(dir, `/one/main.html`, `one main`)
+
+	dir = Dir{Path: root, Index: `main.html`}
+	test(dir, `/one`, `one main`)
+	testDir404(t, dir, pathReq(`/`))
+
+	filter := FilterFunc(func(path string) bool { return !strings.HasSuffix(path, `/index.html`) })
+	testDir404(t, Dir{Path: root, Filter: filter}, pathReq(`/one/`))
+}
+
+func TestDir_Prefix(t *testing.T) {
+	dir := Dir{Path: `.`, Prefix: `/static`}
+
+	testDirOk(t, dir, pathReq(`/static/readme.md`), `readme.md`)
+	testDir404(t, dir, pathReq(`/readme.md`))
+	testDir404(t, dir, pathReq(`/staticreadme.md`))
+	testDir404(t, dir, pathReq(`/other/readme.md`))
+
+	dir = Dir{Path: `.`, Prefix: `/static/`}
+	testDirOk(t, dir, pathReq(`/static/readme.md`), `readme.md`)
+	testDir404(t, dir, pathReq(`/static`))
+}
+
+func Test_stripPrefix(t *testing.T) {
+	test := func(path, prefix, exp string, expOk bool) {
+		t.Helper()
+		out, ok := stripPrefix(path, prefix)
+		eq(t, exp, out)
+		eq(t, expOk, ok)
+	}
+
+	test(`/one`, ``, `/one`, true)
+	test(`/one`, `/one`, ``, true)
+	test(`/one/two`, `/one`, `/two`, true)
+	test(`/one/two`, `/one/`, `two`, true)
+	test(`/onetwo`, `/one`, ``, false)
+	test(`/two`, `/one`, ``, false)
+	test(`/one`, `/one/`, ``, false)
+}
+
+func TestDir_Rewrite(t *testing.T) {
+	t.Run(`map`, func(t *testing.T) {
+		dir := Dir{Path: `.`, Prefix: `/static/`, Rewrite: Rewrites{`/license`: `/unlicense`}}
+		testDirOk(t, dir, pathReq(`/static/license`), `unlicense`)
+		testDirOk(t, dir, pathReq(`/static/readme.md`), `readme.md`)
+		testDir404(t, dir, pathReq(`/license`))
+	})
+
+	t.Run(`func`, func(t *testing.T) {
+		dir := Dir{Path: `.`, Rewrite: RewriteFunc(strings.ToLower)}
+		testDirOk(t, dir, pathReq(`/README.md`), `readme.md`)
+	})
+
+	t.Run(`no traversal`, func(t *testing.T) {
+		dir := Dir{Path: `.`, Rewrite: Rewrites{`/one`: `/../module/readme.md`}}
+		testDir404(t, dir, pathReq(`/one`))
+	})
+}
+
+func TestDir_NotFound(t *testing.T) {
+	notFound := StringWith(http.StatusNotFound, `custom`)
+	dir := Dir{Path: `.`, NotFound: notFound}
+	req := pathReq(`/2b3ccf0d5a2c4b1fa1d1b7e0c61b2a9f`)
+
+	eq(t, nil, dir.HanOpt(req))
+	eq(t, notFound, dir.Han(req))
+	eq(t, notFound, dir.Resolve(req).NotFound)
+
+	rew := ht.NewRecorder()
+	eq(t, false, dir.ServedHTTP(rew, req))
+	dir.ServeHTTP(rew, req)
+
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, `custom`, rew.Body.String())
+}
+
+func TestDir_dotfiles(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `.env`), `secret`)
+	writeFile(filepath.Join(root, `.well-known/one.txt`), `one`)
+	writeFile(filepath.Join(root, `two/.three.txt`), `three`)
+
+	dir := Dir{Path: root}
+	testDir404(t, dir, pathReq(`/.env`))
+	testDir404(t, dir, pathReq(`/.well-known/one.txt`))
+	testDir404(t, dir, pathReq(`/two/.three.txt`))
+
+	dir.AllowDotfiles = true
+	testDirOk(t, dir, pathReq(`/.env`), filepath.Join(root, `.env`))
+	testDirOk(t, dir, pathReq(`/.well-known/one.txt`), filepath.Join(root, `.well-known/one.txt`))
+	testDirOk(t, dir, pathReq(`/two/.three.txt`), filepath.Join(root, `two/.three.txt`))
+}
+
+func TestDir_symlinks(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFile(filepath.Join(root, `public/one.txt`), `one`)
+	writeFile(filepath.Join(outside, `secret.txt`), `secret`)
+	try(os.Symlink(filepath.Join(root, `public/one.txt`), filepath.Join(root, `public/two.txt`)))
+	try(os.Symlink(filepath.Join(outside, `secret.txt`), filepath.Join(root, `public/three.txt`)))
+	try(os.Symlink(outside, filepath.Join(root, `public/four`)))
+
+	link := filepath.Join(root, `link`)
+	try(os.Symlink(filepath.Join(root, `public`), link))
+
+	dir := Dir{Path: link}
+	testDirOk(t, dir, pathReq(`/one.txt`), filepath.Join(link, `one.txt`))
+	testDir404(t, dir, pathReq(`/two.txt`))
+	testDir404(t, dir, pathReq(`/three.txt`))
+	testDir404(t, dir, pathReq(`/four/secret.txt`))
+
+	dir.FollowSymlinks = true
+	testDirOk(t, dir, pathReq(`/one.txt`), filepath.Join(link, `one.txt`))
+	testDirOk(t, dir, pathReq(`/two.txt`), filepath.Join(link, `two.txt`))
+	testDir404(t, dir, pathReq(`/three.txt`))
+	testDir404(t, dir, pathReq(`/four/secret.txt`))
+}
+
+func TestDir_symlinks_list(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFile(filepath.Join(root, `one.txt`), `one`)
+	writeFile(filepath.Join(outside, `secret.txt`), `secret`)
+	try(os.Symlink(filepath.Join(root, `one.txt`), filepath.Join(root, `two.txt`)))
+	try(os.Symlink(filepath.Join(outside, `secret.txt`), filepath.Join(root, `three.txt`)))
+
+	names := func(dir Dir) (out []string) {
+		list, ok := dir.DirList(pathReq(`/`))
+		eq(t, true, ok)
+		for _, val := range list.Entries {
+			out = append(out, val.Name)
+		}
+		return
+	}
+
+	dir := Dir{Path: root, List: true}
+	eq(t, []string{`one.txt`}, names(dir))
+
+	dir.FollowSymlinks = true
+	eq(t, []string{`one.txt`, `two.txt`}, names(dir))
+}
+
+func TestDir_invalid_paths(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `one/two.txt`), `two`)
+	writeFile(filepath.Join(root, `three..txt`), `three`)
+
+	dir := Dir{Path: root}
+	testDirOk(t, dir, pathReq(`/one//two.txt`), filepath.Join(root, `one/two.txt`))
+	testDirOk(t, dir, pathReq(`/one/./two.txt`), filepath.Join(root, `one/two.txt`))
+	testDirOk(t, dir, pathReq(`/three..txt`), filepath.Join(root, `three..txt`))
+	testDir404(t, dir, pathReq(`/one/../one/two.txt`))
+	testDir404(t, dir, pathReq("/one/two.txt\x00"))
+	testDir404(t, dir, pathReq("/one/\ntwo.txt"))
+	testDir404(t, dir, pathReq("/one/two.txt\xff"))
+}
+
+func Test_cleanReqPath(t *testing.T) {
+	test := func(src, exp string, expOk bool) {
+		t.Helper()
+		out, ok := cleanReqPath(src)
+		eq(t, expOk, ok)
+		eq(t, exp, out)
+	}
+
+	test(``, ``, true)
+	test(`one`, `one`, true)
+	test(`one/`, `one/`, true)
+	test(`one//two/`, `one/two/`, true)
+	test(`./one/./two`, `one/two`, true)
+	test(`./`, ``, true)
+	test(`one..two`, `one..two`, true)
+	test(`..`, ``, false)
+	test(`one/../two`, ``, false)
+	test(`one/..`, ``, false)
+	test("one\x00", ``, false)
+	test("one\x7f", ``, false)
+	test("one\xc3", ``, false)
+}
+
+func Test_validWindowsPath(t *testing.T) {
+	eq(t, true, validWindowsPath(``))
+	eq(t, true, validWindowsPath(`readme.md`))
+	eq(t, true, validWindowsPath(`one/two.txt`))
+	eq(t, true, validWindowsPath(`console.txt`))
+	eq(t, true, validWindowsPath(`com10`))
+	eq(t, true, validWindowsPath(`lpt`))
+
+	eq(t, false, validWindowsPath(`readme.md::$DATA`))
+	eq(t, false, validWindowsPath(`readme.md:stream`))
+	eq(t, false, validWindowsPath(`C:/windows`))
+	eq(t, false, validWindowsPath(`one\two.txt`))
+	eq(t, false, validWindowsPath(`readme.md.`))
+	eq(t, false, validWindowsPath(`readme.md `))
+	eq(t, false, validWindowsPath(`one./two.txt`))
+	eq(t, false, validWindowsPath(`one /two.txt`))
+	eq(t, false, validWindowsPath(`one?.txt`))
+	eq(t, false, validWindowsPath(`CON`))
+	eq(t, false, validWindowsPath(`con`))
+	eq(t, false, validWindowsPath(`one/nul.txt`))
+	eq(t, false, validWindowsPath(`aux .txt`))
+	eq(t, false, validWindowsPath(`COM1`))
+	eq(t, false, validWindowsPath(`lpt9.log`))
+	eq(t, false, validWindowsPath(`com¹`))
+	eq(t, false, validWindowsPath(`CONIN$`))
+}
+
+func TestDir_UseRoot(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `one.txt`), `one`)
+
+	dir := Dir{Path: root, UseRoot: true}
+	file := dir.Resolve(pathReq(`/one.txt`))
+	eq(t, root, file.root)
+	eq(t, true, file.Exists())
+
+	rew := ht.NewRecorder()
+	dir.ServeHTTP(rew, pathReq(`/one.txt`))
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, `one`, rew.Body.String())
+
+	testDir404(t, dir, pathReq(`/two.txt`))
+}
+
+func Test_hasDotSegment(t *testing.T) {
+	eq(t, false, hasDotSegment(``))
+	eq(t, false, hasDotSegment(`one/two.txt`))
+	eq(t, false, hasDotSegment(`one./two`))
+	eq(t, true, hasDotSegment(`.one`))
+	eq(t, true, hasDotSegment(`one/.two`))
+	eq(t, true, hasDotSegment(`one/.two/three`))
+}
+
+func TestDir_OnFile(t *testing.T) {
+	var paths []string
+
+	header := http.Header{`One`: {`two`}}
+	dir := Dir{
+		Path:   `.`,
+		Header: header,
+		OnFile: func(file *File, req *http.Request) {
+			paths = append(paths, req.URL.Path)
+			file.Header.Set(`Three`, `four`)
+		},
+	}
+
+	rew := ht.NewRecorder()
+	dir.ServeHTTP(rew, pathReq(`/readme.md`))
+	dir.ServeHTTP(ht.NewRecorder(), pathReq(`/2a1b8a3e0d6e4b0f9d93b2c0e0bd4bde`))
+
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, `two`, rew.Header().Get(`One`))
+	eq(t, `four`, rew.Header().Get(`Three`))
+	eq(t, []string{`/readme.md`}, paths)
+	eq(t, http.Header{`One`: {`two`}}, header)
+
+	paths = nil
+	dir.Header = nil
+	file, ok := dir.ResolveExisting(pathReq(`/readme.md`))
+	eq(t, true, ok)
+	eq(t, http.Header{`Three`: {`four`}}, file.Header)
+
+	dir.OnFile = func(file *File, _ *http.Request) { file.Path = `unlicense` }
+	rew = ht.NewRecorder()
+	dir.ServeHTTP(rew, pathReq(`/readme.md`))
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, string(readFile(`unlicense`)), rew.Body.String())
+
+	dir.OnFile = func(file *File, _ *http.Request) { file.Path = `2a1b8a3e0d6e4b0f9d93b2c0e0bd4bde` }
+	rew = ht.NewRecorder()
+	dir.ServeHTTP(rew, pathReq(`/readme.md`))
+	eq(t, http.StatusNotFound, rew.Code)
+}
+
+func TestDir_CacheControl(t *testing.T) {
+	header := http.Header{`One`: {`two`}}
+	dir := Dir{
+		Path:         `.`,
+		Header:       header,
+		CacheControl: map[string]string{`.md`: `no-cache`},
+	}
+
+	file, ok := dir.ResolveExisting(pathReq(`/readme.md`))
+	eq(t, true, ok)
+	eq(t, http.Header{`One`: {`two`}, `Cache-Control`: {`no-cache`}}, file.Header)
+	eq(t, http.Header{`One`: {`two`}}, header)
+
+	file, ok = dir.ResolveExisting(pathReq(`/unlicense`))
+	eq(t, true, ok)
+	eq(t, header, file.Header)
+
+	dir.OnFile = func(file *File, _ *http.Request) { file.Header.Del(`Cache-Control`) }
+	file, ok = dir.ResolveExisting(pathReq(`/readme.md`))
+	eq(t, true, ok)
+	eq(t, header, file.Header)
+
+	rew := ht.NewRecorder()
+	Dir{Path: `.`, CacheControl: map[string]string{`.md`: `no-cache`}}.ServeHTTP(rew, pathReq(`/readme.md`))
+	eq(t, `no-cache`, rew.Header().Get(`Cache-Control`))
+}
+
+func TestDir_Fallback(t *testing.T) {
+	dir := Dir{Path: `.`, Fallback: File{Path: `unlicense`}}
+
+	t.Run(`exists`, func(t *testing.T) {
+		eq(t, File{Path: `readme.md`}, dir.HanOpt(pathReq(`readme.md`)))
+
+		rew := ht.NewRecorder()
+		dir.ServeHTTP(rew, pathReq(`readme.md`))
+		eq(t, readFile(`readme.md`), rew.Body.Bytes())
+	})
+
+	t.Run(`missing`, func(t *testing.T) {
+		req := pathReq(`some/route`)
+		eq(t, File{Path: `unlicense`}, dir.HanOpt(req))
+		eq(t, File{Path: `unlicense`}, dir.Han(req))
+
+		rew := ht.NewRecorder()
+		eq(t, true, dir.ServedHTTP(rew, req))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, readFile(`unlicense`), rew.Body.Bytes())
+	})
+
+	t.Run(`fallback missing`, func(t *testing.T) {
+		dir := Dir{Path: `.`, Fallback: File{Path: `5d5e0ed1c3b14a2e8ed4b7f0f6f0a0e2`}}
+
+		rew := ht.NewRecorder()
+		eq(t, false, dir.ServedHTTP(rew, pathReq(`some/route`)))
+		dir.ServeHTTP(rew, pathReq(`some/route`))
+		eq(t, http.StatusNotFound, rew.Code)
+	})
+}
+
+func TestDirs(t *testing.T) {
+	one := t.TempDir()
+	two := t.TempDir()
+	writeFile(filepath.Join(one, `one.txt`), `one from one`)
+	writeFile(filepath.Join(two, `one.txt`), `one from two`)
+	writeFile(filepath.Join(two, `two.txt`), `two from two`)
+
+	dirs := Dirs{{Path: one}, {Path: two}}
+
+	test := func(path string, code int, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		dirs.ServeHTTP(rew, pathReq(path))
+		eq(t, code, rew.Code)
+		eq(t, exp, rew.Body.String())
+	}
+
+	test(`/one.txt`, http.StatusOK, `one from one`)
+	test(`/two.txt`, http.StatusOK, `two from two`)
+	test(`/three.txt`, http.StatusNotFound, ``)
+
+	eq(t, nil, dirs.HanOpt(pathReq(`/three.txt`)))
+	eq(t, NotFound{}, dirs.Han(pathReq(`/three.txt`)))
+	eq(t, false, dirs.ServedHTTP(ht.NewRecorder(), pathReq(`/three.txt`)))
+}
+
 func testDir404(t testing.TB, dir Dir, req *http.Request) {
 	eq(t, nil, dir.HanOpt(req))
 	eq(t, NotFound{}, dir.Han(req))
@@ -462,9 +1592,120 @@ func TestAllowDirs(t *testing.T) {
 	test(true, AllowDirs{`one`, `two`}, `two/three`)
 }
 
+func TestAllowExts(t *testing.T) {
+	test := func(exp bool, exts AllowExts, path string) {
+		t.Helper()
+		eq(t, exp, exts.Allow(path))
+	}
+
+	test(false, AllowExts{}, `one.css`)
+	test(false, AllowExts{`.css`}, ``)
+	test(false, AllowExts{`.css`}, `one`)
+	test(false, AllowExts{`.css`}, `one/`)
+	test(false, AllowExts{`.css`}, `one.css/two`)
+	test(false, AllowExts{`.css`}, `one.js`)
+	test(false, AllowExts{`.css`}, `one.css.map`)
+	test(true, AllowExts{`.css`}, `one.css`)
+	test(true, AllowExts{`.css`}, `one/two.CSS`)
+	test(true, AllowExts{`.css`, `.js`}, `one/two.js`)
+}
+
+func TestRegexpFilter(t *testing.T) {
+	filter := RegexpFilter{regexp.MustCompile(`^static/`)}
+	eq(t, false, filter.Allow(``))
+	eq(t, false, filter.Allow(`other/one.css`))
+	eq(t, true, filter.Allow(`static/one.css`))
+	eq(t, false, RegexpFilter{}.Allow(`static/one.css`))
+}
+
+func TestDenyRegexp(t *testing.T) {
+	filter := DenyRegexp{regexp.MustCompile(`\.map$`)}
+	eq(t, true, filter.Allow(``))
+	eq(t, true, filter.Allow(`static/one.js`))
+	eq(t, false, filter.Allow(`static/one.js.map`))
+	eq(t, true, DenyRegexp{}.Allow(`static/one.js.map`))
+}
+
+func TestGlobFilter(t *testing.T) {
+	test := func(exp bool, filter GlobFilter, path string) {
+		t.Helper()
+		eq(t, exp, filter.Allow(path))
+	}
+
+	test(true, GlobFilter{}, `one`)
+	test(true, GlobFilter{Include: []string{`*.css`}}, `one.css`)
+	test(false, GlobFilter{Include: []string{`*.css`}}, `one/two.css`)
+	test(false, GlobFilter{Include: []string{`[`}}, `[`)
+
+	filter := GlobFilter{
+		Include: []string{`static/**`},
+		Exclude: []string{`static/**/*.map`, `**/secret`},
+	}
+
+	test(true, filter, `static`)
+	test(true, filter, `static/`)
+	test(true, filter, `static/one.js`)
+	test(true, filter, `static/one/two/three.js`)
+	test(false, filter, `other/one.js`)
+	test(false, filter, `static/one.js.map`)
+	test(false, filter, `static/one/two.js.map`)
+	test(false, filter, `static/secret`)
+	test(false, filter, `static/one/secret`)
+	test(true, filter, `static/one/secret/two`)
+}
+
 func TestHandler_error(t *testing.T) {
-	handler := Handler(func() http.Handler { panic("fail") })
-	eq(t, StringWith(http.StatusInternalServerError, `fail`), handler)
+	rew := ht.NewRecorder()
+	Handler(func() http.Handler { panic("fail") }).ServeHTTP(rew, nil)
+
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, `fail`, rew.Body.String())
+}
+
+func TestHandlerReq(t *testing.T) {
+	han := func(req *http.Request) http.Handler { return StringOk(req.URL.Path) }
+	eq(t, StringOk(`/one`), HandlerReq(pathReq(`/one`), han))
+
+	handler := HandlerReq(pathReq(`/one`), func(*http.Request) http.Handler { panic(`fail`) })
+	eq(t, `fail`, handler.(ErrPanic).Val)
+}
+
+func TestRespondReq(t *testing.T) {
+	rew := ht.NewRecorder()
+	RespondReq(rew, pathReq(`/one`), func(req *http.Request) http.Handler {
+		panic(ErrNotFound(req.URL.Path))
+	})
+
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, `/one`, rew.Body.String())
+}
+
+func TestRespondErr(t *testing.T) {
+	test := func(fun func() (http.Handler, error), expStatus int, expBody string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		RespondErr(rew, pathReq(`/`), fun)
+		eq(t, expStatus, rew.Code)
+		eq(t, expBody, rew.Body.String())
+	}
+
+	test(func() (http.Handler, error) { return StringWith(201, `ok`), nil }, 201, `ok`)
+	test(func() (http.Handler, error) { return nil, nil }, http.StatusNotFound, ``)
+	test(func() (http.Handler, error) { return nil, fmt.Errorf(`fail`) }, http.StatusInternalServerError, `fail`)
+	test(func() (http.Handler, error) { return StringOk(`ok`), ErrConflict(`conflict`) }, http.StatusConflict, `conflict`)
+	test(func() (http.Handler, error) { panic(ErrForbidden(`forbidden`)) }, http.StatusForbidden, `forbidden`)
+}
+
+func TestTryHan(t *testing.T) {
+	han := TryHan(func(req *http.Request) http.Handler {
+		if req.URL.Path == `/fail` {
+			panic(`fail`)
+		}
+		return StringOk(req.URL.Path)
+	})
+
+	eq(t, StringOk(`/one`), han(pathReq(`/one`)))
+	eq(t, `fail`, han(pathReq(`/fail`)).(ErrPanic).Val)
 }
 
 func TestHandler_success(t *testing.T) {
@@ -501,6 +1742,11 @@ func ptr(val interface{}) uintptr {
 func pathUrl(path string) *url.URL      { return &url.URL{Path: path} }
 func pathReq(path string) *http.Request { return &http.Request{URL: pathUrl(path)} }
 
+func writeFile(path, body string) {
+	try(os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	try(os.WriteFile(path, []byte(body), os.ModePerm))
+}
+
 func readFile(path string) []byte {
 	val, err := os.ReadFile(path)
 	try(err)
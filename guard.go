@@ -0,0 +1,177 @@
+package goh
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+/*
+Error reported by `goh.SizeGuard` when a response exceeds the limit. The
+actual error wraps this one, and can be detected via `errors.Is`.
+*/
+var ErrResponseTooLarge = errors.New(`[goh] response exceeded the size limit`)
+
+/*
+HTTP handler that limits the size of the response body of `.Handler` to `.Max`
+bytes. Protects against runaway generators, or accidentally serving giant files
+through handlers such as `goh.Reader` or `goh.File`:
+
+	var han = goh.SizeGuard{Max: 64 << 20, Handler: goh.Dir{Path: `public`}}
+
+When a write would exceed the limit, it's rejected, and this and subsequent
+writes fail with an error wrapping `goh.ErrResponseTooLarge`, which usually
+makes the inner handler stop. The header is sent only with the first accepted
+write; when the inner handler declares "Content-Length" above the limit, or
+the first write alone exceeds the limit, nothing is sent at all. After the
+inner handler returns, the error is reported via `.ErrFunc`, defaulting to
+`goh.HandleErr`.
+
+If nothing was sent to the client, `.ErrFunc` may write an error response as
+usual; representation headers set by the inner handler, such as
+"Content-Length", are removed beforehand. If the header or a part of the body
+was already sent, the response is aborted by panicking with
+`http.ErrAbortHandler` after reporting the error, so that the client sees a
+broken response rather than a truncated one that looks complete.
+
+Note that the inner handler may also report the failed write via its own err
+func. When `.Max` is not positive, `.Handler` is served without a limit. When
+`.Handler` is nil, responds with `goh.NotFound`.
+*/
+type SizeGuard struct {
+	Max     int64
+	Handler http.Handler
+	ErrFunc ErrFunc
+}
+
+// Implement `http.Handler`.
+func (self SizeGuard) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.SizeGuard`, self.serveHTTP)
+}
+
+func (self SizeGuard) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	han := orNotFound(self.Handler)
+	if self.Max <= 0 {
+		han.ServeHTTP(rew, req)
+		return
+	}
+
+	writer := &sizeGuardWriter{ResponseWriter: rew, max: self.Max}
+	han.ServeHTTP(writer, req)
+	if writer.err == nil {
+		writer.send()
+		return
+	}
+
+	status := 0
+	if writer.sent {
+		status = writer.status
+	}
+	Head{ErrFunc: self.ErrFunc}.fail(rew, req, ErrInfo{writer.err, `goh.SizeGuard`, status, writer.size, writer.sent})
+
+	if writer.sent {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self SizeGuard) Han(*http.Request) http.Handler { return self }
+
+/*
+Response writer used by `goh.SizeGuard`. Delays sending the header until the
+first accepted write, which allows to report an error cleanly when even the
+first write exceeds the limit. Preserves `http.Flusher`, and supports
+`http.ResponseController` via `.Unwrap`.
+*/
+type sizeGuardWriter struct {
+	http.ResponseWriter
+	max    int64
+	size   int64
+	status int
+	sent   bool
+	err    error
+}
+
+func (self *sizeGuardWriter) WriteHeader(status int) {
+	// Informational statuses such as 103 may precede the actual status.
+	if status < http.StatusOK {
+		if !self.sent && self.err == nil {
+			self.ResponseWriter.WriteHeader(status)
+		}
+		return
+	}
+
+	if self.status != 0 {
+		return
+	}
+	self.status = status
+
+	if self.declaredSize() > self.max {
+		self.exceed()
+	}
+}
+
+func (self *sizeGuardWriter) Write(src []byte) (int, error) {
+	if self.status == 0 {
+		self.WriteHeader(http.StatusOK)
+	}
+	if self.err != nil {
+		return 0, self.err
+	}
+	if self.size+int64(len(src)) > self.max {
+		self.exceed()
+		return 0, self.err
+	}
+
+	self.send()
+	size, err := self.ResponseWriter.Write(src)
+	self.size += int64(size)
+	return size, err
+}
+
+func (self *sizeGuardWriter) Flush() {
+	if self.status == 0 {
+		self.WriteHeader(http.StatusOK)
+	}
+	if self.err != nil {
+		return
+	}
+	self.send()
+
+	flusher, _ := self.ResponseWriter.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+func (self *sizeGuardWriter) Unwrap() http.ResponseWriter { return self.ResponseWriter }
+
+// Sends the pending header, if any.
+func (self *sizeGuardWriter) send() {
+	if self.sent || self.status == 0 {
+		return
+	}
+	self.sent = true
+	self.ResponseWriter.WriteHeader(self.status)
+}
+
+func (self *sizeGuardWriter) declaredSize() int64 {
+	val, err := strconv.ParseInt(self.Header().Get(`Content-Length`), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return val
+}
+
+func (self *sizeGuardWriter) exceed() {
+	self.err = fmt.Errorf(`%w: %d bytes`, ErrResponseTooLarge, self.max)
+
+	// Allows the err func to write a valid error response.
+	if !self.sent {
+		head := self.Header()
+		head.Del(`Content-Length`)
+		head.Del(`Content-Encoding`)
+		head.Del(`Content-Range`)
+	}
+}
@@ -0,0 +1,108 @@
+package goh
+
+import (
+	"errors"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var (
+	_ = http.Handler(SizeGuard{})
+	_ = Han(SizeGuard{}.Han)
+)
+
+func TestSizeGuard(t *testing.T) {
+	t.Run(`within limit`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		SizeGuard{Max: 5, Handler: StringOk(`hello`)}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `hello`, rew.Body.String())
+	})
+
+	t.Run(`first write`, func(t *testing.T) {
+		var info ErrInfo
+		rew := ht.NewRecorder()
+		SizeGuard{
+			Max:     4,
+			Handler: StringOk(`hello`),
+			ErrFunc: func(rew http.ResponseWriter, _ *http.Request, err error, _ bool) {
+				info, _ = ErrInfoOf(err)
+				rew.WriteHeader(http.StatusInsufficientStorage)
+			},
+		}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusInsufficientStorage, rew.Code)
+		eq(t, ``, rew.Body.String())
+		eq(t, ``, rew.Header().Get(`Content-Length`))
+		eq(t, `goh.SizeGuard`, info.Handler)
+		eq(t, false, info.Wrote)
+		eq(t, true, errors.Is(info, ErrResponseTooLarge))
+	})
+
+	t.Run(`declared size`, func(t *testing.T) {
+		wrote := false
+		han := http.HandlerFunc(func(rew http.ResponseWriter, _ *http.Request) {
+			rew.Header().Set(`Content-Length`, `100`)
+			rew.WriteHeader(http.StatusOK)
+			_, err := rew.Write([]byte(`one`))
+			wrote = err == nil
+		})
+
+		rew := ht.NewRecorder()
+		SizeGuard{Max: 10, Handler: han}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, false, wrote)
+		eq(t, http.StatusInternalServerError, rew.Code)
+		eq(t, true, rew.Header().Get(`Content-Length`) != `100`)
+	})
+
+	t.Run(`default err func`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		SizeGuard{Max: 4, Handler: StringOk(`hello`)}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusInternalServerError, rew.Code)
+		eq(t, true, strings.Contains(rew.Body.String(), `size limit`))
+	})
+
+	t.Run(`streamed`, func(t *testing.T) {
+		var writeErr error
+		var info ErrInfo
+
+		stream := http.HandlerFunc(func(rew http.ResponseWriter, _ *http.Request) {
+			for {
+				_, writeErr = rew.Write([]byte(`chunk`))
+				if writeErr != nil {
+					return
+				}
+			}
+		})
+
+		rew := ht.NewRecorder()
+		func() {
+			defer func() { eq(t, http.ErrAbortHandler, recover()) }()
+			SizeGuard{
+				Max:     12,
+				Handler: stream,
+				ErrFunc: func(_ http.ResponseWriter, _ *http.Request, err error, _ bool) {
+					info, _ = ErrInfoOf(err)
+				},
+			}.ServeHTTP(rew, pathReq(`/`))
+		}()
+
+		eq(t, `chunkchunk`, rew.Body.String())
+		eq(t, true, errors.Is(writeErr, ErrResponseTooLarge))
+		eq(t, true, info.Wrote)
+		eq(t, http.StatusOK, info.Status)
+		eq(t, int64(10), info.Written)
+	})
+
+	t.Run(`no limit`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		SizeGuard{Handler: StringOk(`hello`)}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, `hello`, rew.Body.String())
+
+		rew = ht.NewRecorder()
+		SizeGuard{Max: 10}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusNotFound, rew.Code)
+	})
+}
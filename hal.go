@@ -0,0 +1,103 @@
+package goh
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Content type of HAL documents.
+const TypeHal = `application/hal+json`
+
+/*
+HAL resource, see https://datatracker.ietf.org/doc/html/draft-kelly-json-hal.
+Encodes `.Body`, which must encode as a JSON object or null, with the
+additional properties "_links" and "_embedded", which are omitted when empty.
+Values of `.Links` should be `goh.HalLink` or `[]goh.HalLink`. Values of
+`.Embedded` should be `goh.Hal` or `[]goh.Hal`. Serve via `goh.JsonHal`.
+Example:
+
+	goh.JsonHal(http.StatusOK, goh.Hal{
+		Body:  user,
+		Links: map[string]interface{}{`self`: goh.HalLink{Href: `/users/1`}},
+	})
+
+Output:
+
+	{"_links":{"self":{"href":"/users/1"}},"id":1,"name":"one"}
+*/
+type Hal struct {
+	Body     interface{}
+	Links    map[string]interface{}
+	Embedded map[string]interface{}
+}
+
+// HAL link object.
+type HalLink struct {
+	Href        string `json:"href"`
+	Templated   bool   `json:"templated,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Deprecation string `json:"deprecation,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Profile     string `json:"profile,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Hreflang    string `json:"hreflang,omitempty"`
+}
+
+// Implement `json.Marshaler`.
+func (self Hal) MarshalJSON() ([]byte, error) {
+	body, err := json.Marshal(self.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if bytes.Equal(body, []byte(`null`)) {
+		body = []byte(`{}`)
+	}
+	if len(body) < 2 || body[0] != '{' {
+		return nil, errors.New(`[goh] HAL body must encode as a JSON object`)
+	}
+
+	var fields [][]byte
+
+	if len(self.Links) > 0 {
+		val, err := json.Marshal(self.Links)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, append([]byte(`"_links":`), val...))
+	}
+
+	if len(self.Embedded) > 0 {
+		val, err := json.Marshal(self.Embedded)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, append([]byte(`"_embedded":`), val...))
+	}
+
+	rest := bytes.TrimSpace(body[1 : len(body)-1])
+	if len(rest) > 0 {
+		fields = append(fields, rest)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	buf.Write(bytes.Join(fields, []byte(`,`)))
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+/*
+Shortcut for `goh.Json` that serves the given HAL resource with the content
+type `goh.TypeHal`.
+*/
+func JsonHal(status int, val Hal) Json {
+	return Json{
+		Status: status,
+		Header: http.Header{HeadType: {withCharset(TypeHal, ``)}},
+		Body:   val,
+	}
+}
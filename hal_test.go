@@ -0,0 +1,41 @@
+package goh
+
+import (
+	"encoding/json"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHal(t *testing.T) {
+	type User struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	eq(t, `{}`, jsonString(Hal{}))
+	eq(t, `{"id":1,"name":"one"}`, jsonString(Hal{Body: User{1, `one`}}))
+
+	eq(t,
+		`{"_links":{"items":[{"href":"/users/1"},{"href":"/users/2"}],"self":{"href":"/users{?page}","templated":true}},"_embedded":{"users":[{"_links":{"self":{"href":"/users/1"}},"id":1,"name":"one"}]},"total":1}`,
+		jsonString(Hal{
+			Body: map[string]int{`total`: 1},
+			Links: map[string]interface{}{
+				`self`:  HalLink{Href: `/users{?page}`, Templated: true},
+				`items`: []HalLink{{Href: `/users/1`}, {Href: `/users/2`}},
+			},
+			Embedded: map[string]interface{}{
+				`users`: []Hal{{Body: User{1, `one`}, Links: map[string]interface{}{`self`: HalLink{Href: `/users/1`}}}},
+			},
+		}),
+	)
+
+	_, err := json.Marshal(Hal{Body: []int{1}})
+	eq(t, true, err != nil)
+
+	rew := ht.NewRecorder()
+	JsonHal(http.StatusOK, Hal{Links: map[string]interface{}{`self`: HalLink{Href: `/`}}}).ServeHTTP(rew, nil)
+	eq(t, `application/hal+json; charset=utf-8`, rew.Header().Get(HeadType))
+	eq(t, `{"_links":{"self":{"href":"/"}}}`, strings.TrimSpace(rew.Body.String()))
+}
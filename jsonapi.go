@@ -0,0 +1,142 @@
+package goh
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Content type of JSON:API documents. Has no charset, as required by the spec.
+const TypeJsonApi = `application/vnd.api+json`
+
+/*
+Top-level JSON:API document, see https://jsonapi.org. `.Data` is the primary
+data: `goh.JsonApiResource`, a slice of resources, `goh.JsonApiRef`, or nil.
+When `.Errors` is non-empty, `.Data` is omitted, as required by the spec;
+otherwise it's always present, encoded as null when nil. Other fields are
+omitted when empty. Serve via `goh.JsonApi`.
+*/
+type JsonApiDoc struct {
+	Data     interface{}
+	Errors   []JsonApiError
+	Included []JsonApiResource
+	Links    map[string]string
+	Meta     interface{}
+}
+
+// Implement `json.Marshaler`.
+func (self JsonApiDoc) MarshalJSON() ([]byte, error) {
+	if len(self.Errors) > 0 {
+		return json.Marshal(jsonApiErrDoc{
+			Errors: self.Errors,
+			Links:  self.Links,
+			Meta:   self.Meta,
+		})
+	}
+
+	return json.Marshal(jsonApiDataDoc{
+		Data:     self.Data,
+		Included: self.Included,
+		Links:    self.Links,
+		Meta:     self.Meta,
+	})
+}
+
+type jsonApiDataDoc struct {
+	Data     interface{}       `json:"data"`
+	Included []JsonApiResource `json:"included,omitempty"`
+	Links    map[string]string `json:"links,omitempty"`
+	Meta     interface{}       `json:"meta,omitempty"`
+}
+
+type jsonApiErrDoc struct {
+	Errors []JsonApiError    `json:"errors"`
+	Links  map[string]string `json:"links,omitempty"`
+	Meta   interface{}       `json:"meta,omitempty"`
+}
+
+/*
+JSON:API resource object. `.Attributes` is typically a struct or map with the
+resource fields, excluding the ID and relationships.
+*/
+type JsonApiResource struct {
+	Type          string                         `json:"type"`
+	Id            string                         `json:"id,omitempty"`
+	Attributes    interface{}                    `json:"attributes,omitempty"`
+	Relationships map[string]JsonApiRelationship `json:"relationships,omitempty"`
+	Links         map[string]string              `json:"links,omitempty"`
+	Meta          interface{}                    `json:"meta,omitempty"`
+}
+
+/*
+JSON:API relationship. `.Data` is the resource linkage: `goh.JsonApiRef`, a
+slice of refs, or nil for an empty to-one relationship. It's always present,
+encoded as null when nil.
+*/
+type JsonApiRelationship struct {
+	Data  interface{}       `json:"data"`
+	Links map[string]string `json:"links,omitempty"`
+	Meta  interface{}       `json:"meta,omitempty"`
+}
+
+// JSON:API resource identifier, used in relationships.
+type JsonApiRef struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+/*
+JSON:API error object. `.Status` is the HTTP status code as a string, as
+required by the spec. See `goh.JsonApiErrorFrom`.
+*/
+type JsonApiError struct {
+	Id     string      `json:"id,omitempty"`
+	Status string      `json:"status,omitempty"`
+	Code   string      `json:"code,omitempty"`
+	Title  string      `json:"title,omitempty"`
+	Detail string      `json:"detail,omitempty"`
+	Source interface{} `json:"source,omitempty"`
+	Meta   interface{} `json:"meta,omitempty"`
+}
+
+// Returns a JSON:API error object for the given error and status.
+func JsonApiErrorFrom(err error, status int) JsonApiError {
+	return JsonApiError{
+		Status: strconv.Itoa(status),
+		Title:  http.StatusText(status),
+		Detail: errMsg(err),
+	}
+}
+
+/*
+Shortcut for `goh.Json` that serves the given JSON:API document with the
+content type `goh.TypeJsonApi`.
+*/
+func JsonApi(status int, doc JsonApiDoc) Json {
+	return Json{
+		Status: status,
+		Header: http.Header{HeadType: {TypeJsonApi}},
+		Body:   doc,
+	}
+}
+
+/*
+Variant of `goh.ErrJson` that serves the error as a JSON:API document. When the
+status is 0, it's determined by `goh.ErrHttpStatus`.
+*/
+func ErrJsonApi(status int, err error) Json {
+	status = orErrStatus(status, err)
+	out := JsonApi(status, JsonApiDoc{Errors: []JsonApiError{JsonApiErrorFrom(err, status)}})
+	AppendHeader(out.Header, errHeader(err))
+	return out
+}
+
+/*
+Implements `goh.ErrRenderer` by writing a JSON:API document with one error
+object. Registered in `goh.ErrRenderers` for `goh.TypeJsonApi`.
+*/
+func ErrRenderJsonApi(rew http.ResponseWriter, _ *http.Request, err error, status int) error {
+	rew.Header().Set(HeadType, TypeJsonApi)
+	rew.WriteHeader(status)
+	return json.NewEncoder(rew).Encode(JsonApiDoc{Errors: []JsonApiError{JsonApiErrorFrom(err, status)}})
+}
@@ -0,0 +1,68 @@
+package goh
+
+import (
+	"encoding/json"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func jsonString(val interface{}) string {
+	out, err := json.Marshal(val)
+	try(err)
+	return string(out)
+}
+
+func TestJsonApi(t *testing.T) {
+	eq(t, `{"data":null}`, jsonString(JsonApiDoc{}))
+
+	eq(t,
+		`{"data":{"type":"users","id":"1","attributes":{"name":"one"},"relationships":{"group":{"data":{"type":"groups","id":"2"}},"manager":{"data":null}}},"links":{"self":"/users/1"}}`,
+		jsonString(JsonApiDoc{
+			Data: JsonApiResource{
+				Type:       `users`,
+				Id:         `1`,
+				Attributes: map[string]string{`name`: `one`},
+				Relationships: map[string]JsonApiRelationship{
+					`group`:   {Data: JsonApiRef{`groups`, `2`}},
+					`manager`: {},
+				},
+			},
+			Links: map[string]string{`self`: `/users/1`},
+		}),
+	)
+
+	eq(t,
+		`{"errors":[{"status":"404","title":"Not Found","detail":"missing"}]}`,
+		jsonString(JsonApiDoc{Data: `ignored`, Errors: []JsonApiError{JsonApiErrorFrom(ErrNotFound(`missing`), http.StatusNotFound)}}),
+	)
+
+	t.Run(`handler`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		JsonApi(http.StatusOK, JsonApiDoc{Data: []JsonApiResource{{Type: `users`, Id: `1`}}}).ServeHTTP(rew, nil)
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, TypeJsonApi, rew.Header().Get(HeadType))
+		eq(t, `{"data":[{"type":"users","id":"1"}]}`, strings.TrimSpace(rew.Body.String()))
+	})
+
+	t.Run(`error`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		ErrJsonApi(0, ErrStatus{Status: http.StatusConflict, Header: http.Header{`One`: {`two`}}}).ServeHTTP(rew, nil)
+		eq(t, http.StatusConflict, rew.Code)
+		eq(t, TypeJsonApi, rew.Header().Get(HeadType))
+		eq(t, `two`, rew.Header().Get(`One`))
+		eq(t, `{"errors":[{"status":"409","title":"Conflict","detail":"Conflict"}]}`, strings.TrimSpace(rew.Body.String()))
+	})
+
+	t.Run(`renderer`, func(t *testing.T) {
+		req := pathReq(`/`)
+		req.Header = http.Header{`Accept`: {TypeJsonApi}}
+
+		rew := ht.NewRecorder()
+		ErrNotFound(`missing`).ServeHTTP(rew, req)
+		eq(t, http.StatusNotFound, rew.Code)
+		eq(t, TypeJsonApi, rew.Header().Get(HeadType))
+		eq(t, `{"errors":[{"status":"404","title":"Not Found","detail":"missing"}]}`, strings.TrimSpace(rew.Body.String()))
+	})
+}
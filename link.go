@@ -0,0 +1,159 @@
+package goh
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Name of the RFC 8288 "Link" header, built by `goh.Link` and `goh.Links`.
+const HeadLink = `Link`
+
+/*
+Single value of the RFC 8288 "Link" header. Builds the header value with
+proper escaping, avoiding manual string concatenation. Example usage:
+
+	head := goh.Links{
+		goh.LinkPreload(`/static/app.js`, `script`),
+		{Url: `/api`, Rel: `service-desc`, Type: `application/json`, Title: `API`},
+	}.Header()
+
+	// Link: </static/app.js>; rel="preload"; as="script", </api>; rel="service-desc"; type="application/json"; title="API"
+
+`.Url` is enclosed in angle brackets, and characters which would terminate or
+break it, such as ">" and whitespace, are percent-encoded. `.Rel`, `.Type`,
+`.Title`, and `.Params` are written in this order as quoted strings, skipping
+empty ones. `.Rel` may contain multiple space-separated relation types. When
+`.Title` contains non-ASCII characters, it's written as the RFC 8187
+"title*" parameter instead. A param with an empty value is written as a bare
+attribute, such as "crossorigin".
+*/
+type Link struct {
+	Url    string
+	Rel    string
+	Type   string
+	Title  string
+	Params []LinkParam
+}
+
+// Additional parameter of `goh.Link`, such as "as" or "crossorigin".
+type LinkParam struct {
+	Key string
+	Val string
+}
+
+/*
+Shortcut for a "preload" link with the given "as" destination, such as
+"script", "style", "font". Font preloads typically also require the
+"crossorigin" param, see `goh.Link.With`.
+*/
+func LinkPreload(url, as string) Link {
+	return Link{Url: url, Rel: `preload`}.With(`as`, as)
+}
+
+// Returns a copy with the given param appended. Doesn't mutate the original.
+func (self Link) With(key, val string) Link {
+	params := make([]LinkParam, len(self.Params), len(self.Params)+1)
+	copy(params, self.Params)
+	self.Params = append(params, LinkParam{key, val})
+	return self
+}
+
+// Returns the header value, such as `</one>; rel="next"`.
+func (self Link) String() string {
+	var buf strings.Builder
+	buf.WriteString(`<`)
+	buf.WriteString(linkUrlReplacer.Replace(self.Url))
+	buf.WriteString(`>`)
+
+	writeLinkParam(&buf, `rel`, self.Rel)
+	writeLinkParam(&buf, `type`, self.Type)
+
+	if isAscii(self.Title) {
+		writeLinkParam(&buf, `title`, self.Title)
+	} else {
+		buf.WriteString(`; title*=`)
+		writeExtValue(&buf, self.Title)
+	}
+
+	for _, param := range self.Params {
+		if param.Key == `` {
+			continue
+		}
+		if param.Val == `` {
+			buf.WriteString(`; `)
+			buf.WriteString(param.Key)
+			continue
+		}
+		writeLinkParam(&buf, param.Key, param.Val)
+	}
+	return buf.String()
+}
+
+// Appends the link to the "Link" header.
+func (self Link) Apply(head http.Header) { head.Add(HeadLink, self.String()) }
+
+/*
+Sequence of `goh.Link`, serialized as a single comma-separated "Link" header
+value. See `goh.Link` for an example.
+*/
+type Links []Link
+
+// Returns the comma-separated header value, or an empty string if empty.
+func (self Links) String() string {
+	var buf strings.Builder
+	for ind, val := range self {
+		if ind > 0 {
+			buf.WriteString(`, `)
+		}
+		buf.WriteString(val.String())
+	}
+	return buf.String()
+}
+
+// Returns a new header with the links. See `goh.Links.Apply`.
+func (self Links) Header() http.Header {
+	out := http.Header{}
+	self.Apply(out)
+	return out
+}
+
+// Appends the links to the "Link" header. Does nothing if empty.
+func (self Links) Apply(head http.Header) {
+	if len(self) > 0 {
+		head.Add(HeadLink, self.String())
+	}
+}
+
+var linkUrlReplacer = strings.NewReplacer(
+	`<`, `%3C`,
+	`>`, `%3E`,
+	` `, `%20`,
+	`"`, `%22`,
+	"\t", `%09`,
+	"\r", `%0D`,
+	"\n", `%0A`,
+)
+
+func writeLinkParam(buf *strings.Builder, key, val string) {
+	if val == `` {
+		return
+	}
+	buf.WriteString(`; `)
+	buf.WriteString(key)
+	buf.WriteString(`="`)
+	buf.WriteString(quotedStringReplacer.Replace(val))
+	buf.WriteString(`"`)
+}
+
+// Escapes the contents of an RFC 7230 quoted string.
+var quotedStringReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func isAscii(val string) bool {
+	for ind := 0; ind < len(val); ind++ {
+		if val[ind] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
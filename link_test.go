@@ -0,0 +1,66 @@
+package goh
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLink(t *testing.T) {
+	eq(t, `</one>`, Link{Url: `/one`}.String())
+	eq(t, `</one>; rel="next"`, Link{Url: `/one`, Rel: `next`}.String())
+
+	eq(
+		t,
+		`</api>; rel="service-desc describedby"; type="application/json"; title="The \"API\" \\ v1"`,
+		Link{
+			Url:   `/api`,
+			Rel:   `service-desc describedby`,
+			Type:  `application/json`,
+			Title: `The "API" \ v1`,
+		}.String(),
+	)
+
+	eq(
+		t,
+		`</a%3Eb%20c%22d>; rel="alternate"`,
+		Link{Url: `/a>b c"d`, Rel: `alternate`}.String(),
+	)
+
+	eq(
+		t,
+		`</de>; rel="alternate"; title*=UTF-8''Deutsch%20%C3%BCber`,
+		Link{Url: `/de`, Rel: `alternate`, Title: `Deutsch über`}.String(),
+	)
+
+	eq(
+		t,
+		`</font.woff2>; rel="preload"; as="font"; type="font/woff2"; crossorigin`,
+		LinkPreload(`/font.woff2`, `font`).With(`type`, `font/woff2`).With(`crossorigin`, ``).String(),
+	)
+}
+
+func TestLink_With_no_aliasing(t *testing.T) {
+	base := Link{Url: `/one`, Params: make([]LinkParam, 0, 4)}
+	one := base.With(`as`, `script`)
+	two := base.With(`as`, `style`)
+
+	eq(t, `</one>; as="script"`, one.String())
+	eq(t, `</one>; as="style"`, two.String())
+	eq(t, 0, len(base.Params))
+}
+
+func TestLinks(t *testing.T) {
+	eq(t, ``, Links(nil).String())
+	eq(t, http.Header{}, Links(nil).Header())
+
+	links := Links{LinkPreload(`/app.js`, `script`), {Url: `/next`, Rel: `next`}}
+	eq(t, `</app.js>; rel="preload"; as="script", </next>; rel="next"`, links.String())
+
+	head := http.Header{HeadLink: {`</prev>; rel="prev"`}}
+	links.Apply(head)
+	eq(t, []string{`</prev>; rel="prev"`, links.String()}, head.Values(HeadLink))
+
+	head = http.Header{}
+	Link{Url: `/one`, Rel: `next`}.Apply(head)
+	eq(t, `</one>; rel="next"`, head.Get(HeadLink))
+}
@@ -0,0 +1,221 @@
+package goh
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*
+Returns a directory listing handler for the requested directory, or nil if
+listings are disabled via `.List`, if the requested path is not a directory, or
+if the directory is not allowed by the filter. The filter receives directory
+paths with a trailing slash. Listed entries are also filtered. The listing is
+HTML by default, or JSON if `.ListJson` is true.
+*/
+func (self Dir) ListOpt(req *http.Request) http.Handler {
+	if !self.List {
+		return nil
+	}
+
+	list, ok := self.DirList(req)
+	if !ok {
+		return nil
+	}
+	if self.ListJson {
+		return list.Json()
+	}
+	return list
+}
+
+/*
+Resolves the request to a `goh.DirList` describing the requested directory.
+Returns false if the request path is not an allowed directory. Entries that
+can't be read are skipped.
+*/
+func (self Dir) DirList(req *http.Request) (_ DirList, _ bool) {
+	reqPath, ok := self.reqPath(req)
+	if !ok {
+		return
+	}
+
+	dirPath := filepath.Join(self.Path, reqPath)
+	if !dirExists(dirPath) || !self.allowDir(dirPath) || !self.allowReal(dirPath) {
+		return
+	}
+
+	ents, err := os.ReadDir(dirPath)
+	if err != nil {
+		return
+	}
+
+	out := DirList{
+		Status:       self.Status,
+		Header:       self.Header,
+		ErrFunc:      self.ErrFunc,
+		AppendHeader: self.AppendHeader,
+		Path:         path.Join(`/`, req.URL.Path),
+		Tmpl:         self.ListTmpl,
+	}
+
+	for _, ent := range ents {
+		if !self.AllowDotfiles && strings.HasPrefix(ent.Name(), `.`) {
+			continue
+		}
+
+		entPath := filepath.Join(dirPath, ent.Name())
+		info, err := self.entInfo(ent, entPath)
+		if err != nil {
+			continue
+		}
+
+		isDir := info.IsDir()
+		if isDir && !self.allowDir(entPath) ||
+			!isDir && !self.Allow(entPath) ||
+			!isDir && !self.File(entPath).AllowSize(info.Size()) {
+			continue
+		}
+
+		val := DirEntry{Name: ent.Name(), ModTime: info.ModTime(), IsDir: isDir}
+		if !val.IsDir {
+			val.Size = info.Size()
+		}
+		out.Entries = append(out.Entries, val)
+	}
+
+	return out, true
+}
+
+/*
+Returns the info of the entry, following symlinks permitted by
+`.FollowSymlinks`. Other symlinks produce an error.
+*/
+func (self Dir) entInfo(ent fs.DirEntry, path string) (fs.FileInfo, error) {
+	if ent.Type()&fs.ModeSymlink == 0 {
+		return ent.Info()
+	}
+	if !self.allowReal(path) {
+		return nil, fs.ErrPermission
+	}
+	return os.Stat(path)
+}
+
+func (self Dir) allowDir(path string) bool {
+	return self.Allow(path + string(filepath.Separator))
+}
+
+// Describes a single entry in a `goh.DirList`.
+type DirEntry struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	IsDir   bool      `json:"isDir"`
+}
+
+/*
+HTTP handler that renders a directory listing as HTML. Used by `goh.Dir` when
+`.List` is enabled. `.Path` is the URL path of the directory, used for the
+title and for entry links.
+
+The template is executed with the `goh.DirList` as its data. When `.Tmpl` is
+nil, `goh.DirListTmpl` is used. Custom templates may use the method `.Href`
+to build entry links.
+*/
+type DirList struct {
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Path         string
+	Entries      []DirEntry
+	Tmpl         *template.Template
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self DirList) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self DirList) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.DirList`, self.serveHTTP)
+}
+
+func (self DirList) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(HeadType, `text/html; charset=utf-8`)
+
+	head := self.Head()
+	head.Write(rew)
+
+	writer := spyingWriter{Writer: rew}
+	err := self.tmpl().Execute(&writer, self)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write directory listing: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.DirList`, head.sentStatusIf(writer.wrote), writer.size, writer.wrote})
+	}
+}
+
+// Conforms to `goh.Han`.
+func (self DirList) Han(*http.Request) http.Handler { return self }
+
+/*
+Converts to `goh.Json` whose body is the list of entries, preserving the head.
+The body is always a JSON array, even when empty. Example output:
+
+	[{"name":"one.txt","size":3,"mtime":"2020-01-02T03:04:05Z","isDir":false}]
+*/
+func (self DirList) Json() Json {
+	body := self.Entries
+	if body == nil {
+		body = []DirEntry{}
+	}
+	return Json{
+		Status:       self.Status,
+		Header:       self.Header,
+		ErrFunc:      self.ErrFunc,
+		AppendHeader: self.AppendHeader,
+		Body:         body,
+	}
+}
+
+// Returns the escaped absolute URL path of the given entry.
+func (self DirList) Href(val DirEntry) string {
+	out := path.Join(self.Path, val.Name)
+	if val.IsDir {
+		out += `/`
+	}
+	return (&url.URL{Path: out}).EscapedPath()
+}
+
+func (self DirList) tmpl() *template.Template {
+	if self.Tmpl != nil {
+		return self.Tmpl
+	}
+	return DirListTmpl
+}
+
+// Default template used by `goh.DirList`. May be overridden globally.
+var DirListTmpl = template.Must(template.New(`goh.DirList`).Parse(strings.TrimSpace(`
+<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>{{.Path}}</title></head>
+<body>
+<h1>{{.Path}}</h1>
+<table>
+<thead><tr><th>Name</th><th>Size</th><th>Modified</th></tr></thead>
+<tbody>
+{{- range .Entries}}
+<tr><td><a href="{{$.Href .}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td>{{if not .IsDir}}{{.Size}}{{end}}</td><td>{{.ModTime.UTC.Format "2006-01-02 15:04:05"}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`)))
@@ -0,0 +1,128 @@
+package goh
+
+import (
+	"html/template"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(DirList{})
+	_ = Han(DirList{}.Han)
+)
+
+func listDir(t testing.TB) string {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `one/two.txt`), `two`)
+	writeFile(filepath.Join(root, `one/three.txt`), `three!`)
+	writeFile(filepath.Join(root, `one/four/five.txt`), `five`)
+	writeFile(filepath.Join(root, `one/.six.txt`), `six`)
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, path := range []string{`one/two.txt`, `one/three.txt`, `one/four`} {
+		try(os.Chtimes(filepath.Join(root, path), mtime, mtime))
+	}
+	return root
+}
+
+func TestDir_List(t *testing.T) {
+	root := listDir(t)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run(`disabled`, func(t *testing.T) {
+		testDir404(t, Dir{Path: root}, pathReq(`/one/`))
+	})
+
+	t.Run(`entries`, func(t *testing.T) {
+		list, ok := Dir{Path: root}.DirList(pathReq(`/one`))
+		eq(t, true, ok)
+		eq(t, `/one`, list.Path)
+
+		for i := range list.Entries {
+			list.Entries[i].ModTime = list.Entries[i].ModTime.UTC()
+		}
+
+		eq(t, []DirEntry{
+			{Name: `four`, ModTime: mtime, IsDir: true},
+			{Name: `three.txt`, Size: 6, ModTime: mtime},
+			{Name: `two.txt`, Size: 3, ModTime: mtime},
+		}, list.Entries)
+	})
+
+	t.Run(`dotfiles`, func(t *testing.T) {
+		list, ok := Dir{Path: root, AllowDotfiles: true}.DirList(pathReq(`/one`))
+		eq(t, true, ok)
+		eq(t, 4, len(list.Entries))
+		eq(t, `.six.txt`, list.Entries[0].Name)
+	})
+
+	t.Run(`max size`, func(t *testing.T) {
+		list, ok := Dir{Path: root, MaxSize: 5}.DirList(pathReq(`/one`))
+		eq(t, true, ok)
+		eq(t, 2, len(list.Entries))
+		eq(t, `four`, list.Entries[0].Name)
+		eq(t, `two.txt`, list.Entries[1].Name)
+	})
+
+	t.Run(`not dir`, func(t *testing.T) {
+		_, ok := Dir{Path: root}.DirList(pathReq(`/one/two.txt`))
+		eq(t, false, ok)
+	})
+
+	t.Run(`filter`, func(t *testing.T) {
+		filter := FilterFunc(func(path string) bool {
+			return !strings.HasSuffix(path, `/two.txt`) && !strings.HasSuffix(path, `/four/`)
+		})
+		list, ok := Dir{Path: root, Filter: filter}.DirList(pathReq(`/one/`))
+		eq(t, true, ok)
+		eq(t, 1, len(list.Entries))
+		eq(t, `three.txt`, list.Entries[0].Name)
+
+		_, ok = Dir{Path: root, Filter: filter}.DirList(pathReq(`/one/four`))
+		eq(t, false, ok)
+	})
+
+	t.Run(`html`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Dir{Path: root, List: true}.ServeHTTP(rew, pathReq(`/one/`))
+
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `text/html; charset=utf-8`, rew.Header().Get(HeadType))
+
+		body := rew.Body.String()
+		eq(t, true, strings.Contains(body, `<title>/one</title>`))
+		eq(t, true, strings.Contains(body, `<a href="/one/four/">four/</a>`))
+		eq(t, true, strings.Contains(body, `<a href="/one/two.txt">two.txt</a></td><td>3</td><td>2020-01-02 03:04:05</td>`))
+	})
+
+	t.Run(`custom template`, func(t *testing.T) {
+		tmpl := template.Must(template.New(``).Parse(`{{range .Entries}}{{.Name}};{{end}}`))
+
+		rew := ht.NewRecorder()
+		Dir{Path: root, List: true, ListTmpl: tmpl}.ServeHTTP(rew, pathReq(`/one`))
+		eq(t, `four;three.txt;two.txt;`, rew.Body.String())
+	})
+
+	t.Run(`json`, func(t *testing.T) {
+		dir := Dir{Path: root, List: true, ListJson: true}
+
+		rew := ht.NewRecorder()
+		dir.ServeHTTP(rew, pathReq(`/one/four`))
+
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, typeJsonUtf8, rew.Header().Get(HeadType))
+		eq(t, true, strings.HasPrefix(rew.Body.String(), `[{"name":"five.txt","size":4,"mtime":"`))
+		eq(t, true, strings.HasSuffix(rew.Body.String(), `","isDir":false}]`+"\n"))
+	})
+
+	t.Run(`json empty`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		DirList{}.Json().ServeHTTP(rew, nil)
+		eq(t, "[]\n", rew.Body.String())
+	})
+}
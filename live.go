@@ -0,0 +1,193 @@
+package goh
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Delay between retries of a failed initial load when `.Interval` is unset.
+const liveDirRetry = time.Second
+
+/*
+Variant of `goh.MemDir` that can pick up file changes without restarting the
+process. Must be used by pointer. Content is loaded lazily on first use, and
+can be reloaded explicitly via `.Reload`.
+
+When `.Interval` is positive, requests check for changes at most once per
+interval by comparing `goh.MemDir.Signature`, which stats the files without
+reading them, and reload the content when it differs. The check is performed
+by one request at a time, while others keep serving the current content. If a
+reload fails, the previously loaded content continues to be served, and the
+error is logged to the standard error stream. If the initial load fails, it's
+retried at most once per `.Interval`, or once per second when the interval is
+not positive; meanwhile, requests are served by `.Mem` as-is, which respects
+settings such as `.Mem.Dir.Fallback`. Example usage:
+
+	var han = &goh.LiveDir{
+		Mem:      goh.MemDir{Dir: goh.Dir{Path: `static`}},
+		Interval: time.Second,
+	}
+*/
+type LiveDir struct {
+	Mem      MemDir
+	Interval time.Duration
+
+	lock    sync.Mutex
+	cur     atomic.Value
+	checked atomic.Value
+	busy    int32
+}
+
+// Content loaded by `goh.LiveDir`, replaced atomically on reload.
+type liveDirState struct {
+	mem MemDir
+	sig uint64
+}
+
+/*
+Reloads the content from disk, regardless of `.Interval`. On error, the
+previously loaded content is kept.
+*/
+func (self *LiveDir) Reload() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.reload()
+}
+
+/*
+Returns the currently loaded content, loading or reloading it if necessary,
+see the comment on `goh.LiveDir`. Requests don't wait for a check or reload in
+progress, unless nothing was loaded yet.
+*/
+func (self *LiveDir) Current() MemDir {
+	state, ok := self.state()
+	if !ok {
+		self.load()
+		state, ok = self.state()
+
+		// Preserves settings such as `.Dir.Fallback` until loaded.
+		if !ok {
+			return self.Mem
+		}
+	} else if self.due(self.Interval) && atomic.CompareAndSwapInt32(&self.busy, 0, 1) {
+		self.check()
+		state, _ = self.state()
+	}
+	return state.mem
+}
+
+// Implement `http.Handler`.
+func (self *LiveDir) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.LiveDir`, self.serveHTTP)
+}
+
+func (self *LiveDir) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	self.Current().ServeHTTP(rew, req)
+}
+
+// Implement `goh.HttpHandlerOpt`. See `goh.MemDir.ServedHTTP`.
+func (self *LiveDir) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.LiveDir`, self.servedHTTP)
+}
+
+func (self *LiveDir) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return self.Current().ServedHTTP(rew, req)
+}
+
+// Conforms to `goh.Han`. See `goh.MemDir.Han`.
+func (self *LiveDir) Han(req *http.Request) http.Handler {
+	return self.Current().Han(req)
+}
+
+// Conforms to `goh.Han`. See `goh.MemDir.HanOpt`.
+func (self *LiveDir) HanOpt(req *http.Request) http.Handler {
+	return self.Current().HanOpt(req)
+}
+
+func (self *LiveDir) state() (liveDirState, bool) {
+	val, ok := self.cur.Load().(liveDirState)
+	return val, ok
+}
+
+// True if the given interval has passed since the last check or load attempt.
+func (self *LiveDir) due(interval time.Duration) bool {
+	if interval <= 0 {
+		return false
+	}
+	checked, _ := self.checked.Load().(time.Time)
+	return time.Since(checked) >= interval
+}
+
+/*
+Initial load. Concurrent callers wait for it. After a failure, it's retried at
+most once per `.Interval`, or per `liveDirRetry` when the interval is not
+positive, which also limits logging.
+*/
+func (self *LiveDir) load() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	retry := self.Interval
+	if retry <= 0 {
+		retry = liveDirRetry
+	}
+
+	_, ok := self.state()
+	if ok || !self.due(retry) {
+		return
+	}
+	self.logErr(self.reload())
+}
+
+/*
+Checks for changes and reloads if necessary. Called by at most one request at
+a time, see `.busy`; other requests keep serving the current content.
+*/
+func (self *LiveDir) check() {
+	defer atomic.StoreInt32(&self.busy, 0)
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	// May have been reloaded explicitly in the meantime.
+	if !self.due(self.Interval) {
+		return
+	}
+	self.checked.Store(time.Now())
+
+	state, _ := self.state()
+	sig, err := self.Mem.Signature()
+	if err != nil {
+		self.logErr(err)
+	} else if sig != state.sig {
+		self.logErr(self.reload())
+	}
+}
+
+// Must be called under lock.
+func (self *LiveDir) reload() error {
+	self.checked.Store(time.Now())
+
+	sig, err := self.Mem.Signature()
+	if err != nil {
+		return err
+	}
+
+	cur, err := self.Mem.Load()
+	if err != nil {
+		return err
+	}
+
+	self.cur.Store(liveDirState{cur, sig})
+	return nil
+}
+
+func (self *LiveDir) logErr(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[goh] failed to reload directory %q: %+v\n", self.Mem.Dir.Path, err)
+	}
+}
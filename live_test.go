@@ -0,0 +1,73 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(new(LiveDir))
+	_ = HttpHandlerOpt(new(LiveDir))
+	_ = Han(new(LiveDir).Han)
+	_ = Han(new(LiveDir).HanOpt)
+)
+
+func TestLiveDir(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, `one.txt`)
+	writeFile(path, `one`)
+
+	test := func(han http.Handler, code int, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, pathReq(`/one.txt`))
+		eq(t, code, rew.Code)
+		eq(t, exp, rew.Body.String())
+	}
+
+	touch := func(body string, mtime time.Time) {
+		writeFile(path, body)
+		try(os.Chtimes(path, mtime, mtime))
+	}
+
+	t.Run(`explicit reload`, func(t *testing.T) {
+		live := &LiveDir{Mem: MemDir{Dir: Dir{Path: root}}}
+		test(live, http.StatusOK, `one`)
+
+		touch(`two`, time.Now().Add(time.Hour))
+		test(live, http.StatusOK, `one`)
+
+		try(live.Reload())
+		test(live, http.StatusOK, `two`)
+	})
+
+	t.Run(`interval`, func(t *testing.T) {
+		live := &LiveDir{Mem: MemDir{Dir: Dir{Path: root}}, Interval: time.Nanosecond}
+		test(live, http.StatusOK, `two`)
+
+		touch(`three`, time.Now().Add(2*time.Hour))
+		time.Sleep(time.Millisecond)
+		test(live, http.StatusOK, `three`)
+
+		try(os.Remove(path))
+		time.Sleep(time.Millisecond)
+		test(live, http.StatusNotFound, ``)
+	})
+	t.Run(`failed initial load`, func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), `missing`)
+		dir := Dir{Path: missing, Fallback: StringWith(http.StatusNotFound, `fallback`)}
+		live := &LiveDir{Mem: MemDir{Dir: dir}, Interval: time.Hour}
+		test(live, http.StatusNotFound, `fallback`)
+
+		try(os.Mkdir(missing, os.ModePerm))
+		writeFile(filepath.Join(missing, `one.txt`), `four`)
+		test(live, http.StatusNotFound, `fallback`)
+
+		try(live.Reload())
+		test(live, http.StatusOK, `four`)
+	})
+}
@@ -0,0 +1,160 @@
+package goh
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+)
+
+/*
+HTTP handler that renders Markdown to HTML via the user-supplied `.Render`,
+serving the result with the content type `goh.TypeHtml`. Goh doesn't include a
+Markdown implementation; `.Render` may wrap any library. The source is taken
+from `.Path` when set, otherwise from `.Body`. When `.FS` is set, `.Path` is
+read from `.FS`, otherwise from the OS filesystem. Example usage:
+
+	var readme = goh.Markdown{Path: `readme.md`, Render: renderMarkdown}
+
+	func renderMarkdown(src []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		err := goldmark.Convert(src, &buf)
+		return buf.Bytes(), err
+	}
+
+The handler reads and renders the source on each request, which allows to edit
+the file without restarting the process. For production, use
+`goh.Markdown.Load`, which renders once and caches the result in memory. When
+the file is missing, the handler responds with 404 via `.ErrFunc`. The field
+`.Charset` is appended to the content type, defaulting to
+`goh.DefaultCharset`.
+*/
+type Markdown struct {
+	Status          int
+	Header          http.Header
+	ErrFunc         ErrFunc
+	AppendHeader    bool
+	NoContentLength bool
+	Language        string
+	Charset         string
+	Body            string
+	Path            string
+	FS              fs.FS
+	Render          func(src []byte) ([]byte, error)
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self Markdown) Head() Head {
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
+}
+
+// Implement `http.Handler`.
+func (self Markdown) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Markdown`, self.serveHTTP)
+}
+
+func (self Markdown) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	head := self.Head()
+
+	body, _, err := self.render()
+	if err != nil {
+		head.fail(rew, req, ErrInfo{err, `goh.Markdown`, 0, 0, false})
+		return
+	}
+
+	rew.Header().Set(HeadType, withCharset(TypeHtml, self.Charset))
+	head.Write(rew)
+
+	size, err := rew.Write(body)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write response HTML: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.Markdown`, head.sentStatusIf(size > 0), int64(size), size > 0})
+	}
+}
+
+// Conforms to `goh.Han`.
+func (self Markdown) Han(*http.Request) http.Handler { return self }
+
+/*
+Renders the source once, returning a `goh.MemFile` that serves the resulting
+HTML from memory, with the status, header, and err func of this handler, plus
+headers "Etag" (content hash) and "Last-Modified" (when the source is a file).
+Conditional requests are supported. Should be used in root scope:
+
+	var readme = goh.MustMemFile(goh.Markdown{Path: `readme.md`, Render: renderMarkdown}.Load())
+*/
+func (self Markdown) Load() (MemFile, error) {
+	body, mtime, err := self.render()
+	if err != nil {
+		return MemFile{}, err
+	}
+
+	header := cloneHeader(self.Header)
+	if header.Get(HeadType) == `` {
+		header.Set(HeadType, withCharset(TypeHtml, self.Charset))
+	}
+
+	dir := Dir{Status: self.Status, Header: header, ErrFunc: self.ErrFunc, AppendHeader: self.AppendHeader}
+	out := MemDir{Dir: dir}.memFile(`index.html`, body, mtime)
+	out.Plain.NoContentLength = self.NoContentLength
+	out.Plain.Language = self.Language
+	return out, nil
+}
+
+func (self Markdown) render() ([]byte, time.Time, error) {
+	if self.Render == nil {
+		return nil, time.Time{}, errors.New(`[goh] missing Markdown renderer`)
+	}
+
+	src, mtime, err := self.source()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, mtime, ErrStatus{Status: http.StatusNotFound, Err: fmt.Errorf(`[goh] failed to read Markdown: %w`, err)}
+	}
+	if err != nil {
+		return nil, mtime, fmt.Errorf(`[goh] failed to read Markdown: %w`, err)
+	}
+
+	out, err := self.Render(src)
+	if err != nil {
+		return nil, mtime, fmt.Errorf(`[goh] failed to render Markdown: %w`, err)
+	}
+	return out, mtime, nil
+}
+
+func (self Markdown) source() ([]byte, time.Time, error) {
+	if self.Path == `` {
+		return []byte(self.Body), time.Time{}, nil
+	}
+
+	var info fs.FileInfo
+	var err error
+	if self.FS != nil {
+		info, err = fs.Stat(self.FS, self.Path)
+	} else {
+		info, err = os.Stat(self.Path)
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var src []byte
+	if self.FS != nil {
+		src, err = fs.ReadFile(self.FS, self.Path)
+	} else {
+		src, err = os.ReadFile(self.Path)
+	}
+	return src, info.ModTime(), err
+}
+
+/*
+Panics if the error is non-nil, otherwise returns the value. Intended for
+initializing global variables, see `goh.Markdown.Load`.
+*/
+func MustMemFile(val MemFile, err error) MemFile {
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
@@ -0,0 +1,94 @@
+package goh
+
+import (
+	"errors"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var (
+	_ = http.Handler(Markdown{})
+	_ = Han(Markdown{}.Han)
+)
+
+func renderTestMarkdown(src []byte) ([]byte, error) {
+	text := strings.TrimPrefix(string(src), `# `)
+	if text == string(src) {
+		return nil, errors.New(`unsupported`)
+	}
+	return []byte(`<h1>` + text + `</h1>`), nil
+}
+
+func TestMarkdown(t *testing.T) {
+	root := t.TempDir()
+	filePath := filepath.Join(root, `one.md`)
+	writeFile(filePath, `# one`)
+
+	test := func(han http.Handler, status int, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, pathReq(`/`))
+		eq(t, status, rew.Code)
+		eq(t, true, strings.Contains(rew.Body.String(), exp))
+	}
+
+	t.Run(`body`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Markdown{Status: http.StatusCreated, Body: `# hello`, Render: renderTestMarkdown}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusCreated, rew.Code)
+		eq(t, `text/html; charset=utf-8`, rew.Header().Get(HeadType))
+		eq(t, `<h1>hello</h1>`, rew.Body.String())
+	})
+
+	t.Run(`file`, func(t *testing.T) {
+		han := Markdown{Path: filePath, Render: renderTestMarkdown}
+		test(han, http.StatusOK, `<h1>one</h1>`)
+
+		writeFile(filePath, `# two`)
+		test(han, http.StatusOK, `<h1>two</h1>`)
+	})
+
+	t.Run(`fs`, func(t *testing.T) {
+		src := fstest.MapFS{`docs/one.md`: {Data: []byte(`# fs`)}}
+		test(Markdown{FS: src, Path: `docs/one.md`, Render: renderTestMarkdown}, http.StatusOK, `<h1>fs</h1>`)
+		test(Markdown{FS: src, Path: `docs/missing.md`, Render: renderTestMarkdown}, http.StatusNotFound, `failed to read Markdown`)
+	})
+
+	t.Run(`errors`, func(t *testing.T) {
+		test(Markdown{Path: filepath.Join(root, `missing.md`), Render: renderTestMarkdown}, http.StatusNotFound, `failed to read Markdown`)
+		test(Markdown{Body: `one`, Render: renderTestMarkdown}, http.StatusInternalServerError, `failed to render Markdown: unsupported`)
+		test(Markdown{Body: `# one`}, http.StatusInternalServerError, `missing Markdown renderer`)
+	})
+
+	t.Run(`load`, func(t *testing.T) {
+		mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		writeFile(filePath, `# three`)
+		try(os.Chtimes(filePath, mtime, mtime))
+
+		file := MustMemFile(Markdown{Path: filePath, Render: renderTestMarkdown}.Load())
+		writeFile(filePath, `# four`)
+
+		rew := ht.NewRecorder()
+		file.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `<h1>three</h1>`, rew.Body.String())
+		eq(t, `text/html; charset=utf-8`, rew.Header().Get(HeadType))
+		eq(t, `Thu, 02 Jan 2020 03:04:05 GMT`, rew.Header().Get(`Last-Modified`))
+
+		req := pathReq(`/`)
+		req.Header = http.Header{`If-None-Match`: {rew.Header().Get(`Etag`)}}
+		rew = ht.NewRecorder()
+		file.ServeHTTP(rew, req)
+		eq(t, http.StatusNotModified, rew.Code)
+
+		_, err := Markdown{Body: `one`, Render: renderTestMarkdown}.Load()
+		eq(t, true, err != nil)
+	})
+}
@@ -0,0 +1,334 @@
+package goh
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+Shortcut for `goh.MemDir{Dir: self}.Load()`. Loads all servable files from the
+directory into memory. See `goh.MemDir`.
+*/
+func (self Dir) Preload() (MemDir, error) { return MemDir{Dir: self}.Load() }
+
+/*
+HTTP handler that serves files preloaded into memory from a `goh.Dir`,
+eliminating per-request filesystem access. Intended for small static sites.
+Should be created at startup via `goh.Dir.Preload` or `goh.MemDir.Load`:
+
+	var han = goh.MustMemDir(goh.MemDir{
+		Dir:  goh.Dir{Path: `static`, Filter: goh.AllowExts{`.html`, `.css`}},
+		Gzip: true,
+	}.Load())
+
+Files are loaded according to the settings of `.Dir`, such as `.Path`,
+`.Filter`, `.MaxSize`, and `.AllowDotfiles`. Requests are resolved according to
+`.Prefix`, `.Rewrite`, and `.Index`. `.Fallback` and `.NotFound` are used as in
+`goh.Dir`. Directory listings and `.OnFile` are not supported.
+
+Each file is served as `goh.Bytes` with the status, header, and err func of
+`.Dir`, plus headers "Content-Type" (always detected), "Etag" (content hash),
+"Last-Modified", and "Cache-Control" when `.Dir.CacheControl` matches.
+Conditional requests are always supported. When `.Gzip` is true, compressible
+files are additionally gzipped at load time, and the compressed variant is
+served to clients that accept it. When `.ContentDigest` is true, each variant
+additionally has the "Content-Digest" header of its own body, computed at load
+time, see `goh.ContentDigest`.
+*/
+type MemDir struct {
+	Dir           Dir
+	Gzip          bool
+	ContentDigest bool
+	Files         map[string]MemFile
+}
+
+/*
+Returns a copy with `.Files` populated by walking `.Dir.Path`. Keys are
+slash-separated paths relative to `.Dir.Path`, such as "one/two.css".
+*/
+func (self MemDir) Load() (MemDir, error) {
+	files := map[string]MemFile{}
+
+	err := self.walk(func(filePath, rel string, info fs.FileInfo) error {
+		body, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[rel] = self.memFile(rel, body, info.ModTime())
+		return nil
+	})
+
+	self.Files = files
+	return self, err
+}
+
+// Walks the servable files in `.Dir.Path`, as determined by the dir settings.
+func (self MemDir) walk(fun func(filePath, rel string, info fs.FileInfo) error) error {
+	root := self.Dir.Path
+
+	return filepath.WalkDir(root, func(filePath string, ent fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel != `.` && !self.Dir.AllowDotfiles && strings.HasPrefix(ent.Name(), `.`) {
+			if ent.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ent.IsDir() || !self.Dir.Allow(filePath) {
+			return nil
+		}
+
+		info, err := ent.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || !self.Dir.File(filePath).AllowSize(info.Size()) {
+			return nil
+		}
+		return fun(filePath, rel, info)
+	})
+}
+
+/*
+Returns a hash of the paths, sizes, and modification times of the servable
+files in `.Dir.Path`. Used by `goh.LiveDir` to detect changes without reading
+file contents.
+*/
+func (self MemDir) Signature() (uint64, error) {
+	hash := fnv.New64a()
+	err := self.walk(func(_, rel string, info fs.FileInfo) error {
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\x00", rel, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hash.Sum64(), err
+}
+
+func (self MemDir) memFile(rel string, body []byte, mtime time.Time) (out MemFile) {
+	dir := self.Dir
+	header := cloneHeader(dir.Header)
+
+	conType := mime.TypeByExtension(path.Ext(rel))
+	if conType == `` {
+		conType = http.DetectContentType(body)
+	}
+	if header.Get(HeadType) == `` {
+		header.Set(HeadType, conType)
+	}
+
+	sum := sha256.Sum256(body)
+	etag := base64.RawURLEncoding.EncodeToString(sum[:12])
+	header.Set(`Etag`, strconv.Quote(etag))
+	if !mtime.IsZero() {
+		header.Set(`Last-Modified`, mtime.UTC().Format(http.TimeFormat))
+	}
+	setNonEmpty(header, `Cache-Control`, dir.CacheControl[strings.ToLower(path.Ext(rel))])
+
+	out.ModTime = mtime.UTC().Truncate(time.Second)
+	out.Plain = Bytes{Status: dir.Status, Header: header, ErrFunc: dir.ErrFunc, AppendHeader: dir.AppendHeader, Body: body}
+	if self.ContentDigest {
+		header.Set(HeadContentDigest, ContentDigest(body))
+	}
+
+	if !self.Gzip || !isCompressible(conType) {
+		return
+	}
+
+	zipped := gzipBytes(body)
+	if len(zipped) >= len(body) {
+		return
+	}
+
+	header = header.Clone()
+	header.Set(`Etag`, strconv.Quote(etag+`-gzip`))
+	header.Set(`Content-Encoding`, `gzip`)
+	if self.ContentDigest {
+		header.Set(HeadContentDigest, ContentDigest(zipped))
+	}
+	AddVary(header, `Accept-Encoding`)
+	AddVary(out.Plain.Header, `Accept-Encoding`)
+	out.Gzipped = Bytes{Status: dir.Status, Header: header, ErrFunc: dir.ErrFunc, AppendHeader: dir.AppendHeader, Body: zipped}
+	return
+}
+
+// Implement `http.Handler`.
+func (self MemDir) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.MemDir`, self.serveHTTP)
+}
+
+func (self MemDir) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if !self.ServedHTTP(rew, req) {
+		self.Dir.notFound().ServeHTTP(rew, req)
+	}
+}
+
+/*
+Implement `HttpHandlerOpt`. If possible, serves the requested file or
+`.Dir.Fallback`, and returns true. Otherwise returns false.
+*/
+func (self MemDir) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.MemDir`, self.servedHTTP)
+}
+
+func (self MemDir) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return servedHTTP(self.HanOpt(req), rew, req)
+}
+
+// Conforms to `goh.Han`. Always returns non-nil.
+func (self MemDir) Han(req *http.Request) http.Handler {
+	return orNotFound(self.HanOpt(req), self.Dir.notFound())
+}
+
+/*
+Conforms to `goh.Han`. If the requested file is not found, returns
+`.Dir.Fallback`, which may be nil.
+*/
+func (self MemDir) HanOpt(req *http.Request) http.Handler {
+	file, ok := self.Resolve(req)
+	if ok {
+		return file
+	}
+	return self.Dir.Fallback
+}
+
+/*
+Finds the preloaded file for the given request, using the same path resolution
+rules as `goh.Dir`.
+*/
+func (self MemDir) Resolve(req *http.Request) (MemFile, bool) {
+	reqPath, ok := self.Dir.reqPath(req)
+	if !ok {
+		return MemFile{}, false
+	}
+
+	if reqPath != `` && !strings.HasSuffix(reqPath, `/`) {
+		file, ok := self.Files[reqPath]
+		if ok {
+			return file, true
+		}
+	}
+
+	file, ok := self.Files[path.Join(reqPath, self.Dir.index())]
+	return file, ok
+}
+
+/*
+Single file preloaded by `goh.MemDir`. Serves `.Gzipped` to clients that accept
+gzip encoding, if available, and `.Plain` otherwise. Responds with 304 to
+matching conditional requests.
+*/
+type MemFile struct {
+	Plain   Bytes
+	Gzipped Bytes
+	ModTime time.Time
+}
+
+// Implement `http.Handler`.
+func (self MemFile) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.MemFile`, self.serveHTTP)
+}
+
+func (self MemFile) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	out := self.Variant(req)
+	if notModified(req, out.Header.Get(`Etag`), self.ModTime) {
+		out.Head().writeHeaders(rew)
+		rew.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if !out.NoContentLength {
+		rew.Header().Set(`Content-Length`, strconv.Itoa(len(out.Body)))
+	}
+	out.ServeHTTP(rew, req)
+}
+
+// Conforms to `goh.Han`.
+func (self MemFile) Han(*http.Request) http.Handler { return self }
+
+// Returns `.Gzipped` if available and accepted by the client, otherwise `.Plain`.
+func (self MemFile) Variant(req *http.Request) Bytes {
+	if self.Gzipped.Body != nil && req != nil && acceptsEncoding(req, `gzip`) {
+		return self.Gzipped
+	}
+	return self.Plain
+}
+
+/*
+Panics if the error is non-nil, otherwise returns the value. Intended for
+initializing global variables, see `goh.MemDir`.
+*/
+func MustMemDir(val MemDir, err error) MemDir {
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+/*
+True if the request's "Accept-Encoding" header includes the given encoding, or
+"*", with a non-zero quality value. An explicit entry for the encoding takes
+priority over "*".
+*/
+func acceptsEncoding(req *http.Request, enc string) bool {
+	enc = strings.ToLower(enc)
+	var star *acceptedVal
+
+	for _, val := range acceptedVals(req, `Accept-Encoding`) {
+		if val.name == enc {
+			return val.quality > 0
+		}
+		if val.name == `*` && star == nil {
+			val := val
+			star = &val
+		}
+	}
+	return star != nil && star.quality > 0
+}
+
+// Equivalent of `strings.Cut` for compatibility with older Go versions.
+func cutStr(src, sep string) (string, string) {
+	ind := strings.Index(src, sep)
+	if ind < 0 {
+		return src, ``
+	}
+	return src[:ind], src[ind+len(sep):]
+}
+
+func isCompressible(conType string) bool {
+	conType, _ = cutStr(conType, `;`)
+	return strings.HasPrefix(conType, `text/`) ||
+		strings.HasSuffix(conType, `json`) ||
+		strings.HasSuffix(conType, `xml`) ||
+		conType == `application/javascript` ||
+		conType == `image/svg+xml` ||
+		conType == `application/wasm`
+}
+
+func gzipBytes(src []byte) []byte {
+	var buf bytes.Buffer
+	writer, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	_, _ = writer.Write(src)
+	_ = writer.Close()
+	return buf.Bytes()
+}
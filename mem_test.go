@@ -0,0 +1,145 @@
+package goh
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(MemDir{})
+	_ = HttpHandlerOpt(MemDir{})
+	_ = Han(MemDir{}.Han)
+	_ = Han(MemDir{}.HanOpt)
+	_ = http.Handler(MemFile{})
+	_ = Han(MemFile{}.Han)
+)
+
+func memDir(t testing.TB) string {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `index.html`), `<p>index</p>`)
+	writeFile(filepath.Join(root, `one/two.css`), strings.Repeat(`body {} `, 64))
+	writeFile(filepath.Join(root, `one/index.html`), `<p>one</p>`)
+	writeFile(filepath.Join(root, `.env`), `secret`)
+	writeFile(filepath.Join(root, `.git/config`), `secret`)
+	writeFile(filepath.Join(root, `big.txt`), strings.Repeat(`big`, 400))
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	try(os.Chtimes(filepath.Join(root, `one/two.css`), mtime, mtime))
+	return root
+}
+
+func TestMemDir(t *testing.T) {
+	root := memDir(t)
+
+	mem, err := Dir{Path: root, MaxSize: 1024}.Preload()
+	try(err)
+
+	eq(t, 3, len(mem.Files))
+	_, ok := mem.Files[`one/two.css`]
+	eq(t, true, ok)
+
+	test := func(mem MemDir, path, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		mem.ServeHTTP(rew, pathReq(path))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, exp, rew.Body.String())
+	}
+
+	test(mem, `/`, `<p>index</p>`)
+	test(mem, `/one`, `<p>one</p>`)
+	test(mem, `/one/`, `<p>one</p>`)
+	test(mem, `/one/index.html`, `<p>one</p>`)
+
+	for _, path := range []string{`/.env`, `/.git/config`, `/big.txt`, `/missing`, `/../index.html`} {
+		rew := ht.NewRecorder()
+		mem.ServeHTTP(rew, pathReq(path))
+		eq(t, http.StatusNotFound, rew.Code)
+		eq(t, nil, mem.HanOpt(pathReq(path)))
+	}
+
+	t.Run(`headers`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		mem.ServeHTTP(rew, pathReq(`/one/two.css`))
+
+		head := rew.Result().Header
+		eq(t, `text/css; charset=utf-8`, head.Get(HeadType))
+		eq(t, `512`, head.Get(`Content-Length`))
+		eq(t, `Thu, 02 Jan 2020 03:04:05 GMT`, head.Get(`Last-Modified`))
+		eq(t, true, strings.HasPrefix(head.Get(`Etag`), `"`))
+		eq(t, ``, head.Get(`Content-Encoding`))
+	})
+
+	t.Run(`conditional`, func(t *testing.T) {
+		file, ok := mem.Resolve(pathReq(`/one/two.css`))
+		eq(t, true, ok)
+
+		req := ht.NewRequest(http.MethodGet, `/one/two.css`, nil)
+		req.Header.Set(`If-None-Match`, file.Plain.Header.Get(`Etag`))
+
+		rew := ht.NewRecorder()
+		mem.ServeHTTP(rew, req)
+		eq(t, http.StatusNotModified, rew.Code)
+		eq(t, ``, rew.Body.String())
+	})
+
+	t.Run(`gzip`, func(t *testing.T) {
+		mem, err := MemDir{Dir: Dir{Path: root}, Gzip: true}.Load()
+		try(err)
+
+		req := ht.NewRequest(http.MethodGet, `/one/two.css`, nil)
+		req.Header.Set(`Accept-Encoding`, `br, gzip;q=0.5`)
+
+		rew := ht.NewRecorder()
+		mem.ServeHTTP(rew, req)
+
+		head := rew.Result().Header
+		eq(t, `gzip`, head.Get(`Content-Encoding`))
+		eq(t, `Accept-Encoding`, head.Get(`Vary`))
+		eq(t, strings.Repeat(`body {} `, 64), gunzip(rew.Body.Bytes()))
+
+		req.Header.Set(`Accept-Encoding`, `gzip;q=0`)
+		rew = ht.NewRecorder()
+		mem.ServeHTTP(rew, req)
+		eq(t, ``, rew.Result().Header.Get(`Content-Encoding`))
+		eq(t, `Accept-Encoding`, rew.Result().Header.Get(`Vary`))
+		eq(t, strings.Repeat(`body {} `, 64), rew.Body.String())
+
+		file, _ := mem.Resolve(pathReq(`/index.html`))
+		eq(t, []byte(nil), file.Gzipped.Body)
+
+		accepts := func(head string) bool {
+			req := ht.NewRequest(http.MethodGet, `/`, nil)
+			req.Header.Set(`Accept-Encoding`, head)
+			return acceptsEncoding(req, `gzip`)
+		}
+		eq(t, true, accepts(`*`))
+		eq(t, true, accepts(`GZIP;q=0.1`))
+		eq(t, false, accepts(`*;q=0`))
+		eq(t, false, accepts(`gzip;q=0, *`))
+		eq(t, true, accepts(`br;q=0, *;q=0.5`))
+		eq(t, false, accepts(`br`))
+	})
+
+	t.Run(`fallback`, func(t *testing.T) {
+		mem, err := Dir{Path: root, Fallback: StringOk(`fallback`)}.Preload()
+		try(err)
+		test(mem, `/missing`, `fallback`)
+	})
+}
+
+func gunzip(src []byte) string {
+	reader, err := gzip.NewReader(bytes.NewReader(src))
+	try(err)
+	out, err := io.ReadAll(reader)
+	try(err)
+	return string(out)
+}
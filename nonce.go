@@ -0,0 +1,142 @@
+package goh
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Placeholder replaced with the nonce in `goh.CspNonce.Policy`.
+const CspNoncePlaceholder = `{nonce}`
+
+/*
+Default policy of `goh.CspNonce`: a "strict" content security policy which
+allows only scripts with the nonce, and scripts loaded by them.
+*/
+const DefaultCspNoncePolicy = `script-src 'nonce-{nonce}' 'strict-dynamic'; object-src 'none'; base-uri 'none'`
+
+type cspNonceKey struct{}
+
+/*
+HTTP handler that generates a random nonce for each request, adds it to the
+"Content-Security-Policy" header, and serves the inner handler. Occurrences of
+`goh.CspNoncePlaceholder` in `.Policy` are replaced with the nonce. Empty
+`.Policy` means `goh.DefaultCspNoncePolicy`. When `.ReportOnly` is true, uses
+the "Content-Security-Policy-Report-Only" header instead. When `.Handler` is
+nil, responds with `goh.NotFound`.
+
+The nonce is stored in the request context, where it can be obtained via
+`goh.CspNonceOf`, and rendered into HTML elements:
+
+	<script nonce="{{cspNonce}}">...</script>
+
+See `goh.CspNonceFuncMap`. Since the nonce must be different for every
+response, responses using it must not be cached by shared caches.
+*/
+type CspNonce struct {
+	Policy     string
+	ReportOnly bool
+	Handler    http.Handler
+}
+
+// Implement `http.Handler`.
+func (self CspNonce) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	nonce := NewCspNonce()
+	rew.Header().Set(self.headerKey(), self.PolicyWith(nonce))
+	req = req.WithContext(context.WithValue(req.Context(), cspNonceKey{}, nonce))
+	observe(rew, req, `goh.CspNonce`, orNotFound(self.Handler).ServeHTTP)
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self CspNonce) Han(*http.Request) http.Handler { return self }
+
+// Returns a modified version with the given inner handler.
+func (self CspNonce) With(val http.Handler) CspNonce {
+	self.Handler = val
+	return self
+}
+
+// Returns the policy with the placeholder replaced by the given nonce.
+func (self CspNonce) PolicyWith(nonce string) string {
+	return strings.ReplaceAll(orStr(self.Policy, DefaultCspNoncePolicy), CspNoncePlaceholder, nonce)
+}
+
+func (self CspNonce) headerKey() string {
+	if self.ReportOnly {
+		return `Content-Security-Policy-Report-Only`
+	}
+	return `Content-Security-Policy`
+}
+
+/*
+Returns the nonce assigned by `goh.CspNonce`, or an empty string if the
+request wasn't served by it.
+*/
+func CspNonceOf(req *http.Request) string {
+	if req == nil {
+		return ``
+	}
+	val, _ := req.Context().Value(cspNonceKey{}).(string)
+	return val
+}
+
+/*
+Returns template functions for the given request. Currently includes only
+"cspNonce", which returns `goh.CspNonceOf`. Since `html/template` requires
+functions to be defined before parsing, templates should be parsed with a nil
+request:
+
+	var tmpl = template.Must(template.New(``).Funcs(goh.CspNonceFuncMap(nil)).Parse(src))
+
+With a nil request, "cspNonce" returns a random placeholder, generated once
+per process. `goh.Template` replaces it with the nonce of the current request
+after rendering, which allows to share one template between requests. When
+rendering templates manually, either replace the placeholder via
+`goh.ReplaceCspNonce`, or clone the template for each request before its first
+execution, and define the function for that request:
+
+	func render(out io.Writer, req *http.Request) error {
+		tmpl, err := tmpl.Clone()
+		if err != nil {
+			return err
+		}
+		return tmpl.Funcs(goh.CspNonceFuncMap(req)).Execute(out, nil)
+	}
+*/
+func CspNonceFuncMap(req *http.Request) template.FuncMap {
+	if req == nil {
+		return template.FuncMap{`cspNonce`: func() string { return cspNonceMarker }}
+	}
+	return template.FuncMap{`cspNonce`: func() string { return CspNonceOf(req) }}
+}
+
+/*
+Replaces the placeholders rendered by "cspNonce", see `goh.CspNonceFuncMap`,
+with the nonce of the given request, or with an empty string if the request
+has no nonce.
+*/
+func ReplaceCspNonce(src []byte, req *http.Request) []byte {
+	return bytes.ReplaceAll(src, []byte(cspNonceMarker), []byte(CspNonceOf(req)))
+}
+
+/*
+Placeholder rendered by "cspNonce" in shared templates. Random, and thus
+unknown to clients, which prevents user-supplied content from obtaining the
+nonce by including the placeholder. Consists of hex characters, which are never
+escaped by `html/template`.
+*/
+var cspNonceMarker = `goh_csp_nonce_` + NewRequestId()
+
+/*
+Returns a random nonce: 128 bits encoded as unpadded base64url, which is never
+escaped by `html/template`.
+*/
+func NewCspNonce() string {
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	return base64.RawURLEncoding.EncodeToString(buf[:])
+}
@@ -0,0 +1,59 @@
+package goh
+
+import (
+	"html/template"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var (
+	_ = http.Handler(CspNonce{})
+	_ = Han(CspNonce{}.Han)
+)
+
+func TestCspNonce(t *testing.T) {
+	tmpl := template.Must(template.New(``).Funcs(CspNonceFuncMap(nil)).Parse(`<script nonce="{{cspNonce}}"></script>`))
+
+	var nonce string
+	inner := http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+		nonce = CspNonceOf(req)
+
+		tmpl, err := tmpl.Clone()
+		try(err)
+		try(tmpl.Funcs(CspNonceFuncMap(req)).Execute(rew, nil))
+	})
+
+	t.Run(`default`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		CspNonce{}.With(inner).ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, 22, len(nonce))
+		eq(t, `<script nonce="`+nonce+`"></script>`, rew.Body.String())
+		eq(t, strings.ReplaceAll(DefaultCspNoncePolicy, CspNoncePlaceholder, nonce), rew.Header().Get(`Content-Security-Policy`))
+	})
+
+	t.Run(`custom`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		CspNonce{
+			Policy:     `script-src 'nonce-{nonce}'; style-src 'nonce-{nonce}'`,
+			ReportOnly: true,
+			Handler:    inner,
+		}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, ``, rew.Header().Get(`Content-Security-Policy`))
+		eq(t, `script-src 'nonce-`+nonce+`'; style-src 'nonce-`+nonce+`'`, rew.Header().Get(`Content-Security-Policy-Report-Only`))
+	})
+
+	t.Run(`unique`, func(t *testing.T) {
+		han := CspNonce{Handler: inner}
+		han.ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+		prev := nonce
+		han.ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+		eq(t, false, prev == nonce)
+	})
+
+	eq(t, ``, CspNonceOf(nil))
+	eq(t, ``, CspNonceOf(pathReq(`/`)))
+}
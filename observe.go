@@ -0,0 +1,472 @@
+package goh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/*
+Describes a response served by a Goh handler. Passed to `goh.OnServe` and
+`goh.Observe.OnServe` after the response is complete.
+
+`.Handler` is the type of the Goh handler that started writing the response,
+such as "goh.Json" or "goh.NotFound". When a handler such as `goh.Dir`
+delegates to `goh.File`, this is "goh.File". When nothing was written, this is
+the type of the outermost Goh handler. `.Status` is the sent status, with the
+implicit 200 made explicit. `.Written` counts body bytes.
+
+When the handler panics, the response is still reported, with the recovered
+value in `.Panic`, after which the panic continues. If nothing was sent yet,
+`.Status` is 500, although the server usually aborts the response instead.
+*/
+type ServeInfo struct {
+	Req      *http.Request
+	Handler  string
+	Status   int
+	Written  int64
+	Duration time.Duration
+	Panic    interface{}
+}
+
+/*
+Optional hook invoked after every response served by a Goh handler. Nested Goh
+handlers, such as `goh.File` served by `goh.Dir`, report only once. When no
+hooks are set (default), Goh handlers use the response writer as-is, without
+any overhead. Should be set once, at startup. Example usage:
+
+	goh.OnServe = func(info goh.ServeInfo) {
+		log.Println(info.Req.URL.Path, info.Handler, info.Status, info.Duration)
+	}
+
+For hooks specific to one handler, see `goh.Observe`.
+*/
+var OnServe func(ServeInfo)
+
+/*
+HTTP handler that observes the responses of the inner handler, invoking its
+own hooks in addition to the package-level hooks such as `goh.OnServe`. The
+inner handler doesn't have to be a Goh handler.
+*/
+type Observe struct {
+	Handler   http.Handler
+	OnServe   func(ServeInfo)
+	AccessLog func(AccessEntry)
+}
+
+// Implement `http.Handler`.
+func (self Observe) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	han := orNotFound(self.Handler)
+
+	obs := observerOf(req)
+	if obs != nil {
+		obs.hook(self.onServe)
+		han.ServeHTTP(rew, req)
+		return
+	}
+
+	obs = newObserver(rew, req, ``)
+	obs.hook(self.onServe)
+	defer func() { obs.finish(recover()) }()
+	han.ServeHTTP(obs, obs.inner)
+}
+
+func (self Observe) onServe(info ServeInfo) {
+	if self.OnServe != nil {
+		self.OnServe(info)
+	}
+	if self.AccessLog != nil {
+		self.AccessLog(AccessEntryOf(info))
+	}
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Observe) Han(*http.Request) http.Handler { return self }
+
+/*
+Interface for tracing the responses of Goh handlers, for example via
+OpenTelemetry, without Goh depending on any tracing library. Set via
+`goh.SetTracer`.
+
+`.StartSpan` is called before serving a request, with the type of the
+outermost Goh handler, which is empty for `goh.Observe`. It may return a
+request with a modified context, for example carrying a span, which is then
+passed to the handler; a nil request is ignored. The returned function, if
+any, is called after the response is complete, and ends the span. When an
+optional handler such as `goh.Dir.ServedHTTP` declines the request, the
+function receives `.Status` 0.
+*/
+type Tracer interface {
+	StartSpan(req *http.Request, handler string) (*http.Request, func(ServeInfo))
+}
+
+var tracer Tracer
+
+/*
+Sets the tracer used by all Goh handlers. Nil disables tracing. Should be
+called once, at startup.
+*/
+func SetTracer(val Tracer) { tracer = val }
+
+/*
+Interface for collecting response metrics, for example via Prometheus or
+statsd counters. Set via `goh.SetMetrics`. `.ObserveResponse` is called once
+after each response served by a Goh handler, with the same data as
+`goh.ServeInfo`, where "kind" is `goh.ServeInfo.Handler`.
+*/
+type Metrics interface {
+	ObserveResponse(status int, bytes int64, dur time.Duration, kind string)
+}
+
+var metrics Metrics
+
+/*
+Sets the metrics collector used by all Goh handlers. Nil disables metrics.
+Should be called once, at startup.
+*/
+func SetMetrics(val Metrics) { metrics = val }
+
+/*
+Optional access-log hook invoked once after every response served by a Goh
+handler, like `goh.OnServe`. For hooks specific to one handler, see
+`goh.Observe`. Example usage:
+
+	goh.AccessLog = goh.AccessLogTo(os.Stderr)
+*/
+var AccessLog func(AccessEntry)
+
+/*
+Access-log entry describing one response. Passed to `goh.AccessLog` and
+`goh.Observe.AccessLog`.
+*/
+type AccessEntry struct {
+	Method    string
+	Path      string
+	Status    int
+	Bytes     int64
+	Duration  time.Duration
+	UserAgent string
+	Handler   string
+	RequestId string
+}
+
+// Creates an access-log entry from the given response info.
+func AccessEntryOf(info ServeInfo) AccessEntry {
+	out := AccessEntry{
+		Status:   info.Status,
+		Bytes:    info.Written,
+		Duration: info.Duration,
+		Handler:  info.Handler,
+	}
+
+	req := info.Req
+	if req != nil {
+		out.Method = req.Method
+		out.UserAgent = req.UserAgent()
+		out.RequestId = RequestIdOf(req)
+		if req.URL != nil {
+			out.Path = req.URL.Path
+		}
+	}
+	return out
+}
+
+/*
+Formats the entry as a single line without a trailing newline, for example:
+
+	GET /index.html 200 1024 1.5ms "Mozilla/5.0"
+
+When the entry has a request ID, it's appended at the end.
+*/
+func (self AccessEntry) String() string {
+	out := fmt.Sprintf(
+		`%v %v %v %v %v %q`,
+		self.Method, self.Path, self.Status, self.Bytes, self.Duration, self.UserAgent,
+	)
+	if self.RequestId != `` {
+		out += ` ` + self.RequestId
+	}
+	return out
+}
+
+/*
+Returns an access-log hook that writes each entry to the given writer, one per
+line, in the format of `goh.AccessEntry.String`. Concurrent writes are
+serialized. Errors are ignored.
+*/
+func AccessLogTo(out io.Writer) func(AccessEntry) {
+	var lock sync.Mutex
+	return func(val AccessEntry) {
+		line := val.String() + "\n"
+		lock.Lock()
+		defer lock.Unlock()
+		_, _ = io.WriteString(out, line)
+	}
+}
+
+// True if any package-level hooks are set.
+func observing() bool {
+	return OnServe != nil || AccessLog != nil || tracer != nil || metrics != nil
+}
+
+/*
+Serves the request via the given function, observing the response when any
+hooks are set. Used by Goh handlers to implement `http.Handler`.
+
+Nested Goh handlers find the outer observer via the request context rather
+than the response writer, which may be wrapped by intermediate handlers such
+as `goh.SizeGuard`, and report only via the outer observer.
+*/
+func observe(
+	rew http.ResponseWriter, req *http.Request, kind string,
+	fun func(http.ResponseWriter, *http.Request),
+) {
+	obs := observerOf(req)
+	if obs != nil {
+		defer obs.leave(obs.enter(kind))
+		fun(rew, req)
+		return
+	}
+
+	if !observing() {
+		fun(rew, req)
+		return
+	}
+
+	obs = newObserver(rew, req, kind)
+	defer func() { obs.finish(recover()) }()
+	fun(obs, obs.inner)
+}
+
+/*
+Same as `goh.observe`, but for `goh.HttpHandlerOpt`. When the function
+declines the request, only the tracer span is ended.
+*/
+func observed(
+	rew http.ResponseWriter, req *http.Request, kind string,
+	fun func(http.ResponseWriter, *http.Request) bool,
+) bool {
+	obs := observerOf(req)
+	if obs != nil {
+		defer obs.leave(obs.enter(kind))
+		return fun(rew, req)
+	}
+
+	if !observing() {
+		return fun(rew, req)
+	}
+
+	obs = newObserver(rew, req, kind)
+	var out, returned bool
+	defer func() {
+		val := recover()
+		if returned && !out {
+			obs.declined()
+		} else {
+			obs.finish(val)
+		}
+	}()
+
+	out = fun(obs, obs.inner)
+	returned = true
+	return out
+}
+
+/*
+Response writer used by Goh handlers when any hooks are set. Records the sent
+status and counts body bytes. Preserves `http.Flusher` and `io.ReaderFrom`, and
+supports `http.ResponseController` via `.Unwrap`.
+
+Nested handlers may run in another goroutine, for example under `goh.Timeout`,
+so the mutable state is guarded by a lock.
+*/
+type observer struct {
+	http.ResponseWriter
+	req     *http.Request
+	inner   *http.Request
+	end     func(ServeInfo)
+	start   time.Time
+	lock    sync.Mutex
+	current string
+	hooks   []func(ServeInfo)
+	info    ServeInfo
+}
+
+type observerKey struct{}
+
+// Returns the observer of an outer Goh handler serving the request, if any.
+func observerOf(req *http.Request) *observer {
+	if req == nil {
+		return nil
+	}
+	val, _ := req.Context().Value(observerKey{}).(*observer)
+	return val
+}
+
+func newObserver(rew http.ResponseWriter, req *http.Request, kind string) *observer {
+	var end func(ServeInfo)
+	if tracer != nil {
+		var out *http.Request
+		out, end = tracer.StartSpan(req, kind)
+		if out != nil {
+			req = out
+		}
+	}
+
+	obs := &observer{
+		ResponseWriter: rew,
+		req:            req,
+		inner:          req,
+		end:            end,
+		start:          time.Now(),
+		current:        kind,
+	}
+	if req != nil {
+		obs.inner = req.WithContext(context.WithValue(req.Context(), observerKey{}, obs))
+	}
+	return obs
+}
+
+func (self *observer) enter(kind string) string {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	prev := self.current
+	self.current = kind
+	return prev
+}
+
+func (self *observer) leave(prev string) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.current = prev
+}
+
+func (self *observer) hook(fun func(ServeInfo)) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.hooks = append(self.hooks, fun)
+}
+
+func (self *observer) WriteHeader(status int) {
+	// Informational statuses such as 103 may precede the actual status.
+	if status >= http.StatusOK {
+		self.begin(status)
+	}
+	self.ResponseWriter.WriteHeader(status)
+}
+
+func (self *observer) Write(src []byte) (int, error) {
+	self.begin(http.StatusOK)
+	size, err := self.ResponseWriter.Write(src)
+	self.wrote(int64(size))
+	return size, err
+}
+
+func (self *observer) ReadFrom(src io.Reader) (int64, error) {
+	self.begin(http.StatusOK)
+
+	var size int64
+	var err error
+	dst, ok := self.ResponseWriter.(io.ReaderFrom)
+	if ok {
+		size, err = dst.ReadFrom(src)
+	} else {
+		size, err = io.Copy(self.ResponseWriter, src)
+	}
+
+	self.wrote(size)
+	return size, err
+}
+
+func (self *observer) Flush() {
+	flusher, _ := self.ResponseWriter.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+func (self *observer) Unwrap() http.ResponseWriter { return self.ResponseWriter }
+
+func (self *observer) begin(status int) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if self.info.Status == 0 {
+		self.info.Status = status
+		self.info.Handler = self.current
+	}
+}
+
+func (self *observer) wrote(size int64) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.info.Written += size
+}
+
+func (self *observer) done() {
+	self.begin(http.StatusOK)
+
+	info := self.report()
+	if self.end != nil {
+		self.end(info)
+	}
+	if metrics != nil {
+		metrics.ObserveResponse(info.Status, info.Written, info.Duration, info.Handler)
+	}
+
+	self.lock.Lock()
+	hooks := self.hooks
+	self.lock.Unlock()
+
+	for _, fun := range hooks {
+		if fun != nil {
+			fun(info)
+		}
+	}
+	if OnServe != nil {
+		OnServe(info)
+	}
+	if AccessLog != nil {
+		AccessLog(AccessEntryOf(info))
+	}
+}
+
+/*
+Reports the response via `.done`, including the panic, if any, which is then
+propagated.
+*/
+func (self *observer) finish(val interface{}) {
+	if val != nil {
+		self.lock.Lock()
+		self.info.Panic = val
+		self.lock.Unlock()
+		self.begin(http.StatusInternalServerError)
+	}
+
+	self.done()
+	if val != nil {
+		panic(val)
+	}
+}
+
+// Ends the span, if any, without invoking other hooks.
+func (self *observer) declined() {
+	if self.end != nil {
+		self.end(self.report())
+	}
+}
+
+func (self *observer) report() ServeInfo {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	info := self.info
+	if info.Handler == `` {
+		info.Handler = self.current
+	}
+	info.Req = self.req
+	info.Duration = time.Since(self.start)
+	return info
+}
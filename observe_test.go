@@ -0,0 +1,258 @@
+package goh
+
+import (
+	"context"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(Observe{})
+	_ = Han(Observe{}.Han)
+	_ = http.Flusher((*observer)(nil))
+	_ = io.ReaderFrom((*observer)(nil))
+)
+
+// Sets `goh.OnServe` for the duration of the test, collecting reports.
+func collectServeInfo(t testing.TB) *[]ServeInfo {
+	var out []ServeInfo
+	prev := OnServe
+	OnServe = func(info ServeInfo) { out = append(out, info) }
+	t.Cleanup(func() { OnServe = prev })
+	return &out
+}
+
+func serveInfo(val ServeInfo) ServeInfo {
+	val.Req = nil
+	val.Duration = 0
+	return val
+}
+
+func TestOnServe(t *testing.T) {
+	t.Run(`bytes`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		req := pathReq(`/`)
+		StringWith(http.StatusCreated, `hello world`).ServeHTTP(ht.NewRecorder(), req)
+
+		eq(t, 1, len(*infos))
+		eq(t, true, (*infos)[0].Req == req)
+		eq(t, ServeInfo{Handler: `goh.String`, Status: http.StatusCreated, Written: 11}, serveInfo((*infos)[0]))
+	})
+
+	t.Run(`implicit status`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		Bytes{}.ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+		eq(t, []ServeInfo{{Handler: `goh.Bytes`, Status: http.StatusOK}}, []ServeInfo{serveInfo((*infos)[0])})
+	})
+
+	t.Run(`nested`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		rew := ht.NewRecorder()
+		Chain{Dir{Path: `.`}}.ServeHTTP(rew, pathReq(`/readme.md`))
+
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, 1, len(*infos))
+		eq(t, ServeInfo{Handler: `goh.File`, Status: http.StatusOK, Written: int64(rew.Body.Len())}, serveInfo((*infos)[0]))
+	})
+
+	t.Run(`nested in wrapper`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		rew := ht.NewRecorder()
+		SizeGuard{Max: 64, Handler: StringOk(`one`)}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, `one`, rew.Body.String())
+		eq(t, 1, len(*infos))
+		eq(t, ServeInfo{Handler: `goh.String`, Status: http.StatusOK, Written: 3}, serveInfo((*infos)[0]))
+	})
+
+	t.Run(`nested panic`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		inner := SizeGuard{Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(`fail`)
+		})}
+
+		Observe{Handler: http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+			func() {
+				defer func() { _ = recover() }()
+				inner.ServeHTTP(rew, req)
+			}()
+			rew.WriteHeader(http.StatusAccepted)
+		})}.ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+
+		eq(t, 1, len(*infos))
+		eq(t, ServeInfo{Status: http.StatusAccepted}, serveInfo((*infos)[0]))
+	})
+
+	t.Run(`panic`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		han := SizeGuard{Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(`fail`)
+		})}
+
+		func() {
+			defer func() { eq(t, `fail`, recover()) }()
+			han.ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+		}()
+
+		eq(t, 1, len(*infos))
+		eq(t, ServeInfo{Handler: `goh.SizeGuard`, Status: http.StatusInternalServerError, Panic: `fail`}, serveInfo((*infos)[0]))
+	})
+
+	t.Run(`fallback`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		Dir{Path: `.`}.ServeHTTP(ht.NewRecorder(), pathReq(`/missing`))
+
+		eq(t, 1, len(*infos))
+		eq(t, ServeInfo{Handler: `goh.NotFound`, Status: http.StatusNotFound}, serveInfo((*infos)[0]))
+	})
+
+	t.Run(`declined`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		eq(t, false, Dir{Path: `.`}.ServedHTTP(ht.NewRecorder(), pathReq(`/missing`)))
+		eq(t, 0, len(*infos))
+	})
+
+	t.Run(`error`, func(t *testing.T) {
+		infos := collectServeInfo(t)
+		Json{Body: func() {}}.ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+
+		eq(t, 1, len(*infos))
+		eq(t, `goh.Json`, (*infos)[0].Handler)
+		eq(t, http.StatusInternalServerError, (*infos)[0].Status)
+	})
+}
+
+func TestObserve(t *testing.T) {
+	global := collectServeInfo(t)
+	var local []ServeInfo
+
+	han := Observe{
+		Handler: http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+			rew.WriteHeader(http.StatusAccepted)
+			_, _ = io.WriteString(rew, `one`)
+			StringOk(`two`).ServeHTTP(rew, req)
+		}),
+		OnServe: func(info ServeInfo) { local = append(local, info) },
+	}
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, pathReq(`/`))
+
+	eq(t, `onetwo`, rew.Body.String())
+	eq(t, 1, len(local))
+	eq(t, ServeInfo{Status: http.StatusAccepted, Written: 6}, serveInfo(local[0]))
+	eq(t, 1, len(*global))
+	eq(t, local[0], (*global)[0])
+}
+
+type ctxKey string
+
+type testTracer struct {
+	started []string
+	ended   []ServeInfo
+}
+
+func (self *testTracer) StartSpan(req *http.Request, handler string) (*http.Request, func(ServeInfo)) {
+	self.started = append(self.started, handler)
+	ctx := context.WithValue(req.Context(), ctxKey(`span`), handler)
+	return req.WithContext(ctx), func(info ServeInfo) {
+		self.ended = append(self.ended, serveInfo(info))
+	}
+}
+
+func setTracer(t testing.TB, val Tracer) {
+	prev := tracer
+	SetTracer(val)
+	t.Cleanup(func() { SetTracer(prev) })
+}
+
+func TestTracer(t *testing.T) {
+	t.Run(`served`, func(t *testing.T) {
+		var tra testTracer
+		setTracer(t, &tra)
+
+		var span interface{}
+		han := ByMethod{http.MethodGet: http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+			span = req.Context().Value(ctxKey(`span`))
+			StringOk(`hello`).ServeHTTP(rew, req)
+		})}
+
+		req := ht.NewRequest(http.MethodGet, `/`, nil)
+		han.ServeHTTP(ht.NewRecorder(), req)
+
+		eq(t, `goh.ByMethod`, span)
+		eq(t, []string{`goh.ByMethod`}, tra.started)
+		eq(t, []ServeInfo{{Handler: `goh.String`, Status: http.StatusOK, Written: 5}}, tra.ended)
+	})
+
+	t.Run(`declined`, func(t *testing.T) {
+		var tra testTracer
+		setTracer(t, &tra)
+
+		eq(t, false, Dir{Path: `.`}.ServedHTTP(ht.NewRecorder(), ht.NewRequest(http.MethodGet, `/missing`, nil)))
+		eq(t, []string{`goh.Dir`}, tra.started)
+		eq(t, []ServeInfo{{Handler: `goh.Dir`}}, tra.ended)
+	})
+}
+
+type testMetric struct {
+	Status int
+	Bytes  int64
+	Kind   string
+}
+
+type testMetrics []testMetric
+
+func (self *testMetrics) ObserveResponse(status int, bytes int64, _ time.Duration, kind string) {
+	*self = append(*self, testMetric{status, bytes, kind})
+}
+
+func TestMetrics(t *testing.T) {
+	var out testMetrics
+	prev := metrics
+	SetMetrics(&out)
+	t.Cleanup(func() { SetMetrics(prev) })
+
+	Chain{Dir{Path: `.`}}.ServeHTTP(ht.NewRecorder(), pathReq(`/missing`))
+	JsonOk(`one`).ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+	eq(t, false, Dir{Path: `.`}.ServedHTTP(ht.NewRecorder(), pathReq(`/missing`)))
+
+	eq(t, testMetrics{
+		{http.StatusNotFound, 0, `goh.NotFound`},
+		{http.StatusOK, 6, `goh.Json`},
+	}, out)
+}
+
+func TestAccessLog(t *testing.T) {
+	var global []AccessEntry
+	prev := AccessLog
+	AccessLog = func(val AccessEntry) { global = append(global, val) }
+	t.Cleanup(func() { AccessLog = prev })
+
+	var buf strings.Builder
+	han := Observe{Handler: StringWith(http.StatusTeapot, `hello`), AccessLog: AccessLogTo(&buf)}
+
+	req := ht.NewRequest(http.MethodPost, `/one?two`, nil)
+	req.Header.Set(`User-Agent`, `test agent`)
+	han.ServeHTTP(ht.NewRecorder(), req)
+
+	eq(t, 1, len(global))
+	entry := global[0]
+	entry.Duration = 0
+	eq(t, AccessEntry{
+		Method:    http.MethodPost,
+		Path:      `/one`,
+		Status:    http.StatusTeapot,
+		Bytes:     5,
+		UserAgent: `test agent`,
+		Handler:   `goh.String`,
+	}, entry)
+
+	eq(t, `POST /one 418 5 0s "test agent"`, entry.String())
+	eq(t, true, strings.HasPrefix(buf.String(), `POST /one 418 5 `))
+	eq(t, true, strings.HasSuffix(buf.String(), " \"test agent\"\n"))
+}
@@ -0,0 +1,132 @@
+package goh
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Header used by `goh.Pagination` for the total count of items.
+const HeadTotalCount = `X-Total-Count`
+
+// Default value of `goh.Pagination.PageParam`.
+const DefaultPageParam = `page`
+
+// Default value of `goh.Pagination.LimitParam`.
+const DefaultLimitParam = `limit`
+
+/*
+Pagination metadata, which generates the RFC 8288 "Link" header with the
+relations "first", "prev", "next", "last", and the "X-Total-Count" header.
+Intended for attaching to any Goh handler:
+
+	func handler(req *http.Request) http.Handler {
+		users, total := findUsers(page, limit)
+		pag := goh.Pagination{Url: req.URL, Page: page, Limit: limit, Total: total}
+		return goh.Json{Header: pag.Header(), Body: users}
+	}
+
+Output for "/users?page=2&limit=10" with 35 items:
+
+	Link: </users?limit=10&page=1>; rel="first", </users?limit=10&page=1>; rel="prev", </users?limit=10&page=3>; rel="next", </users?limit=10&page=4>; rel="last"
+	X-Total-Count: 35
+
+`.Page` is 1-based; values below 1 are treated as 1. Links are built from
+`.Url`, replacing the query parameters `.PageParam` and `.LimitParam`,
+defaulting to `goh.DefaultPageParam` and `goh.DefaultLimitParam`, and
+preserving other parameters. When `.Url` is relative, such as `req.URL` of a
+server request, links are relative too. When `.Limit` is not positive, only
+"X-Total-Count" is generated.
+*/
+type Pagination struct {
+	Url        *url.URL
+	Page       int
+	Limit      int
+	Total      int
+	PageParam  string
+	LimitParam string
+}
+
+// Returns a new header with the pagination headers. See `goh.Pagination.Apply`.
+func (self Pagination) Header() http.Header {
+	out := http.Header{}
+	self.Apply(out)
+	return out
+}
+
+/*
+Sets the pagination headers in the given header. The "Link" header is appended
+to existing values, if any.
+*/
+func (self Pagination) Apply(head http.Header) {
+	head.Set(HeadTotalCount, strconv.Itoa(self.Total))
+
+	links := self.Links()
+	if len(links) > 0 {
+		head.Add(HeadLink, strings.Join(links, `, `))
+	}
+}
+
+/*
+Returns the individual "Link" header values, such as
+`</users?page=2>; rel="next"`.
+*/
+func (self Pagination) Links() []string {
+	if self.Limit <= 0 {
+		return nil
+	}
+
+	page := self.page()
+	last := self.LastPage()
+
+	out := []string{self.link(1, `first`)}
+	if page > 1 {
+		out = append(out, self.link(minInt(page-1, last), `prev`))
+	}
+	if page < last {
+		out = append(out, self.link(page+1, `next`))
+	}
+	return append(out, self.link(last, `last`))
+}
+
+// Returns the number of the last page, which is at least 1.
+func (self Pagination) LastPage() int {
+	if self.Limit <= 0 || self.Total <= 0 {
+		return 1
+	}
+	return (self.Total + self.Limit - 1) / self.Limit
+}
+
+func (self Pagination) page() int {
+	if self.Page < 1 {
+		return 1
+	}
+	return self.Page
+}
+
+func (self Pagination) link(page int, rel string) string {
+	return Link{Url: self.PageUrl(page), Rel: rel}.String()
+}
+
+// Returns the URL of the given page, as described in `goh.Pagination`.
+func (self Pagination) PageUrl(page int) string {
+	var out url.URL
+	if self.Url != nil {
+		out = *self.Url
+	}
+
+	query := out.Query()
+	query.Set(orStr(self.PageParam, DefaultPageParam), strconv.Itoa(page))
+	query.Set(orStr(self.LimitParam, DefaultLimitParam), strconv.Itoa(self.Limit))
+	out.RawQuery = query.Encode()
+	out.Fragment = ``
+	return out.String()
+}
+
+func minInt(one, two int) int {
+	if one < two {
+		return one
+	}
+	return two
+}
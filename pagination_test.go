@@ -0,0 +1,66 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestPagination(t *testing.T) {
+	reqUrl, err := url.Parse(`/users?page=2&limit=10&sort=name#top`)
+	try(err)
+
+	t.Run(`middle`, func(t *testing.T) {
+		head := Pagination{Url: reqUrl, Page: 2, Limit: 10, Total: 35}.Header()
+		eq(t, `35`, head.Get(HeadTotalCount))
+		eq(t, []string{`</users?limit=10&page=1&sort=name>; rel="first", </users?limit=10&page=1&sort=name>; rel="prev", </users?limit=10&page=3&sort=name>; rel="next", </users?limit=10&page=4&sort=name>; rel="last"`}, head.Values(`Link`))
+	})
+
+	test := func(val Pagination, exp ...string) {
+		t.Helper()
+		eq(t, exp, val.Links())
+	}
+
+	test(Pagination{Page: 1, Limit: 10, Total: 35},
+		`<?limit=10&page=1>; rel="first"`,
+		`<?limit=10&page=2>; rel="next"`,
+		`<?limit=10&page=4>; rel="last"`,
+	)
+
+	test(Pagination{Page: 4, Limit: 10, Total: 35},
+		`<?limit=10&page=1>; rel="first"`,
+		`<?limit=10&page=3>; rel="prev"`,
+		`<?limit=10&page=4>; rel="last"`,
+	)
+
+	test(Pagination{Page: 9, Limit: 10, Total: 35},
+		`<?limit=10&page=1>; rel="first"`,
+		`<?limit=10&page=4>; rel="prev"`,
+		`<?limit=10&page=4>; rel="last"`,
+	)
+
+	test(Pagination{Limit: 10},
+		`<?limit=10&page=1>; rel="first"`,
+		`<?limit=10&page=1>; rel="last"`,
+	)
+
+	test(Pagination{Page: 2, Total: 35})
+
+	abs, err := url.Parse(`https://example.com/users`)
+	try(err)
+	eq(t, `https://example.com/users?p=3&per=5`, Pagination{Url: abs, Limit: 5, PageParam: `p`, LimitParam: `per`}.PageUrl(3))
+
+	t.Run(`handler`, func(t *testing.T) {
+		head := http.Header{`Link`: {`</one>; rel="preload"`}}
+		Pagination{Url: reqUrl, Page: 1, Limit: 50, Total: 2}.Apply(head)
+
+		rew := ht.NewRecorder()
+		Json{Header: head, Body: []int{1, 2}}.ServeHTTP(rew, nil)
+		eq(t, `2`, rew.Header().Get(HeadTotalCount))
+		eq(t, []string{
+			`</one>; rel="preload"`,
+			`</users?limit=50&page=1&sort=name>; rel="first", </users?limit=50&page=1&sort=name>; rel="last"`,
+		}, rew.Header().Values(`Link`))
+	})
+}
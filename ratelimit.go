@@ -0,0 +1,83 @@
+package goh
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Header indicating when the client may retry, see `goh.RetryAfterDelay`.
+const HeadRetryAfter = `Retry-After`
+
+// Headers of rate limit information, as used by `goh.RateLimit`.
+const (
+	HeadRateLimitLimit     = `RateLimit-Limit`
+	HeadRateLimitRemaining = `RateLimit-Remaining`
+	HeadRateLimitReset     = `RateLimit-Reset`
+)
+
+/*
+Formats the given delay as a "Retry-After" value in seconds, rounding up.
+Negative delays are treated as 0. Example usage with a 503 response:
+
+	goh.String{
+		Status: http.StatusServiceUnavailable,
+		Header: http.Header{goh.HeadRetryAfter: {goh.RetryAfterDelay(time.Minute)}},
+		Body:   `down for maintenance`,
+	}
+*/
+func RetryAfterDelay(val time.Duration) string {
+	return strconv.FormatInt(ceilSeconds(val), 10)
+}
+
+// Formats the given time as a "Retry-After" value in the HTTP date format.
+func RetryAfterDate(val time.Time) string {
+	return val.UTC().Format(http.TimeFormat)
+}
+
+/*
+Rate limit information, which generates the headers "RateLimit-Limit",
+"RateLimit-Remaining", and "RateLimit-Reset", as described in the IETF draft
+"RateLimit header fields for HTTP". `.Reset` is the time until the quota
+resets, and is formatted in seconds, rounding up. When `.Remaining` is 0 or
+less, "Retry-After" is also set to `.Reset`, since the client has exhausted its
+quota. Intended for attaching to any Goh handler:
+
+	limit := goh.RateLimit{Limit: 100, Remaining: 0, Reset: 30 * time.Second}
+	return goh.ErrStatus{Status: http.StatusTooManyRequests, Header: limit.Header()}
+*/
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Duration
+}
+
+// Returns a new header with the rate limit headers. See `goh.RateLimit.Apply`.
+func (self RateLimit) Header() http.Header {
+	out := http.Header{}
+	self.Apply(out)
+	return out
+}
+
+// Sets the rate limit headers in the given header.
+func (self RateLimit) Apply(head http.Header) {
+	remaining := self.Remaining
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	head.Set(HeadRateLimitLimit, strconv.Itoa(self.Limit))
+	head.Set(HeadRateLimitRemaining, strconv.Itoa(remaining))
+	head.Set(HeadRateLimitReset, RetryAfterDelay(self.Reset))
+
+	if remaining == 0 {
+		head.Set(HeadRetryAfter, RetryAfterDelay(self.Reset))
+	}
+}
+
+func ceilSeconds(val time.Duration) int64 {
+	if val <= 0 {
+		return 0
+	}
+	return int64((val + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,38 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	eq(t, `0`, RetryAfterDelay(-time.Second))
+	eq(t, `0`, RetryAfterDelay(0))
+	eq(t, `1`, RetryAfterDelay(time.Millisecond))
+	eq(t, `60`, RetryAfterDelay(time.Minute))
+	eq(t, `61`, RetryAfterDelay(time.Minute+time.Millisecond))
+
+	eq(t, `Thu, 02 Jan 2020 03:04:05 GMT`, RetryAfterDate(time.Date(2020, 1, 2, 4, 4, 5, 0, time.FixedZone(``, 3600))))
+}
+
+func TestRateLimit(t *testing.T) {
+	test := func(val RateLimit, limit, remaining, reset, retry string) {
+		t.Helper()
+		head := val.Header()
+		eq(t, limit, head.Get(HeadRateLimitLimit))
+		eq(t, remaining, head.Get(HeadRateLimitRemaining))
+		eq(t, reset, head.Get(HeadRateLimitReset))
+		eq(t, retry, head.Get(HeadRetryAfter))
+	}
+
+	test(RateLimit{Limit: 100, Remaining: 10, Reset: 30 * time.Second}, `100`, `10`, `30`, ``)
+	test(RateLimit{Limit: 100, Remaining: -1, Reset: 1500 * time.Millisecond}, `100`, `0`, `2`, `2`)
+
+	rew := ht.NewRecorder()
+	ErrStatus{Status: http.StatusTooManyRequests, Header: RateLimit{Limit: 1, Reset: time.Minute}.Header()}.ServeHTTP(rew, pathReq(`/`))
+	eq(t, http.StatusTooManyRequests, rew.Code)
+	eq(t, `60`, rew.Header().Get(HeadRetryAfter))
+	eq(t, `1`, rew.Header().Get(HeadRateLimitLimit))
+}
@@ -0,0 +1,209 @@
+package goh
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+HTTP handler that serves a pre-encoded response, periodically re-encoding it in
+the background. Intended for payloads which are static but occasionally
+updated, such as config blobs or sitemaps. Must be used by pointer. Example
+usage:
+
+	var config = &goh.Refreshing{
+		Every: time.Minute,
+		Make: func() (goh.Bytes, error) {
+			val, err := loadConfig()
+			if err != nil {
+				return goh.Bytes{}, err
+			}
+			return goh.JsonOk(val).TryBytes(), nil
+		},
+	}
+
+	func main() {
+		try(config.Start())
+		defer config.Stop()
+	}
+
+`.Make` is called by `.Start`, then every `.Every` in a background goroutine,
+until `.Stop`. Each successful result atomically replaces the served
+`goh.Bytes`; requests never wait for a refresh in progress. If a refresh fails,
+the previous response continues to be served, and the error is logged to the
+standard error stream.
+
+Calling `.Start` is optional: the first request starts it lazily, unless
+`.Stop` was called. Until the first successful call of `.Make`, each request
+retries it synchronously, and fails with the resulting error via
+`goh.HandleErr`. Calls of `.Make` are serialized, so a slow call never
+replaces the result of a later one. When `.Every` is not
+positive, the response is made once and never refreshed in the background, but
+may be refreshed explicitly via `.Refresh`.
+*/
+type Refreshing struct {
+	Every time.Duration
+	Make  func() (Bytes, error)
+
+	lock        sync.Mutex
+	refreshLock sync.Mutex
+	cur         atomic.Value
+	stop        chan struct{}
+	stopped     bool
+}
+
+/*
+Makes the initial response and starts refreshing it in the background. Returns
+the error of the initial call of `.Make`, if any; in this case, background
+refresh is started anyway, and may succeed later. Does nothing if already
+started. May be used to restart after `.Stop`.
+*/
+func (self *Refreshing) Start() error {
+	self.lock.Lock()
+	self.stopped = false
+	started := self.start()
+	self.lock.Unlock()
+
+	if !started {
+		return nil
+	}
+	return self.Refresh()
+}
+
+/*
+Starts the background refresh, unless already started. Returns false if already
+started. Must be called under lock. The caller makes the initial response after
+releasing the lock, so that a slow `.Make` doesn't block `.Stop`.
+*/
+func (self *Refreshing) start() bool {
+	if self.stop != nil {
+		return false
+	}
+	if self.Every > 0 {
+		self.stop = make(chan struct{})
+		go self.loop(self.Every, self.stop)
+	}
+	return true
+}
+
+/*
+Stops the background refresh. The current response continues to be served.
+Requests don't restart the background refresh, but until a response is made,
+they still retry `.Make` synchronously.
+*/
+func (self *Refreshing) Stop() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.stopped = true
+	if self.stop != nil {
+		close(self.stop)
+		self.stop = nil
+	}
+}
+
+/*
+Calls `.Make` immediately, replacing the current response on success. On
+error, the current response is kept. Waits for any refresh in progress.
+*/
+func (self *Refreshing) Refresh() error {
+	if self.Make == nil {
+		return errors.New(`[goh] missing Refreshing.Make`)
+	}
+
+	self.refreshLock.Lock()
+	defer self.refreshLock.Unlock()
+	return self.refresh()
+}
+
+// Must be called under `.refreshLock`.
+func (self *Refreshing) refresh() error {
+	val, err := self.Make()
+	if err != nil {
+		return fmt.Errorf(`[goh] failed to refresh response: %w`, err)
+	}
+	self.cur.Store(val)
+	return nil
+}
+
+/*
+Returns the current response, starting if necessary, see the comment on
+`goh.Refreshing`. The error is non-nil only when no response was made yet.
+*/
+func (self *Refreshing) Current() (Bytes, error) {
+	val, ok := self.cur.Load().(Bytes)
+	if ok {
+		return val, nil
+	}
+
+	self.lock.Lock()
+	if !self.stopped {
+		self.start()
+	}
+	self.lock.Unlock()
+
+	return self.initial()
+}
+
+/*
+Makes the first response, unless a concurrent call has made it while this one
+was waiting. Unlike `.Refresh`, concurrent requests share one call of `.Make`.
+*/
+func (self *Refreshing) initial() (Bytes, error) {
+	if self.Make == nil {
+		return Bytes{}, errors.New(`[goh] missing Refreshing.Make`)
+	}
+
+	self.refreshLock.Lock()
+	defer self.refreshLock.Unlock()
+
+	val, ok := self.cur.Load().(Bytes)
+	if ok {
+		return val, nil
+	}
+
+	err := self.refresh()
+	if err != nil {
+		return Bytes{}, err
+	}
+	return self.cur.Load().(Bytes), nil
+}
+
+// Implement `http.Handler`.
+func (self *Refreshing) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Refreshing`, self.serveHTTP)
+}
+
+func (self *Refreshing) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	val, err := self.Current()
+	if err != nil {
+		Head{}.fail(rew, req, ErrInfo{err, `goh.Refreshing`, 0, 0, false})
+		return
+	}
+	val.ServeHTTP(rew, req)
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self *Refreshing) Han(*http.Request) http.Handler { return self }
+
+func (self *Refreshing) loop(every time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			err := self.Refresh()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%+v\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package goh
+
+import (
+	"errors"
+	"net/http"
+	ht "net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(new(Refreshing))
+	_ = Han(new(Refreshing).Han)
+)
+
+func TestRefreshing(t *testing.T) {
+	var calls int32
+	ref := &Refreshing{Make: func() (Bytes, error) {
+		return BytesOk([]byte(strconv.Itoa(int(atomic.AddInt32(&calls, 1))))), nil
+	}}
+
+	test := func(exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		ref.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, exp, rew.Body.String())
+	}
+
+	// Lazy start, no background refresh.
+	test(`1`)
+	test(`1`)
+
+	try(ref.Refresh())
+	test(`2`)
+}
+
+func TestRefreshing_background(t *testing.T) {
+	var calls int32
+	ref := &Refreshing{
+		Every: time.Millisecond,
+		Make: func() (Bytes, error) {
+			return BytesOk([]byte(strconv.Itoa(int(atomic.AddInt32(&calls, 1))))), nil
+		},
+	}
+
+	try(ref.Start())
+	try(ref.Start())
+	t.Cleanup(ref.Stop)
+
+	deadline := time.Now().Add(time.Second * 5)
+	for atomic.LoadInt32(&calls) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	ref.Stop()
+
+	val, err := ref.Current()
+	try(err)
+	eq(t, true, atomic.LoadInt32(&calls) >= 3)
+	eq(t, true, string(val.Body) != `1`)
+}
+
+func TestRefreshing_error(t *testing.T) {
+	fail := true
+	ref := &Refreshing{Make: func() (Bytes, error) {
+		if fail {
+			return Bytes{}, errors.New(`unavailable`)
+		}
+		return BytesOk([]byte(`ok`)), nil
+	}}
+
+	rew := ht.NewRecorder()
+	ref.ServeHTTP(rew, pathReq(`/`))
+	eq(t, http.StatusInternalServerError, rew.Code)
+
+	fail = false
+	rew = ht.NewRecorder()
+	ref.ServeHTTP(rew, pathReq(`/`))
+	eq(t, `ok`, rew.Body.String())
+
+	// Failed refresh keeps the previous response.
+	fail = true
+	eq(t, true, ref.Refresh() != nil)
+	val, err := ref.Current()
+	try(err)
+	eq(t, `ok`, string(val.Body))
+
+	_, err = new(Refreshing).Current()
+	eq(t, true, err != nil)
+}
+
+func TestRefreshing_stopped(t *testing.T) {
+	var fail int32 = 1
+	ref := &Refreshing{
+		Every: time.Millisecond,
+		Make: func() (Bytes, error) {
+			if atomic.LoadInt32(&fail) == 1 {
+				return Bytes{}, errors.New(`unavailable`)
+			}
+			return BytesOk([]byte(`ok`)), nil
+		},
+	}
+
+	eq(t, true, ref.Start() != nil)
+	ref.Stop()
+
+	_, err := ref.Current()
+	eq(t, true, err != nil)
+
+	atomic.StoreInt32(&fail, 0)
+	val, err := ref.Current()
+	try(err)
+	eq(t, `ok`, string(val.Body))
+	eq(t, true, ref.stop == nil)
+
+	try(ref.Start())
+	eq(t, true, ref.stop != nil)
+	ref.Stop()
+}
+
+func TestRefreshing_serialized(t *testing.T) {
+	var calls int32
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	ref := &Refreshing{Make: func() (Bytes, error) {
+		call := atomic.AddInt32(&calls, 1)
+		if call == 1 {
+			close(entered)
+			<-release
+		}
+		return BytesOk([]byte(strconv.Itoa(int(call)))), nil
+	}}
+
+	done := make(chan error, 2)
+	go func() { done <- ref.Refresh() }()
+	<-entered
+	go func() { done <- ref.Refresh() }()
+
+	time.Sleep(time.Millisecond * 10)
+	eq(t, int32(1), atomic.LoadInt32(&calls))
+
+	close(release)
+	try(<-done)
+	try(<-done)
+
+	val, err := ref.Current()
+	try(err)
+	eq(t, `2`, string(val.Body))
+}
+
+func TestRefreshing_unlocked(t *testing.T) {
+	var calls int32
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	ref := &Refreshing{Every: time.Hour, Make: func() (Bytes, error) {
+		call := atomic.AddInt32(&calls, 1)
+		if call == 1 {
+			close(entered)
+			<-release
+		}
+		return BytesOk([]byte(strconv.Itoa(int(call)))), nil
+	}}
+
+	started := make(chan error, 1)
+	go func() { started <- ref.Start() }()
+	<-entered
+
+	// A slow initial call doesn't block `.Stop`.
+	stopped := make(chan struct{})
+	go func() {
+		ref.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal(`Stop blocked by Make`)
+	}
+
+	// Concurrent requests share the initial call.
+	current := make(chan string, 1)
+	go func() {
+		val, err := ref.Current()
+		try(err)
+		current <- string(val.Body)
+	}()
+
+	close(release)
+	try(<-started)
+	eq(t, `1`, <-current)
+	eq(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,89 @@
+package goh
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// Header used by `goh.RequestId`.
+const HeadRequestId = `X-Request-Id`
+
+// Max length of an incoming request ID accepted by `goh.RequestId`.
+const MaxRequestIdLen = 128
+
+type requestIdKey struct{}
+
+/*
+HTTP handler that assigns an ID to each request and serves the inner handler.
+Uses the incoming "X-Request-Id" header if it's valid, otherwise generates a
+new ID via `.New`, defaulting to `goh.NewRequestId`. The ID is echoed in the
+response header, and stored in the request context, where it can be obtained
+via `goh.RequestIdOf`, for example in a `goh.ErrFunc` or a `goh.OnServe`
+hook. `goh.AccessEntry` includes it automatically.
+
+A valid incoming ID is non-empty, no longer than `goh.MaxRequestIdLen`, and
+consists of ASCII letters, digits, and the characters "-_.:".
+*/
+type RequestId struct {
+	Handler http.Handler
+	New     func() string
+}
+
+// Implement `http.Handler`.
+func (self RequestId) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	id := req.Header.Get(HeadRequestId)
+	if !isValidRequestId(id) {
+		id = self.new()
+	}
+
+	rew.Header().Set(HeadRequestId, id)
+	req = req.WithContext(context.WithValue(req.Context(), requestIdKey{}, id))
+	observe(rew, req, `goh.RequestId`, orNotFound(self.Handler).ServeHTTP)
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self RequestId) Han(*http.Request) http.Handler { return self }
+
+func (self RequestId) new() string {
+	if self.New != nil {
+		return self.New()
+	}
+	return NewRequestId()
+}
+
+/*
+Returns the request ID assigned by `goh.RequestId`, or an empty string if the
+request wasn't served by it.
+*/
+func RequestIdOf(req *http.Request) string {
+	if req == nil {
+		return ``
+	}
+	val, _ := req.Context().Value(requestIdKey{}).(string)
+	return val
+}
+
+// Returns a random request ID: 32 hexadecimal characters.
+func NewRequestId() string {
+	var buf [16]byte
+	_, _ = rand.Read(buf[:])
+	return hex.EncodeToString(buf[:])
+}
+
+func isValidRequestId(val string) bool {
+	if val == `` || len(val) > MaxRequestIdLen {
+		return false
+	}
+	for ind := 0; ind < len(val); ind++ {
+		char := val[ind]
+		if !(char >= 'a' && char <= 'z' ||
+			char >= 'A' && char <= 'Z' ||
+			char >= '0' && char <= '9' ||
+			char == '-' || char == '_' || char == '.' || char == ':') {
+			return false
+		}
+	}
+	return true
+}
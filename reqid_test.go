@@ -0,0 +1,77 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var (
+	_ = http.Handler(RequestId{})
+	_ = Han(RequestId{}.Han)
+)
+
+func TestRequestId(t *testing.T) {
+	var seen string
+	inner := http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+		seen = RequestIdOf(req)
+	})
+
+	t.Run(`incoming`, func(t *testing.T) {
+		req := ht.NewRequest(http.MethodGet, `/`, nil)
+		req.Header.Set(HeadRequestId, `abc-123`)
+		rew := ht.NewRecorder()
+		RequestId{Handler: inner}.ServeHTTP(rew, req)
+
+		eq(t, `abc-123`, seen)
+		eq(t, `abc-123`, rew.Header().Get(HeadRequestId))
+	})
+
+	t.Run(`invalid incoming`, func(t *testing.T) {
+		for _, val := range []string{`one two`, `<script>`, strings.Repeat(`a`, MaxRequestIdLen+1)} {
+			req := ht.NewRequest(http.MethodGet, `/`, nil)
+			req.Header.Set(HeadRequestId, val)
+			rew := ht.NewRecorder()
+			RequestId{Handler: inner, New: func() string { return `new` }}.ServeHTTP(rew, req)
+
+			eq(t, `new`, seen)
+			eq(t, `new`, rew.Header().Get(HeadRequestId))
+		}
+	})
+
+	t.Run(`generated`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		RequestId{Handler: inner}.ServeHTTP(rew, ht.NewRequest(http.MethodGet, `/`, nil))
+
+		eq(t, 32, len(seen))
+		eq(t, seen, rew.Header().Get(HeadRequestId))
+	})
+
+	t.Run(`missing`, func(t *testing.T) {
+		eq(t, ``, RequestIdOf(nil))
+		eq(t, ``, RequestIdOf(ht.NewRequest(http.MethodGet, `/`, nil)))
+	})
+}
+
+func TestRequestId_hooks(t *testing.T) {
+	infos := collectServeInfo(t)
+
+	var errId string
+	han := RequestId{
+		Handler: String{
+			Body: `hello`,
+			ErrFunc: func(rew http.ResponseWriter, req *http.Request, err error, _ bool) {
+				errId = RequestIdOf(req)
+			},
+		},
+		New: func() string { return `one` },
+	}
+
+	han.ServeHTTP(&failWriter{ResponseWriter: ht.NewRecorder()}, ht.NewRequest(http.MethodGet, `/`, nil))
+
+	eq(t, `one`, errId)
+	eq(t, 1, len(*infos))
+	eq(t, `one`, RequestIdOf((*infos)[0].Req))
+	eq(t, `one`, AccessEntryOf((*infos)[0]).RequestId)
+}
@@ -0,0 +1,131 @@
+package goh
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+/*
+HTTP handler that serves "robots.txt" as defined by RFC 9309, with the content
+type `goh.TypeText`. Intended for declarative package-level values:
+
+	var robots = goh.Robots{
+		Groups: []goh.RobotsGroup{
+			{Disallow: []string{`/admin/`}},
+			{UserAgents: []string{`BadBot`}, Disallow: []string{`/`}},
+		},
+		Sitemaps: []string{`https://example.com/sitemap.xml`},
+	}
+
+Output:
+
+	User-agent: *
+	Disallow: /admin/
+
+	User-agent: BadBot
+	Disallow: /
+
+	Sitemap: https://example.com/sitemap.xml
+*/
+type Robots struct {
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Groups       []RobotsGroup
+	Sitemaps     []string
+}
+
+/*
+Group of rules in `goh.Robots`. Empty `.UserAgents` means "*", matching all
+crawlers. A group without rules allows everything.
+*/
+type RobotsGroup struct {
+	UserAgents []string
+	Allow      []string
+	Disallow   []string
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self Robots) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self Robots) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Robots`, self.serveHTTP)
+}
+
+func (self Robots) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	rew.Header().Set(HeadType, withCharset(TypeText, ``))
+
+	head := self.Head()
+	head.Write(rew)
+
+	size, err := io.WriteString(rew, self.String())
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write robots.txt: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.Robots`, head.sentStatusIf(size > 0), int64(size), size > 0})
+	}
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Robots) Han(*http.Request) http.Handler { return self }
+
+/*
+Renders the "robots.txt" content. Line breaks in values are removed, which
+prevents them from adding unintended rules.
+*/
+func (self Robots) String() string {
+	var buf strings.Builder
+
+	for ind, group := range self.Groups {
+		if ind > 0 {
+			buf.WriteString("\n")
+		}
+		group.write(&buf)
+	}
+
+	for ind, val := range self.Sitemaps {
+		if ind == 0 && len(self.Groups) > 0 {
+			buf.WriteString("\n")
+		}
+		robotsLine(&buf, `Sitemap`, val)
+	}
+
+	return buf.String()
+}
+
+func (self RobotsGroup) write(buf *strings.Builder) {
+	if len(self.UserAgents) == 0 {
+		robotsLine(buf, `User-agent`, `*`)
+	}
+	for _, val := range self.UserAgents {
+		robotsLine(buf, `User-agent`, val)
+	}
+
+	if len(self.Allow) == 0 && len(self.Disallow) == 0 {
+		robotsLine(buf, `Disallow`, ``)
+		return
+	}
+	for _, val := range self.Allow {
+		robotsLine(buf, `Allow`, val)
+	}
+	for _, val := range self.Disallow {
+		robotsLine(buf, `Disallow`, val)
+	}
+}
+
+var robotsReplacer = strings.NewReplacer("\r", ``, "\n", ``)
+
+func robotsLine(buf *strings.Builder, key, val string) {
+	buf.WriteString(key)
+	buf.WriteString(`:`)
+	if val != `` {
+		buf.WriteString(` `)
+		buf.WriteString(robotsReplacer.Replace(val))
+	}
+	buf.WriteString("\n")
+}
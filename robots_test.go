@@ -0,0 +1,46 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+var (
+	_ = http.Handler(Robots{})
+	_ = Han(Robots{}.Han)
+)
+
+func TestRobots(t *testing.T) {
+	han := Robots{
+		Groups: []RobotsGroup{
+			{Allow: []string{`/public/`}, Disallow: []string{`/admin/`, `/tmp/`}},
+			{UserAgents: []string{`BadBot`, `OtherBot`}, Disallow: []string{"/\nAllow: /secret"}},
+			{UserAgents: []string{`GoodBot`}},
+		},
+		Sitemaps: []string{`https://example.com/sitemap.xml`},
+	}
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, `text/plain; charset=utf-8`, rew.Header().Get(HeadType))
+	eq(t, `User-agent: *
+Allow: /public/
+Disallow: /admin/
+Disallow: /tmp/
+
+User-agent: BadBot
+User-agent: OtherBot
+Disallow: /Allow: /secret
+
+User-agent: GoodBot
+Disallow:
+
+Sitemap: https://example.com/sitemap.xml
+`, rew.Body.String())
+
+	eq(t, ``, Robots{}.String())
+	eq(t, "Sitemap: /one\n", Robots{Sitemaps: []string{`/one`}}.String())
+}
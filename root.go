@@ -0,0 +1,34 @@
+//go:build go1.24
+// +build go1.24
+
+package goh
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+/*
+Opens the file via `os.Root`, which prevents escaping the root directory
+through `..` segments or symlinks. The root is closed immediately; the opened
+file remains valid.
+*/
+func openInRoot(root, path string) (fs.File, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	file, err := dir.Open(rel)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,12 @@
+//go:build !go1.24
+// +build !go1.24
+
+package goh
+
+import (
+	"io/fs"
+	"os"
+)
+
+// `os.Root` is unavailable before Go 1.24, so this opens the file normally.
+func openInRoot(_, path string) (fs.File, error) { return os.Open(path) }
@@ -0,0 +1,28 @@
+//go:build go1.24
+// +build go1.24
+
+package goh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_openInRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFile(filepath.Join(root, `one.txt`), `one`)
+	writeFile(filepath.Join(outside, `secret.txt`), `secret`)
+	try(os.Symlink(filepath.Join(outside, `secret.txt`), filepath.Join(root, `two.txt`)))
+
+	file, err := openInRoot(root, filepath.Join(root, `one.txt`))
+	eq(t, nil, err)
+	try(file.Close())
+
+	_, err = openInRoot(root, filepath.Join(root, `two.txt`))
+	eq(t, true, err != nil)
+
+	_, err = openInRoot(root, filepath.Join(outside, `secret.txt`))
+	eq(t, true, err != nil)
+}
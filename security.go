@@ -0,0 +1,61 @@
+package goh
+
+import "net/http"
+
+/*
+HTTP handler that adds a configurable set of standard security headers to the
+response, then delegates to `.Handler`. Empty fields are skipped. Headers
+written by the inner handler take priority, since they're written later. When
+`.Handler` is nil, responds with `goh.NotFound`.
+
+See `goh.SecurityHeadersDefault` for a reasonable preset. Example usage:
+
+	var someHan = goh.SecurityHeadersDefault.With(goh.StringOk(`hello world`))
+*/
+type SecurityHeaders struct {
+	ContentTypeOptions    string
+	FrameOptions          string
+	ReferrerPolicy        string
+	StrictTransport       string
+	ContentSecurityPolicy string
+	Handler               http.Handler
+}
+
+/*
+Reasonable default for `goh.SecurityHeaders`. Doesn't include a content
+security policy, since there's no policy that would suit every app.
+*/
+var SecurityHeadersDefault = SecurityHeaders{
+	ContentTypeOptions: `nosniff`,
+	FrameOptions:       `DENY`,
+	ReferrerPolicy:     `strict-origin-when-cross-origin`,
+	StrictTransport:    `max-age=63072000; includeSubDomains`,
+}
+
+// Implement `http.Handler`.
+func (self SecurityHeaders) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.SecurityHeaders`, self.serveHTTP)
+}
+
+func (self SecurityHeaders) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	self.Apply(rew.Header())
+	orNotFound(self.Handler).ServeHTTP(rew, req)
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self SecurityHeaders) Han(*http.Request) http.Handler { return self }
+
+// Returns a modified version with the given inner handler.
+func (self SecurityHeaders) With(val http.Handler) SecurityHeaders {
+	self.Handler = val
+	return self
+}
+
+// Sets the non-empty configured headers in the given header map.
+func (self SecurityHeaders) Apply(head http.Header) {
+	setNonEmpty(head, `X-Content-Type-Options`, self.ContentTypeOptions)
+	setNonEmpty(head, `X-Frame-Options`, self.FrameOptions)
+	setNonEmpty(head, `Referrer-Policy`, self.ReferrerPolicy)
+	setNonEmpty(head, `Strict-Transport-Security`, self.StrictTransport)
+	setNonEmpty(head, `Content-Security-Policy`, self.ContentSecurityPolicy)
+}
@@ -0,0 +1,47 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+var _ = Han(SecurityHeaders{}.Han)
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Run(`default`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		SecurityHeadersDefault.With(StringOk(`ok`)).ServeHTTP(rew, nil)
+
+		eq(t, 200, rew.Code)
+		eq(t, `ok`, rew.Body.String())
+		eq(t, `nosniff`, rew.Header().Get(`X-Content-Type-Options`))
+		eq(t, `DENY`, rew.Header().Get(`X-Frame-Options`))
+		eq(t, `strict-origin-when-cross-origin`, rew.Header().Get(`Referrer-Policy`))
+		eq(t, `max-age=63072000; includeSubDomains`, rew.Header().Get(`Strict-Transport-Security`))
+		eq(t, ``, rew.Header().Get(`Content-Security-Policy`))
+	})
+
+	t.Run(`inner handler overrides`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		SecurityHeaders{
+			FrameOptions:          `DENY`,
+			ContentSecurityPolicy: `default-src 'self'`,
+			Handler: String{
+				Status: http.StatusCreated,
+				Header: http.Header{`X-Frame-Options`: {`SAMEORIGIN`}},
+			},
+		}.ServeHTTP(rew, nil)
+
+		eq(t, http.Header{
+			`X-Frame-Options`:         {`SAMEORIGIN`},
+			`Content-Security-Policy`: {`default-src 'self'`},
+		}, rew.Result().Header)
+	})
+
+	t.Run(`nil handler`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		SecurityHeaders{}.ServeHTTP(rew, nil)
+		eq(t, http.StatusNotFound, rew.Code)
+	})
+}
@@ -0,0 +1,130 @@
+package goh
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+/*
+Deduplicates concurrent invocations of an expensive `goh.Han`. While a call
+for a given key is in progress, other requests with the same key wait for it
+and share its result, instead of repeating the computation. Protects against
+thundering herds, for example when many requests arrive for a page whose
+underlying data is not cached yet. Must be used by pointer. Example usage:
+
+	var report = &goh.Single{Make: func(req *http.Request) http.Handler {
+		return goh.JsonOk(computeReport()).TryBytes()
+	}}
+
+Nothing is cached: once the call completes, the next request for the same key
+starts a new one. Combine with a cache when results should be reused over
+time. `.Key` determines which requests are considered the same, defaulting to
+`goh.SingleKey`, which uses the method and the URL. The resulting handler is
+served to every waiting request, and must be safe to serve concurrently and
+repeatedly; Goh's body types such as `goh.Bytes` and `goh.Json` are. Panics in
+`.Make` are converted to `goh.ErrPanic` as in `goh.HandlerReq`, which is also
+shared.
+
+`.Make` receives the request which started the call. Its context belongs to
+that request, and may be canceled when that client disconnects, even though
+other requests are waiting. Waiting requests stop waiting when their own
+context is done, and fail with `goh.ErrStatus` 503 wrapping the context error.
+*/
+type Single struct {
+	Make Han
+	Key  func(*http.Request) string
+
+	lock  sync.Mutex
+	calls map[string]*singleCall
+}
+
+type singleCall struct {
+	done chan struct{}
+	out  http.Handler
+}
+
+// Implement `http.Handler`.
+func (self *Single) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Single`, self.serveHTTP)
+}
+
+func (self *Single) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	self.Han(req).ServeHTTP(rew, req)
+}
+
+/*
+Conforms to `goh.Han`. Returns the result of `.Make`, either computed for this
+request, or shared with a concurrent call with the same key. Always returns
+non-nil: when `.Make` returns nil, returns `goh.NotFound`.
+*/
+func (self *Single) Han(req *http.Request) http.Handler {
+	key := self.key(req)
+
+	self.lock.Lock()
+	call := self.calls[key]
+	if call != nil {
+		self.lock.Unlock()
+		return call.wait(req)
+	}
+
+	call = &singleCall{done: make(chan struct{})}
+	if self.calls == nil {
+		self.calls = map[string]*singleCall{}
+	}
+	self.calls[key] = call
+	self.lock.Unlock()
+
+	call.out = orNotFound(HandlerReq(req, self.make))
+
+	self.lock.Lock()
+	delete(self.calls, key)
+	self.lock.Unlock()
+	close(call.done)
+
+	return call.out
+}
+
+/*
+Waits for the call to complete, or for the request context to be done, for
+example when the client disconnects. In the latter case, returns an error
+handler, while the call continues for the other requests.
+*/
+func (self *singleCall) wait(req *http.Request) http.Handler {
+	select {
+	case <-self.done:
+		return self.out
+	case <-req.Context().Done():
+		return ErrStatus{
+			Status: http.StatusServiceUnavailable,
+			Err:    fmt.Errorf(`[goh] request ended while waiting for a concurrent call: %w`, req.Context().Err()),
+		}
+	}
+}
+
+func (self *Single) make(req *http.Request) http.Handler {
+	if self.Make == nil {
+		return nil
+	}
+	return self.Make(req)
+}
+
+func (self *Single) key(req *http.Request) string {
+	if self.Key != nil {
+		return self.Key(req)
+	}
+	return SingleKey(req)
+}
+
+/*
+Default key function of `goh.Single`: the request method and URL, such as
+"GET /users?page=2". Requests that differ only in headers are considered the
+same; when the response depends on headers, such as "Accept" or cookies, use a
+custom key.
+*/
+func SingleKey(req *http.Request) string {
+	if req == nil || req.URL == nil {
+		return ``
+	}
+	return req.Method + ` ` + req.URL.RequestURI()
+}
@@ -0,0 +1,118 @@
+package goh
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	ht "net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(new(Single))
+	_ = Han(new(Single).Han)
+)
+
+func TestSingle(t *testing.T) {
+	const count = 8
+	var calls int32
+	var keyed sync.WaitGroup
+	keyed.Add(count)
+	release := make(chan struct{})
+
+	single := &Single{
+		Make: func(*http.Request) http.Handler {
+			<-release
+			return StringOk(`call ` + string(rune('0'+atomic.AddInt32(&calls, 1))))
+		},
+		Key: func(req *http.Request) string {
+			defer keyed.Done()
+			return SingleKey(req)
+		},
+	}
+
+	var done sync.WaitGroup
+	bodies := make([]string, count)
+	for ind := range bodies {
+		ind := ind
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			rew := ht.NewRecorder()
+			single.ServeHTTP(rew, pathReq(`/report`))
+			bodies[ind] = rew.Body.String()
+		}()
+	}
+
+	keyed.Wait()
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	done.Wait()
+
+	eq(t, int32(1), atomic.LoadInt32(&calls))
+	for _, body := range bodies {
+		eq(t, `call 1`, body)
+	}
+
+	// Nothing is cached: a subsequent request makes a new call.
+	single.Key = nil
+	rew := ht.NewRecorder()
+	single.ServeHTTP(rew, pathReq(`/report`))
+	eq(t, `call 2`, rew.Body.String())
+}
+
+func TestSingle_keys(t *testing.T) {
+	var calls int32
+	single := &Single{Make: func(req *http.Request) http.Handler {
+		atomic.AddInt32(&calls, 1)
+		return StringOk(req.URL.Path)
+	}}
+
+	eq(t, String{Status: http.StatusOK, Body: `/one`}, single.Han(pathReq(`/one`)))
+	eq(t, String{Status: http.StatusOK, Body: `/two`}, single.Han(pathReq(`/two`)))
+	eq(t, int32(2), calls)
+
+	eq(t, `GET /one?two=three`, SingleKey(ht.NewRequest(http.MethodGet, `/one?two=three`, nil)))
+	eq(t, ``, SingleKey(nil))
+}
+
+func TestSingle_nil_and_panic(t *testing.T) {
+	rew := ht.NewRecorder()
+	new(Single).ServeHTTP(rew, pathReq(`/`))
+	eq(t, http.StatusNotFound, rew.Code)
+
+	single := &Single{Make: func(*http.Request) http.Handler { panic(`boom`) }}
+
+	rew = ht.NewRecorder()
+	single.ServeHTTP(rew, pathReq(`/`))
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, 0, len(single.calls))
+}
+
+func TestSingle_canceled(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	single := &Single{Make: func(*http.Request) http.Handler {
+		close(entered)
+		<-release
+		return StringOk(`done`)
+	}}
+
+	first := make(chan http.Handler, 1)
+	go func() { first <- single.Han(pathReq(`/report`)) }()
+	<-entered
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := single.Han(pathReq(`/report`).WithContext(ctx))
+
+	err, _ := out.(ErrStatus)
+	eq(t, http.StatusServiceUnavailable, err.Status)
+	eq(t, true, errors.Is(err, context.Canceled))
+
+	close(release)
+	eq(t, StringOk(`done`), <-first)
+}
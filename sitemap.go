@@ -0,0 +1,224 @@
+package goh
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// XML namespace of sitemaps and sitemap indexes, as defined by sitemaps.org.
+const SitemapNs = `http://www.sitemaps.org/schemas/sitemap/0.9`
+
+/*
+Entry of `goh.Sitemap`. `.Loc` is required and must be an absolute URL. Other
+fields are optional and omitted when empty. `.ChangeFreq` should be one of
+"always", "hourly", "daily", "weekly", "monthly", "yearly", "never".
+`.Priority` should be between 0 and 1; zero is omitted, implying the default
+0.5.
+*/
+type SitemapUrl struct {
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+// Implement `encoding/xml.Marshaler`, encoding the entry as `<url>`.
+func (self SitemapUrl) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	var priority string
+	if self.Priority != 0 {
+		priority = strconv.FormatFloat(self.Priority, 'f', -1, 64)
+	}
+
+	return enc.Encode(sitemapUrlXml{
+		Loc:        self.Loc,
+		LastMod:    sitemapTime(self.LastMod),
+		ChangeFreq: self.ChangeFreq,
+		Priority:   priority,
+	})
+}
+
+type sitemapUrlXml struct {
+	XMLName    xml.Name `xml:"url"`
+	Loc        string   `xml:"loc"`
+	LastMod    string   `xml:"lastmod,omitempty"`
+	ChangeFreq string   `xml:"changefreq,omitempty"`
+	Priority   string   `xml:"priority,omitempty"`
+}
+
+/*
+Entry of `goh.SitemapIndex`, referencing another sitemap. `.Loc` is required
+and must be an absolute URL. `.LastMod` is omitted when zero.
+*/
+type SitemapRef struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Implement `encoding/xml.Marshaler`, encoding the entry as `<sitemap>`.
+func (self SitemapRef) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	return enc.Encode(sitemapRefXml{
+		Loc:     self.Loc,
+		LastMod: sitemapTime(self.LastMod),
+	})
+}
+
+type sitemapRefXml struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+/*
+HTTP handler that serves a sitemap in the standard sitemaps.org XML format,
+with the content type `goh.TypeXml`. Serves the entries in `.Urls`, followed by
+the entries produced by `.Iter`, if any. Example usage:
+
+	var han = goh.Sitemap{Urls: []goh.SitemapUrl{
+		{Loc: `https://example.com/`, ChangeFreq: `daily`, Priority: 1},
+		{Loc: `https://example.com/about`},
+	}}
+
+For sites with many URLs, `.Iter` allows to stream the entries, for example
+from a database, without collecting them in memory. It must call the given
+function for each entry, stopping and returning its error, if any. Each entry
+is encoded immediately, and the response is written incrementally. Errors
+returned by `.Iter` are passed to `.ErrFunc` with `wrote = true`, since the
+response is already partially written.
+
+The sitemaps.org protocol limits each sitemap to 50000 URLs and 50 MB. Larger
+sites should split their URLs between several sitemaps, listed in a
+`goh.SitemapIndex`.
+*/
+type Sitemap struct {
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Urls         []SitemapUrl
+	Iter         func(func(SitemapUrl) error) error
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self Sitemap) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self Sitemap) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Sitemap`, self.serveHTTP)
+}
+
+func (self Sitemap) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	serveSitemap(rew, req, self.Head(), `goh.Sitemap`, `urlset`, func(enc *xml.Encoder) error {
+		for _, val := range self.Urls {
+			err := enc.Encode(val)
+			if err != nil {
+				return err
+			}
+		}
+		if self.Iter == nil {
+			return nil
+		}
+		return self.Iter(func(val SitemapUrl) error { return enc.Encode(val) })
+	})
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Sitemap) Han(*http.Request) http.Handler { return self }
+
+/*
+HTTP handler that serves a sitemap index in the standard sitemaps.org XML
+format, listing other sitemaps. Works like `goh.Sitemap`: serves the entries
+in `.Sitemaps`, followed by the entries produced by `.Iter`, if any.
+*/
+type SitemapIndex struct {
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Sitemaps     []SitemapRef
+	Iter         func(func(SitemapRef) error) error
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self SitemapIndex) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self SitemapIndex) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.SitemapIndex`, self.serveHTTP)
+}
+
+func (self SitemapIndex) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	serveSitemap(rew, req, self.Head(), `goh.SitemapIndex`, `sitemapindex`, func(enc *xml.Encoder) error {
+		for _, val := range self.Sitemaps {
+			err := enc.Encode(val)
+			if err != nil {
+				return err
+			}
+		}
+		if self.Iter == nil {
+			return nil
+		}
+		return self.Iter(func(val SitemapRef) error { return enc.Encode(val) })
+	})
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self SitemapIndex) Han(*http.Request) http.Handler { return self }
+
+func serveSitemap(
+	rew http.ResponseWriter, req *http.Request, head Head, kind, root string,
+	fun func(*xml.Encoder) error,
+) {
+	rew.Header().Set(HeadType, withCharset(TypeXml, ``))
+	head.Write(rew)
+
+	writer := spyingWriter{Writer: rew}
+	err := writeSitemap(&writer, root, fun)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write sitemap: %w`, err)
+		head.fail(rew, req, ErrInfo{err, kind, head.sentStatusIf(writer.wrote), writer.size, writer.wrote})
+	}
+}
+
+func writeSitemap(out io.Writer, root string, fun func(*xml.Encoder) error) error {
+	enc := xml.NewEncoder(out)
+	start := xml.StartElement{
+		Name: xml.Name{Local: root},
+		Attr: []xml.Attr{{Name: xml.Name{Local: `xmlns`}, Value: SitemapNs}},
+	}
+
+	err := enc.EncodeToken(xml.ProcInst{Target: `xml`, Inst: []byte(`version="1.0" encoding="UTF-8"`)})
+	if err != nil {
+		return err
+	}
+
+	err = enc.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	err = fun(enc)
+	if err != nil {
+		return err
+	}
+
+	err = enc.EncodeToken(start.End())
+	if err != nil {
+		return err
+	}
+	return enc.Flush()
+}
+
+func sitemapTime(val time.Time) string {
+	if val.IsZero() {
+		return ``
+	}
+	return val.Format(time.RFC3339)
+}
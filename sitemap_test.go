@@ -0,0 +1,81 @@
+package goh
+
+import (
+	"errors"
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(Sitemap{})
+	_ = Han(Sitemap{}.Han)
+	_ = http.Handler(SitemapIndex{})
+	_ = Han(SitemapIndex{}.Han)
+)
+
+func TestSitemap(t *testing.T) {
+	mod := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	han := Sitemap{
+		Urls: []SitemapUrl{
+			{Loc: `https://example.com/`, LastMod: mod, ChangeFreq: `daily`, Priority: 1},
+			{Loc: `https://example.com/a?b=c&d=e`, Priority: 0.25},
+		},
+		Iter: func(fun func(SitemapUrl) error) error {
+			return fun(SitemapUrl{Loc: `https://example.com/streamed`})
+		},
+	}
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, TypeXml+`; charset=utf-8`, rew.Header().Get(HeadType))
+	eq(
+		t,
+		`<?xml version="1.0" encoding="UTF-8"?>`+
+			`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+			`<url><loc>https://example.com/</loc><lastmod>2024-01-02T03:04:05Z</lastmod><changefreq>daily</changefreq><priority>1</priority></url>`+
+			`<url><loc>https://example.com/a?b=c&amp;d=e</loc><priority>0.25</priority></url>`+
+			`<url><loc>https://example.com/streamed</loc></url>`+
+			`</urlset>`,
+		rew.Body.String(),
+	)
+}
+
+func TestSitemap_Iter_error(t *testing.T) {
+	var info ErrInfo
+	han := Sitemap{
+		ErrFunc: func(_ http.ResponseWriter, _ *http.Request, err error, wrote bool) {
+			// The XML prologue is buffered, so nothing was sent yet.
+			eq(t, false, wrote)
+			eq(t, true, errors.As(err, &info))
+		},
+		Iter: func(func(SitemapUrl) error) error { return errors.New(`db failure`) },
+	}
+
+	han.ServeHTTP(ht.NewRecorder(), nil)
+	eq(t, `goh.Sitemap`, info.Handler)
+	eq(t, 0, info.Status)
+	eq(t, `[goh] failed to write sitemap: db failure`, info.Err.Error())
+}
+
+func TestSitemapIndex(t *testing.T) {
+	rew := ht.NewRecorder()
+	SitemapIndex{Sitemaps: []SitemapRef{
+		{Loc: `https://example.com/one.xml`, LastMod: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Loc: `https://example.com/two.xml`},
+	}}.ServeHTTP(rew, nil)
+
+	eq(
+		t,
+		`<?xml version="1.0" encoding="UTF-8"?>`+
+			`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+
+			`<sitemap><loc>https://example.com/one.xml</loc><lastmod>2024-01-02T00:00:00Z</lastmod></sitemap>`+
+			`<sitemap><loc>https://example.com/two.xml</loc></sitemap>`+
+			`</sitemapindex>`,
+		rew.Body.String(),
+	)
+}
@@ -0,0 +1,119 @@
+package goh
+
+import (
+	"net/http"
+	"strings"
+)
+
+/*
+Handler that redirects requests for paths without a trailing slash to the same
+path with a trailing slash, preserving the query string. Requests for paths
+already ending with a slash are not redirected: `.ServedHTTP` returns false,
+`.HanOpt` returns nil, and `.ServeHTTP` and `.Han` use `goh.NotFound`. This
+allows to use it in front of other handlers.
+
+Every path without a trailing slash is redirected, including paths of files
+such as "/main.css". When serving files, place it after the file handler, so
+that existing files are served before any redirect:
+
+	var han = goh.Coalesce(
+		goh.Dir{Path: `static`},
+		goh.AddSlash{},
+		someHandler,
+	)
+
+The status is `.Status` if set. Otherwise it's 301 for GET and HEAD requests,
+and 308 for other methods, which preserves the method and body.
+*/
+type AddSlash struct{ Status int }
+
+// Implement `http.Handler`.
+func (self AddSlash) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.AddSlash`, self.serveHTTP)
+}
+
+func (self AddSlash) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	self.Han(req).ServeHTTP(rew, req)
+}
+
+// Implement `goh.HttpHandlerOpt`.
+func (self AddSlash) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.AddSlash`, self.servedHTTP)
+}
+
+func (self AddSlash) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return servedHTTP(self.HanOpt(req), rew, req)
+}
+
+// Conforms to `goh.Han`. Returns the redirect, or `goh.NotFound`.
+func (self AddSlash) Han(req *http.Request) http.Handler {
+	return orNotFound(self.HanOpt(req))
+}
+
+// Returns the redirect if the request path lacks a trailing slash, or nil.
+func (self AddSlash) HanOpt(req *http.Request) http.Handler {
+	path := req.URL.EscapedPath()
+	if strings.HasSuffix(path, `/`) {
+		return nil
+	}
+	return slashRedirect(self.Status, req, path+`/`)
+}
+
+/*
+Handler that redirects requests for paths with a trailing slash to the same
+path without the trailing slash, preserving the query string. The root path
+`/` is never redirected. Otherwise, behaves like `goh.AddSlash`.
+*/
+type StripSlash struct{ Status int }
+
+// Implement `http.Handler`.
+func (self StripSlash) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.StripSlash`, self.serveHTTP)
+}
+
+func (self StripSlash) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	self.Han(req).ServeHTTP(rew, req)
+}
+
+// Implement `goh.HttpHandlerOpt`.
+func (self StripSlash) ServedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return observed(rew, req, `goh.StripSlash`, self.servedHTTP)
+}
+
+func (self StripSlash) servedHTTP(rew http.ResponseWriter, req *http.Request) bool {
+	return servedHTTP(self.HanOpt(req), rew, req)
+}
+
+// Conforms to `goh.Han`. Returns the redirect, or `goh.NotFound`.
+func (self StripSlash) Han(req *http.Request) http.Handler {
+	return orNotFound(self.HanOpt(req))
+}
+
+// Returns the redirect if the request path has a trailing slash, or nil.
+func (self StripSlash) HanOpt(req *http.Request) http.Handler {
+	path := strings.TrimRight(req.URL.EscapedPath(), `/`)
+	if path == `` || path == req.URL.EscapedPath() {
+		return nil
+	}
+	return slashRedirect(self.Status, req, path)
+}
+
+/*
+Leading slashes are collapsed to avoid producing protocol-relative links such as
+`//example.com/`, which would redirect to another host.
+*/
+func slashRedirect(status int, req *http.Request, path string) Redirect {
+	if status == 0 {
+		if isSafeMethod(req.Method) {
+			status = http.StatusMovedPermanently
+		} else {
+			status = http.StatusPermanentRedirect
+		}
+	}
+
+	link := `/` + strings.TrimLeft(path, `/`)
+	if req.URL.RawQuery != `` {
+		link += `?` + req.URL.RawQuery
+	}
+	return RedirectWith(status, link)
+}
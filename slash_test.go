@@ -0,0 +1,82 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	_ = http.Handler(AddSlash{})
+	_ = HttpHandlerOpt(AddSlash{})
+	_ = Han(AddSlash{}.Han)
+	_ = http.Handler(StripSlash{})
+	_ = HttpHandlerOpt(StripSlash{})
+	_ = Han(StripSlash{}.Han)
+)
+
+func TestAddSlash(t *testing.T) {
+	get := func(path string) *http.Request { return ht.NewRequest(http.MethodGet, path, nil) }
+
+	eq(t, nil, AddSlash{}.HanOpt(get(`/`)))
+	eq(t, nil, AddSlash{}.HanOpt(get(`/one/`)))
+	eq(t, RedirectWith(301, `/one/`), AddSlash{}.HanOpt(get(`/one`)))
+	eq(t, RedirectWith(301, `/one/two/?three=four`), AddSlash{}.HanOpt(get(`/one/two?three=four`)))
+	eq(t, RedirectWith(301, `/one%20two/`), AddSlash{}.HanOpt(get(`/one%20two`)))
+	eq(t, RedirectWith(301, `/example.com/`), AddSlash{}.HanOpt(get(`//example.com`)))
+	eq(t, RedirectWith(302, `/one/`), AddSlash{Status: 302}.HanOpt(get(`/one`)))
+	eq(
+		t,
+		RedirectWith(308, `/one/`),
+		AddSlash{}.HanOpt(ht.NewRequest(http.MethodPost, `/one`, nil)),
+	)
+
+	rew := ht.NewRecorder()
+	eq(t, false, AddSlash{}.ServedHTTP(rew, get(`/one/`)))
+
+	rew = ht.NewRecorder()
+	AddSlash{}.ServeHTTP(rew, get(`/one/`))
+	eq(t, http.StatusNotFound, rew.Code)
+
+	rew = ht.NewRecorder()
+	eq(t, true, AddSlash{}.ServedHTTP(rew, get(`/one?two`)))
+	eq(t, http.StatusMovedPermanently, rew.Code)
+	eq(t, `/one/?two`, rew.Header().Get(`Location`))
+
+	t.Run(`after files`, func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(filepath.Join(root, `main.css`), `body {}`)
+		han := Coalesce(Dir{Path: root}, AddSlash{})
+
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, get(`/main.css`))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `body {}`, rew.Body.String())
+
+		rew = ht.NewRecorder()
+		han.ServeHTTP(rew, get(`/one`))
+		eq(t, http.StatusMovedPermanently, rew.Code)
+		eq(t, `/one/`, rew.Header().Get(`Location`))
+	})
+}
+
+func TestStripSlash(t *testing.T) {
+	get := func(path string) *http.Request { return ht.NewRequest(http.MethodGet, path, nil) }
+
+	eq(t, nil, StripSlash{}.HanOpt(get(`/`)))
+	eq(t, nil, StripSlash{}.HanOpt(get(`/one`)))
+	eq(t, RedirectWith(301, `/one`), StripSlash{}.HanOpt(get(`/one/`)))
+	eq(t, RedirectWith(301, `/one/two?three=four`), StripSlash{}.HanOpt(get(`/one/two//?three=four`)))
+	eq(t, RedirectWith(301, `/example.com`), StripSlash{}.HanOpt(get(`//example.com/`)))
+	eq(
+		t,
+		RedirectWith(308, `/one`),
+		StripSlash{}.HanOpt(ht.NewRequest(http.MethodPut, `/one/`, nil)),
+	)
+
+	rew := ht.NewRecorder()
+	StripSlash{}.ServeHTTP(rew, get(`/one/`))
+	eq(t, http.StatusMovedPermanently, rew.Code)
+	eq(t, `/one`, rew.Header().Get(`Location`))
+}
@@ -0,0 +1,82 @@
+//go:build go1.21
+// +build go1.21
+
+package goh
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+/*
+Returns an implementation of `goh.ErrFunc` which writes error responses like
+`goh.WriteErr`, but logs errors via `log/slog` instead of printing them to the
+standard error stream. Every error is logged at the error level, with the
+fields "method", "path", "status", "wrote", and "error". When the error
+response itself fails, the secondary error is included as "write_error". Stack
+traces, such as those of `goh.ErrPanic`, are included as "stack". When
+`goh.RedactErrors` is in effect, the error ID is included as "error_id". The
+ID assigned by `goh.RequestId`, if any, is included as "request_id".
+
+When the logger is nil, uses `slog.Default()`. Example usage:
+
+	goh.HandleErr = goh.SlogErr(logger)
+
+Requires Go 1.21 or later.
+*/
+func SlogErr(logger *slog.Logger) ErrFunc {
+	return func(rew http.ResponseWriter, req *http.Request, err error, wrote bool) {
+		if err == nil {
+			return
+		}
+
+		status := ErrHttpStatus(err)
+		attrs := make([]slog.Attr, 0, 8)
+		ctx := context.Background()
+
+		if req != nil {
+			ctx = req.Context()
+			attrs = append(attrs, slog.String(`method`, req.Method))
+			if req.URL != nil {
+				attrs = append(attrs, slog.String(`path`, req.URL.Path))
+			}
+			if id := RequestIdOf(req); id != `` {
+				attrs = append(attrs, slog.String(`request_id`, id))
+			}
+		}
+
+		attrs = append(
+			attrs,
+			slog.Int(`status`, status),
+			slog.Bool(`wrote`, wrote),
+			slog.Any(`error`, err),
+		)
+		if stack := errStack(err); stack != `` {
+			attrs = append(attrs, slog.String(`stack`, stack))
+		}
+
+		if !wrote {
+			Head{Header: errHeader(err)}.writeHeaders(rew)
+
+			out := err
+			if RedactErrors && status >= http.StatusInternalServerError {
+				id, _ := errId(err)
+				rew.Header().Set(HeadErrId, id)
+				attrs = append(attrs, slog.String(`error_id`, id))
+				out = ErrRedacted{Status: status, Id: id}
+			}
+
+			inner := writeErrResponse(rew, req, out, status)
+			if inner != nil {
+				attrs = append(attrs, slog.Any(`write_error`, inner))
+			}
+		}
+
+		log := logger
+		if log == nil {
+			log = slog.Default()
+		}
+		log.LogAttrs(ctx, slog.LevelError, `error while serving HTTP request`, attrs...)
+	}
+}
@@ -0,0 +1,79 @@
+//go:build go1.21
+// +build go1.21
+
+package goh
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+func TestSlogErr(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	errFunc := SlogErr(logger)
+
+	rew := ht.NewRecorder()
+	errFunc(rew, ht.NewRequest(http.MethodPost, `/one`, nil), ErrNotFound(`user not found`), false)
+
+	eq(t, http.StatusNotFound, rew.Code)
+	eq(t, `user not found`, rew.Body.String())
+
+	var entry Dict
+	try(json.Unmarshal(buf.Bytes(), &entry))
+	delete(entry, `time`)
+
+	eq(
+		t,
+		Dict{
+			`level`:  `ERROR`,
+			`msg`:    `error while serving HTTP request`,
+			`method`: http.MethodPost,
+			`path`:   `/one`,
+			`status`: float64(http.StatusNotFound),
+			`wrote`:  false,
+			`error`:  `user not found`,
+		},
+		entry,
+	)
+}
+
+func TestSlogErr_wrote(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rew := ht.NewRecorder()
+	SlogErr(logger)(rew, nil, errors.New(`fail`), true)
+
+	eq(t, ``, rew.Body.String())
+
+	var entry Dict
+	try(json.Unmarshal(buf.Bytes(), &entry))
+	eq(t, true, entry[`wrote`])
+	eq(t, `fail`, entry[`error`])
+	eq(t, nil, entry[`method`])
+}
+
+func TestSlogErr_RedactErrors(t *testing.T) {
+	defer func(prev bool) { RedactErrors = prev }(RedactErrors)
+	RedactErrors = true
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rew := ht.NewRecorder()
+	SlogErr(logger)(rew, nil, errors.New(`secret`), false)
+
+	var entry Dict
+	try(json.Unmarshal(buf.Bytes(), &entry))
+
+	id := rew.Header().Get(HeadErrId)
+	eq(t, id, entry[`error_id`])
+	eq(t, `secret`, entry[`error`])
+	eq(t, `internal server error (error id: `+id+`)`, rew.Body.String())
+}
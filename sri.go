@@ -0,0 +1,47 @@
+package goh
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+/*
+Returns a Subresource Integrity digest of the given content, such as
+"sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=", for use in "integrity"
+attributes of "<script>" and "<link>" elements.
+*/
+func Integrity(src []byte) string {
+	sum := sha256.Sum256(src)
+	return `sha256-` + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+/*
+Returns a Subresource Integrity digest of `.Body`, which is exactly what this
+handler serves. See `goh.Integrity`.
+*/
+func (self Bytes) Integrity() string { return Integrity(self.Body) }
+
+/*
+Returns a Subresource Integrity digest of the file content. Always uses
+`.Plain`, since browsers verify the content after decoding. See
+`goh.Integrity`.
+*/
+func (self MemFile) Integrity() string { return self.Plain.Integrity() }
+
+/*
+Returns a Subresource Integrity digest of the preloaded file at the given path
+relative to `.Dir.Path`, such as "one/app.js", or an empty string if the file
+is not preloaded. A leading slash is ignored. Intended for templates:
+
+	tmpl.Funcs(template.FuncMap{`integrity`: mem.Integrity})
+
+	<script src="/app.js" integrity="{{integrity "app.js"}}"></script>
+*/
+func (self MemDir) Integrity(name string) string {
+	file, ok := self.Files[strings.TrimPrefix(name, `/`)]
+	if !ok {
+		return ``
+	}
+	return file.Integrity()
+}
@@ -0,0 +1,22 @@
+package goh
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIntegrity(t *testing.T) {
+	eq(t, `sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=`, Integrity(nil))
+	eq(t, `sha256-ungWv48Bz+pBQUDeXa4iI7ADYaOWF3qctBD/YfIAFa0=`, Integrity([]byte(`abc`)))
+	eq(t, Integrity([]byte(`abc`)), BytesOk([]byte(`abc`)).Integrity())
+
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `one/app.js`), `abc`)
+
+	mem, err := MemDir{Dir: Dir{Path: root}, Gzip: true}.Load()
+	try(err)
+
+	eq(t, Integrity([]byte(`abc`)), mem.Integrity(`one/app.js`))
+	eq(t, Integrity([]byte(`abc`)), mem.Integrity(`/one/app.js`))
+	eq(t, ``, mem.Integrity(`missing.js`))
+}
@@ -0,0 +1,173 @@
+package goh
+
+import (
+	"math"
+	"net/http"
+	"runtime"
+	rtm "runtime/metrics"
+)
+
+/*
+HTTP handler that serves runtime statistics as JSON, similar to `expvar`.
+Intended for lightweight debugging endpoints:
+
+	var han = goh.RuntimeStats{
+		Filter:   goh.GlobFilter{Include: []string{`/gc/**`, `/sched/**`}},
+		MemStats: true,
+	}
+
+The response includes the metrics of `runtime/metrics` allowed by `.Filter`,
+keyed by name, such as "/gc/heap/allocs:bytes". Nil `.Filter` includes all
+metrics. Histograms are encoded as `goh.StatsHistogram`. When `.MemStats` is
+true, the response also includes selected fields of `runtime.MemStats`, see
+`goh.MemStats`. Since reading them briefly stops the world, this is opt-in.
+Other fields are used as in `goh.Json`.
+
+Such endpoints reveal implementation details, and should not be publicly
+accessible.
+*/
+type RuntimeStats struct {
+	Status       int
+	Header       http.Header
+	ErrFunc      ErrFunc
+	AppendHeader bool
+	Filter       Filter
+	MemStats     bool
+}
+
+// Body served by `goh.RuntimeStats`. `.MemStats` is nil unless requested.
+type RuntimeStatsBody struct {
+	Goroutines int                    `json:"goroutines"`
+	Metrics    map[string]interface{} `json:"metrics"`
+	MemStats   *MemStats              `json:"memStats,omitempty"`
+}
+
+// Selected fields of `runtime.MemStats`, served by `goh.RuntimeStats`.
+type MemStats struct {
+	Alloc        uint64 `json:"alloc"`
+	TotalAlloc   uint64 `json:"totalAlloc"`
+	Sys          uint64 `json:"sys"`
+	Mallocs      uint64 `json:"mallocs"`
+	Frees        uint64 `json:"frees"`
+	HeapAlloc    uint64 `json:"heapAlloc"`
+	HeapSys      uint64 `json:"heapSys"`
+	HeapInuse    uint64 `json:"heapInuse"`
+	HeapObjects  uint64 `json:"heapObjects"`
+	StackInuse   uint64 `json:"stackInuse"`
+	NextGC       uint64 `json:"nextGC"`
+	PauseTotalNs uint64 `json:"pauseTotalNs"`
+	NumGC        uint32 `json:"numGC"`
+}
+
+/*
+JSON representation of `runtime/metrics.Float64Histogram`. `.Buckets` are the
+bucket boundaries, with infinite boundaries encoded as null.
+*/
+type StatsHistogram struct {
+	Counts  []uint64   `json:"counts"`
+	Buckets []*float64 `json:"buckets"`
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self RuntimeStats) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self RuntimeStats) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.RuntimeStats`, self.serveHTTP)
+}
+
+func (self RuntimeStats) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	Json{
+		Status:       self.Status,
+		Header:       self.Header,
+		ErrFunc:      self.ErrFunc,
+		AppendHeader: self.AppendHeader,
+		Body:         self.Body(),
+	}.serveHTTP(rew, req)
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self RuntimeStats) Han(*http.Request) http.Handler { return self }
+
+// Reads the current statistics. See `goh.RuntimeStats`.
+func (self RuntimeStats) Body() RuntimeStatsBody {
+	out := RuntimeStatsBody{
+		Goroutines: runtime.NumGoroutine(),
+		Metrics:    self.metrics(),
+	}
+	if self.MemStats {
+		out.MemStats = readMemStats()
+	}
+	return out
+}
+
+func (self RuntimeStats) metrics() map[string]interface{} {
+	var samples []rtm.Sample
+	for _, desc := range rtm.All() {
+		if self.Filter == nil || self.Filter.Allow(desc.Name) {
+			samples = append(samples, rtm.Sample{Name: desc.Name})
+		}
+	}
+	rtm.Read(samples)
+
+	out := make(map[string]interface{}, len(samples))
+	for _, sample := range samples {
+		val := statsValue(sample.Value)
+		if val != nil {
+			out[sample.Name] = val
+		}
+	}
+	return out
+}
+
+func statsValue(val rtm.Value) interface{} {
+	switch val.Kind() {
+	case rtm.KindUint64:
+		return val.Uint64()
+
+	case rtm.KindFloat64:
+		return statsFloat(val.Float64())
+
+	case rtm.KindFloat64Histogram:
+		src := val.Float64Histogram()
+		out := StatsHistogram{Counts: src.Counts, Buckets: make([]*float64, len(src.Buckets))}
+		for ind, val := range src.Buckets {
+			out.Buckets[ind] = statsFloat(val)
+		}
+		return out
+
+	default:
+		return nil
+	}
+}
+
+// JSON doesn't support infinities or NaN, which are encoded as null.
+func statsFloat(val float64) *float64 {
+	if math.IsInf(val, 0) || math.IsNaN(val) {
+		return nil
+	}
+	return &val
+}
+
+func readMemStats() *MemStats {
+	var src runtime.MemStats
+	runtime.ReadMemStats(&src)
+
+	return &MemStats{
+		Alloc:        src.Alloc,
+		TotalAlloc:   src.TotalAlloc,
+		Sys:          src.Sys,
+		Mallocs:      src.Mallocs,
+		Frees:        src.Frees,
+		HeapAlloc:    src.HeapAlloc,
+		HeapSys:      src.HeapSys,
+		HeapInuse:    src.HeapInuse,
+		HeapObjects:  src.HeapObjects,
+		StackInuse:   src.StackInuse,
+		NextGC:       src.NextGC,
+		PauseTotalNs: src.PauseTotalNs,
+		NumGC:        src.NumGC,
+	}
+}
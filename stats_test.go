@@ -0,0 +1,50 @@
+package goh
+
+import (
+	"encoding/json"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRuntimeStats(t *testing.T) {
+	type body struct {
+		Goroutines int                        `json:"goroutines"`
+		Metrics    map[string]json.RawMessage `json:"metrics"`
+		MemStats   *MemStats                  `json:"memStats"`
+	}
+
+	serve := func(han RuntimeStats) body {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, typeJsonUtf8, rew.Header().Get(HeadType))
+
+		var out body
+		try(json.Unmarshal(rew.Body.Bytes(), &out))
+		return out
+	}
+
+	t.Run(`all`, func(t *testing.T) {
+		out := serve(RuntimeStats{})
+		eq(t, true, out.Goroutines > 0)
+		eq(t, true, len(out.Metrics) > 0)
+		eq(t, true, out.MemStats == nil)
+	})
+
+	t.Run(`filter`, func(t *testing.T) {
+		out := serve(RuntimeStats{Filter: GlobFilter{Include: []string{`/gc/**`}}, MemStats: true})
+		eq(t, true, len(out.Metrics) > 0)
+		for key := range out.Metrics {
+			eq(t, true, strings.HasPrefix(key, `/gc/`))
+		}
+		eq(t, true, out.MemStats != nil && out.MemStats.Sys > 0)
+	})
+
+	t.Run(`none`, func(t *testing.T) {
+		out := serve(RuntimeStats{Filter: FilterFunc(func(string) bool { return false })})
+		eq(t, 0, len(out.Metrics))
+	})
+}
@@ -0,0 +1,124 @@
+package goh
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Default value of `goh.Tar.Name` when `.Gzip` is false.
+const DefaultTarName = `archive.tar`
+
+// Default value of `goh.Tar.Name` when `.Gzip` is true.
+const DefaultTarGzipName = `archive.tar.gz`
+
+/*
+HTTP handler that streams a tar archive of the files in the given paths, which
+may be directories or individual files. When `.Gzip` is true, the archive is
+gzip-compressed. Intended for exporting directory trees, for example for
+backups or artifact transfer. Example usage:
+
+	var han = goh.Tar{Paths: []string{`artifacts`}, Gzip: true}
+
+The selection of files is the same as for `goh.Zip`, including the handling of
+`.Filter` and `.AllowDotfiles`, and so is the error handling.
+
+The response has "Content-Type: application/x-tar", or "application/gzip" when
+`.Gzip` is true, and "Content-Disposition: attachment" with the file name
+`.Name`, defaulting to `goh.DefaultTarName` or `goh.DefaultTarGzipName`.
+Either can be overridden via `.Header`.
+*/
+type Tar struct {
+	Status        int
+	Header        http.Header
+	ErrFunc       ErrFunc
+	AppendHeader  bool
+	Paths         []string
+	Filter        Filter
+	Name          string
+	Gzip          bool
+	AllowDotfiles bool
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self Tar) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self Tar) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Tar`, self.serveHTTP)
+}
+
+func (self Tar) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	head := self.Head()
+
+	files, err := self.archive().files()
+	if err != nil {
+		head.fail(rew, req, ErrInfo{Err: err, Handler: `goh.Tar`})
+		return
+	}
+
+	header := rew.Header()
+	if self.Gzip {
+		header.Set(HeadType, TypeGzip)
+		header.Set(`Content-Disposition`, ContentDisposition(DispositionAttachment, orStr(self.Name, DefaultTarGzipName)))
+	} else {
+		header.Set(HeadType, TypeTar)
+		header.Set(`Content-Disposition`, ContentDisposition(DispositionAttachment, orStr(self.Name, DefaultTarName)))
+	}
+	head.Write(rew)
+
+	writer := spyingWriter{Writer: rew}
+	err = self.write(&writer, files)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write tar archive: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.Tar`, head.sentStatusIf(writer.wrote), writer.size, writer.wrote})
+	}
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Tar) Han(*http.Request) http.Handler { return self }
+
+func (self Tar) archive() archive {
+	return archive{self.Paths, self.Filter, self.AllowDotfiles}
+}
+
+func (self Tar) write(out io.Writer, files []archiveFile) error {
+	if !self.Gzip {
+		return writeTar(out, files)
+	}
+
+	writer := gzip.NewWriter(out)
+	err := writeTar(writer, files)
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func writeTar(out io.Writer, files []archiveFile) error {
+	writer := tar.NewWriter(out)
+
+	for _, file := range files {
+		head, err := tar.FileInfoHeader(file.Info, ``)
+		if err != nil {
+			return err
+		}
+		head.Name = file.Name
+
+		err = writer.WriteHeader(head)
+		if err != nil {
+			return err
+		}
+
+		err = copyFile(writer, file.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
@@ -0,0 +1,81 @@
+package goh
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	_ = http.Handler(Tar{})
+	_ = Han(Tar{}.Han)
+)
+
+func TestTar(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `static/one.txt`), `one`)
+	writeFile(filepath.Join(root, `static/two/three.txt`), `three`)
+	writeFile(filepath.Join(root, `static/.env`), `secret`)
+
+	han := Tar{Paths: []string{filepath.Join(root, `static`)}}
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, TypeTar, rew.Header().Get(HeadType))
+	eq(t, `attachment; filename="archive.tar"`, rew.Header().Get(`Content-Disposition`))
+	eq(
+		t,
+		map[string]string{`static/one.txt`: `one`, `static/two/three.txt`: `three`},
+		readTar(rew.Body),
+	)
+}
+
+func TestTar_Gzip(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `one.txt`), `one`)
+
+	han := Tar{Paths: []string{filepath.Join(root, `one.txt`)}, Gzip: true, Name: `one.tgz`}
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+
+	eq(t, TypeGzip, rew.Header().Get(HeadType))
+	eq(t, `attachment; filename="one.tgz"`, rew.Header().Get(`Content-Disposition`))
+
+	reader, err := gzip.NewReader(rew.Body)
+	try(err)
+	eq(t, map[string]string{`one.txt`: `one`}, readTar(reader))
+}
+
+func TestTar_error(t *testing.T) {
+	rew := ht.NewRecorder()
+	Tar{Paths: []string{filepath.Join(t.TempDir(), `missing`)}}.ServeHTTP(rew, nil)
+
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, ``, rew.Header().Get(`Content-Disposition`))
+}
+
+func readTar(src io.Reader) map[string]string {
+	reader := tar.NewReader(src)
+	out := map[string]string{}
+
+	for {
+		head, err := reader.Next()
+		if err == io.EOF {
+			return out
+		}
+		try(err)
+
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, reader)
+		try(err)
+		out[head.Name] = buf.String()
+	}
+}
@@ -0,0 +1,409 @@
+package goh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+)
+
+/*
+HTTP handler that renders an HTML template with the given data, setting the
+content type `goh.TypeHtml`. The field `.Charset` is appended to the content
+type, defaulting to `goh.DefaultCharset`. When `.Name` is set, executes the
+template with that name, associated with `.Tmpl`, otherwise executes `.Tmpl`
+itself. Example usage:
+
+	var tmpl = template.Must(template.ParseFiles(`index.html`))
+
+	func handler(req *http.Request) http.Handler {
+		return goh.TemplateOk(tmpl, someData)
+	}
+
+The template is rendered into a buffer before writing the response, so that
+template errors result in a proper error response via `.ErrFunc`, rather than
+a partial page. For composing pages from layouts and partials, see
+`goh.Templates`.
+
+Supports the template function "cspNonce", which renders the nonce assigned to
+the request by `goh.CspNonce`, and "flush", see below. Templates using these
+functions must define them before parsing, see `goh.TemplateFuncs`.
+`goh.Templates` does this automatically.
+
+When `.Stream` is true, the template is rendered directly into the response,
+which improves the time to first byte for large pages. Each call to "flush"
+sends the output rendered so far to the client, for example after the "<head>"
+section, which allows the browser to start loading styles and scripts while
+the rest of the page is rendered:
+
+	<head>...</head>{{flush}}<body>...</body>
+
+When streaming, template errors can't change the already written status, and
+are passed to `.ErrFunc` with `wrote = true`.
+*/
+type Template struct {
+	Status          int
+	Header          http.Header
+	ErrFunc         ErrFunc
+	AppendHeader    bool
+	NoContentLength bool
+	Language        string
+	Charset         string
+	Tmpl            *template.Template
+	Name            string
+	Data            interface{}
+	Stream          bool
+
+	// Template loading error, used by `goh.Templates` in dev mode.
+	err error
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self Template) Head() Head {
+	return Head{self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language}
+}
+
+// Implement `http.Handler`.
+func (self Template) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Template`, self.serveHTTP)
+}
+
+func (self Template) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	if self.Stream {
+		self.serveStream(rew, req)
+		return
+	}
+
+	head := self.Head()
+
+	body, err := self.Render(req)
+	if err != nil {
+		head.fail(rew, req, ErrInfo{err, `goh.Template`, 0, 0, false})
+		return
+	}
+
+	rew.Header().Set(HeadType, withCharset(TypeHtml, self.Charset))
+	head.Write(rew)
+
+	size, err := rew.Write(body)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write response HTML: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.Template`, head.sentStatusIf(size > 0), int64(size), size > 0})
+	}
+}
+
+func (self Template) serveStream(rew http.ResponseWriter, req *http.Request) {
+	head := self.Head()
+
+	tmpl, err := self.template()
+	if err != nil {
+		head.fail(rew, req, ErrInfo{err, `goh.Template`, 0, 0, false})
+		return
+	}
+
+	rew.Header().Set(HeadType, withCharset(TypeHtml, self.Charset))
+	head.Write(rew)
+
+	writer := templateWriter{rew: rew, nonce: []byte(CspNonceOf(req))}
+	err = self.execute(tmpl, &writer)
+	if err != nil {
+		head.fail(rew, req, ErrInfo{err, `goh.Template`, head.sentStatusIf(writer.size > 0), writer.size, writer.size > 0})
+	}
+}
+
+// Conforms to `goh.Han`.
+func (self Template) Han(*http.Request) http.Handler { return self }
+
+/*
+Renders the template into a byte slice, as described in `goh.Template`. The
+request is used only for the CSP nonce, and may be nil. Ignores `.Stream`.
+*/
+func (self Template) Render(req *http.Request) ([]byte, error) {
+	tmpl, err := self.template()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = self.execute(tmpl, &buf)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.ReplaceAll(ReplaceCspNonce(buf.Bytes(), req), templateFlushMarker, nil), nil
+}
+
+func (self Template) template() (*template.Template, error) {
+	if self.err != nil {
+		return nil, self.err
+	}
+	if self.Tmpl == nil {
+		return nil, fmt.Errorf(`[goh] missing template %q`, self.Name)
+	}
+	return self.Tmpl, nil
+}
+
+func (self Template) execute(tmpl *template.Template, out io.Writer) error {
+	var err error
+	if self.Name != `` {
+		err = tmpl.ExecuteTemplate(out, self.Name, self.Data)
+	} else {
+		err = tmpl.Execute(out, self.Data)
+	}
+	if err != nil {
+		return fmt.Errorf(`[goh] failed to render template: %w`, err)
+	}
+	return nil
+}
+
+// Shortcut for `goh.TemplateWith(http.StatusOK, tmpl, data)`.
+func TemplateOk(tmpl *template.Template, data interface{}) Template {
+	return TemplateWith(http.StatusOK, tmpl, data)
+}
+
+// Shortcut for `goh.Template` with specific status, template, and data.
+func TemplateWith(status int, tmpl *template.Template, data interface{}) Template {
+	return Template{Status: status, Tmpl: tmpl, Data: data}
+}
+
+/*
+Returns the template functions supported by `goh.Template`, which must be
+defined before parsing. `goh.Templates` defines them automatically. Includes
+"cspNonce", see `goh.CspNonceFuncMap`, and "flush", which flushes the response
+when streaming, see `goh.Template`, and otherwise renders nothing. Example
+usage:
+
+	var tmpl = template.Must(template.New(``).Funcs(goh.TemplateFuncs()).Parse(src))
+*/
+func TemplateFuncs() template.FuncMap {
+	out := CspNonceFuncMap(nil)
+	out[`flush`] = func() template.HTML { return template.HTML(templateFlushMarker) }
+	return out
+}
+
+/*
+Placeholder rendered by "flush", see `goh.TemplateFuncs`. Random for the same
+reasons as the placeholder of "cspNonce".
+*/
+var templateFlushMarker = []byte(`goh_flush_` + NewRequestId())
+
+/*
+Writer used by `goh.Template` when streaming. Replaces the placeholders
+rendered by "cspNonce" and "flush". Relies on each placeholder being written in
+a single call, which is the case for values printed by templates.
+*/
+type templateWriter struct {
+	rew   http.ResponseWriter
+	nonce []byte
+	size  int64
+}
+
+func (self *templateWriter) Write(src []byte) (int, error) {
+	out := len(src)
+
+	for len(src) > 0 {
+		ind := bytes.Index(src, templateFlushMarker)
+		chunk := src
+		if ind >= 0 {
+			chunk = src[:ind]
+		}
+
+		if len(chunk) > 0 {
+			size, err := self.rew.Write(bytes.ReplaceAll(chunk, []byte(cspNonceMarker), self.nonce))
+			self.size += int64(size)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		if ind < 0 {
+			break
+		}
+
+		flusher, _ := self.rew.(http.Flusher)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		src = src[ind+len(templateFlushMarker):]
+	}
+	return out, nil
+}
+
+/*
+Registry of HTML templates composed from layouts and partials, loaded from
+`.FS`, which may be `os.DirFS` or `embed.FS`. Should be created at startup via
+`goh.Templates.Load`:
+
+	var tmpls = goh.MustTemplates(goh.Templates{
+		FS:     os.DirFS(`templates`),
+		Shared: []string{`layouts/*.html`, `partials/*.html`},
+		Pages:  []string{`pages/*.html`},
+		Layout: `layout.html`,
+		Funcs:  template.FuncMap{`upper`: strings.ToUpper},
+	}.Load())
+
+	func handler(req *http.Request) http.Handler {
+		return tmpls.Page(`pages/index.html`, someData)
+	}
+
+`.Shared` and `.Pages` are glob patterns, see `fs.Glob`. The shared templates
+are parsed into every page. Each page is parsed separately, which allows pages
+to define the same blocks with different content, for example:
+
+	<!-- layouts/layout.html -->
+	<html><title>{{block "title" .}}Site{{end}}</title>{{block "content" .}}{{end}}</html>
+
+	<!-- pages/index.html -->
+	{{define "title"}}Home{{end}}
+	{{define "content"}}{{template "nav.html" .}}<p>{{.Text}}</p>{{end}}
+
+Pages are rendered by executing the template named `.Layout`, typically the
+base name of a layout file, which is then filled with the blocks defined by the
+page. When `.Layout` is empty, each page is executed by its base name. Within
+the templates, "cspNonce" and "flush" are always available, see
+`goh.TemplateFuncs`, along with `.Funcs`.
+
+When `.Dev` is true, pages are re-parsed from `.FS` on each call to
+`goh.Templates.Page`, ignoring `.Tmpls`. This allows to edit templates without
+restarting the process, at the cost of performance. Intended for development,
+for example:
+
+	Dev: os.Getenv(`DEV`) != ``,
+*/
+type Templates struct {
+	FS     fs.FS
+	Shared []string
+	Pages  []string
+	Layout string
+	Funcs  template.FuncMap
+	Tmpls  map[string]*template.Template
+	Dev    bool
+}
+
+/*
+Returns a copy with `.Tmpls` populated by parsing the templates. Keys are the
+page paths matched by `.Pages`, such as "pages/index.html".
+*/
+func (self Templates) Load() (Templates, error) {
+	base, err := self.base()
+	if err != nil {
+		return self, err
+	}
+
+	tmpls := map[string]*template.Template{}
+
+	for _, pattern := range self.Pages {
+		names, err := fs.Glob(self.FS, pattern)
+		if err != nil {
+			return self, fmt.Errorf(`[goh] invalid template pattern %q: %w`, pattern, err)
+		}
+
+		for _, name := range names {
+			tmpl, err := base.Clone()
+			if err != nil {
+				return self, err
+			}
+
+			tmpls[name], err = self.parsePage(tmpl, name)
+			if err != nil {
+				return self, err
+			}
+		}
+	}
+
+	self.Tmpls = tmpls
+	return self, nil
+}
+
+/*
+Returns the parsed template set for the given page path, or nil. In dev mode,
+re-parses the page from `.FS`, returning nil if parsing fails; see
+`goh.Templates.Reload` for the error.
+*/
+func (self Templates) Lookup(name string) *template.Template {
+	if self.Dev {
+		tmpl, _ := self.Reload(name)
+		return tmpl
+	}
+	return self.Tmpls[name]
+}
+
+/*
+Parses the shared templates and the given page from `.FS`, ignoring `.Tmpls`.
+Used in dev mode, see `.Dev`. Returns nil without an error if the page doesn't
+exist.
+*/
+func (self Templates) Reload(name string) (*template.Template, error) {
+	_, err := fs.Stat(self.FS, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := self.base()
+	if err != nil {
+		return nil, err
+	}
+	return self.parsePage(base, name)
+}
+
+func (self Templates) base() (*template.Template, error) {
+	base := template.New(``).Funcs(TemplateFuncs()).Funcs(self.Funcs)
+
+	if len(self.Shared) > 0 {
+		_, err := base.ParseFS(self.FS, self.Shared...)
+		if err != nil {
+			return nil, fmt.Errorf(`[goh] failed to parse shared templates: %w`, err)
+		}
+	}
+	return base, nil
+}
+
+func (self Templates) parsePage(tmpl *template.Template, name string) (*template.Template, error) {
+	_, err := tmpl.ParseFS(self.FS, name)
+	if err != nil {
+		return nil, fmt.Errorf(`[goh] failed to parse template %q: %w`, name, err)
+	}
+	return tmpl, nil
+}
+
+// Shortcut for `goh.Templates.PageWith(http.StatusOK, name, data)`.
+func (self Templates) Page(name string, data interface{}) Template {
+	return self.PageWith(http.StatusOK, name, data)
+}
+
+/*
+Returns a handler that renders the given page, with the given status and data.
+When the page is not found, or in dev mode fails to parse, the handler responds
+with an error via `goh.Template.ErrFunc`.
+*/
+func (self Templates) PageWith(status int, name string, data interface{}) Template {
+	out := Template{Status: status, Name: name, Data: data}
+
+	if self.Dev {
+		out.Tmpl, out.err = self.Reload(name)
+	} else {
+		out.Tmpl = self.Tmpls[name]
+	}
+
+	if out.Tmpl != nil {
+		out.Name = orStr(self.Layout, path.Base(name))
+	}
+	return out
+}
+
+/*
+Panics if the error is non-nil, otherwise returns the value. Intended for
+initializing global variables, see `goh.Templates`.
+*/
+func MustTemplates(val Templates, err error) Templates {
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
@@ -0,0 +1,206 @@
+package goh
+
+import (
+	"html/template"
+	"net/http"
+	ht "net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var (
+	_ = http.Handler(Template{})
+	_ = Han(Template{}.Han)
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl := template.Must(template.New(`one`).Parse(`<p>{{.}}</p>{{define "two"}}<b>{{.}}</b>{{end}}`))
+
+	t.Run(`default`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		TemplateWith(http.StatusCreated, tmpl, `<hello>`).ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusCreated, rew.Code)
+		eq(t, `text/html; charset=utf-8`, rew.Header().Get(HeadType))
+		eq(t, `<p>&lt;hello&gt;</p>`, rew.Body.String())
+	})
+
+	t.Run(`name`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Template{Tmpl: tmpl, Name: `two`, Data: `hello`}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, `<b>hello</b>`, rew.Body.String())
+	})
+
+	t.Run(`error`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Template{Tmpl: tmpl, Name: `missing`, Header: http.Header{`One`: {`two`}}}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusInternalServerError, rew.Code)
+		eq(t, ``, rew.Header().Get(`One`))
+		eq(t, true, strings.Contains(rew.Body.String(), `missing`))
+	})
+
+	t.Run(`nil`, func(t *testing.T) {
+		_, err := Template{}.Render(nil)
+		eq(t, `[goh] missing template ""`, err.Error())
+	})
+}
+
+func templatesFS() fstest.MapFS {
+	return fstest.MapFS{
+		`layouts/layout.html`: {Data: []byte(`<title>{{block "title" .}}Site{{end}}</title>{{block "content" .}}{{end}}`)},
+		`partials/nav.html`:   {Data: []byte(`<nav>{{upper "nav"}}</nav>`)},
+		`pages/index.html`:    {Data: []byte(`{{define "title"}}Home{{end}}{{define "content"}}{{template "nav.html"}}<p>{{.}}</p>{{end}}`)},
+		`pages/about.html`:    {Data: []byte(`{{define "content"}}<p nonce="{{cspNonce}}">about</p>{{end}}`)},
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	tmpls := MustTemplates(Templates{
+		FS:     templatesFS(),
+		Shared: []string{`layouts/*.html`, `partials/*.html`},
+		Pages:  []string{`pages/*.html`},
+		Layout: `layout.html`,
+		Funcs:  template.FuncMap{`upper`: strings.ToUpper},
+	}.Load())
+
+	eq(t, 2, len(tmpls.Tmpls))
+	eq(t, true, tmpls.Lookup(`pages/index.html`) != nil)
+	eq(t, true, tmpls.Lookup(`index.html`) == nil)
+
+	test := func(han http.Handler, req *http.Request, status int, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		han.ServeHTTP(rew, req)
+		eq(t, status, rew.Code)
+		eq(t, exp, rew.Body.String())
+	}
+
+	test(tmpls.Page(`pages/index.html`, `hello`), pathReq(`/`), http.StatusOK, `<title>Home</title><nav>NAV</nav><p>hello</p>`)
+	test(tmpls.Page(`pages/about.html`, nil), pathReq(`/`), http.StatusOK, `<title>Site</title><p nonce="">about</p>`)
+	test(tmpls.PageWith(http.StatusNotFound, `pages/index.html`, `404`), pathReq(`/`), http.StatusNotFound, `<title>Home</title><nav>NAV</nav><p>404</p>`)
+
+	t.Run(`nonce`, func(t *testing.T) {
+		han := tmpls.Page(`pages/about.html`, nil)
+		test(han, pathReq(`/`), http.StatusOK, `<title>Site</title><p nonce="">about</p>`)
+
+		rew := ht.NewRecorder()
+		CspNonce{Handler: tmpls.Page(`pages/about.html`, nil)}.ServeHTTP(rew, pathReq(`/`))
+
+		nonce := strings.TrimSuffix(strings.TrimPrefix(rew.Header().Get(`Content-Security-Policy`), `script-src 'nonce-`), `' 'strict-dynamic'; object-src 'none'; base-uri 'none'`)
+		eq(t, 22, len(nonce))
+		eq(t, `<title>Site</title><p nonce="`+nonce+`">about</p>`, rew.Body.String())
+	})
+
+	t.Run(`missing`, func(t *testing.T) {
+		_, err := tmpls.Page(`pages/missing.html`, nil).Render(nil)
+		eq(t, `[goh] missing template "pages/missing.html"`, err.Error())
+	})
+
+	t.Run(`without layout`, func(t *testing.T) {
+		tmpls := MustTemplates(Templates{FS: templatesFS(), Pages: []string{`partials/*.html`}, Funcs: template.FuncMap{`upper`: strings.ToUpper}}.Load())
+		test(tmpls.Page(`partials/nav.html`, nil), pathReq(`/`), http.StatusOK, `<nav>NAV</nav>`)
+	})
+
+	t.Run(`invalid`, func(t *testing.T) {
+		_, err := Templates{FS: templatesFS(), Pages: []string{`partials/*.html`}}.Load()
+		eq(t, true, err != nil)
+	})
+}
+
+func TestTemplatesDev(t *testing.T) {
+	src := templatesFS()
+	tmpls := Templates{
+		FS:     src,
+		Shared: []string{`layouts/*.html`, `partials/*.html`},
+		Layout: `layout.html`,
+		Funcs:  template.FuncMap{`upper`: strings.ToUpper},
+		Dev:    true,
+	}
+
+	test := func(status int, exp string) {
+		t.Helper()
+		rew := ht.NewRecorder()
+		tmpls.Page(`pages/index.html`, `hello`).ServeHTTP(rew, pathReq(`/`))
+		eq(t, status, rew.Code)
+		eq(t, true, strings.Contains(rew.Body.String(), exp))
+	}
+
+	test(http.StatusOK, `<title>Home</title><nav>NAV</nav><p>hello</p>`)
+
+	src[`pages/index.html`] = &fstest.MapFile{Data: []byte(`{{define "title"}}Index{{end}}`)}
+	test(http.StatusOK, `<title>Index</title>`)
+
+	src[`partials/nav.html`] = &fstest.MapFile{Data: []byte(`{{`)}
+	test(http.StatusInternalServerError, `failed to parse shared templates`)
+	eq(t, true, tmpls.Lookup(`pages/index.html`) == nil)
+
+	src[`partials/nav.html`] = &fstest.MapFile{Data: []byte(`<nav></nav>`)}
+	eq(t, true, tmpls.Lookup(`pages/index.html`) != nil)
+	eq(t, true, tmpls.Lookup(`pages/missing.html`) == nil)
+
+	_, err := tmpls.Page(`pages/missing.html`, nil).Render(nil)
+	eq(t, `[goh] missing template "pages/missing.html"`, err.Error())
+}
+
+// Records the body size at each flush.
+type flushRecorder struct {
+	*ht.ResponseRecorder
+	flushes []int
+}
+
+func (self *flushRecorder) Flush() {
+	self.flushes = append(self.flushes, self.Body.Len())
+	self.ResponseRecorder.Flush()
+}
+
+func TestTemplateStream(t *testing.T) {
+	tmpl := template.Must(template.New(``).Funcs(TemplateFuncs()).Parse(
+		`<head nonce="{{cspNonce}}"></head>{{flush}}<p>{{.}}</p>{{flush}}<p>{{.}}{{flush}}</p>`,
+	))
+
+	t.Run(`stream`, func(t *testing.T) {
+		rew := &flushRecorder{ResponseRecorder: ht.NewRecorder()}
+		Template{Tmpl: tmpl, Data: `one`, Stream: true}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusOK, rew.Code)
+		eq(t, `text/html; charset=utf-8`, rew.Header().Get(HeadType))
+		eq(t, `<head nonce=""></head><p>one</p><p>one</p>`, rew.Body.String())
+		eq(t, []int{22, 32, 38}, rew.flushes)
+	})
+
+	t.Run(`nonce`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		CspNonce{Handler: Template{Tmpl: tmpl, Data: `one`, Stream: true}}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, true, strings.HasPrefix(rew.Body.String(), `<head nonce="`))
+		eq(t, false, strings.HasPrefix(rew.Body.String(), `<head nonce=""`))
+		eq(t, false, strings.Contains(rew.Body.String(), `goh_`))
+	})
+
+	t.Run(`buffered`, func(t *testing.T) {
+		rew := &flushRecorder{ResponseRecorder: ht.NewRecorder()}
+		TemplateOk(tmpl, `one`).ServeHTTP(rew, pathReq(`/`))
+		eq(t, `<head nonce=""></head><p>one</p><p>one</p>`, rew.Body.String())
+		eq(t, 0, len(rew.flushes))
+	})
+
+	t.Run(`error`, func(t *testing.T) {
+		fail := func() (string, error) { return ``, ErrConflict(`fail`) }
+		tmpl := template.Must(template.New(``).Funcs(TemplateFuncs()).Funcs(template.FuncMap{`fail`: fail}).Parse(`<p>one</p>{{flush}}{{fail}}`))
+
+		var info ErrInfo
+		errFunc := func(_ http.ResponseWriter, _ *http.Request, err error, _ bool) {
+			info, _ = ErrInfoOf(err)
+		}
+
+		rew := ht.NewRecorder()
+		Template{Tmpl: tmpl, Stream: true, Status: http.StatusAccepted, ErrFunc: errFunc}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusAccepted, rew.Code)
+		eq(t, `<p>one</p>`, rew.Body.String())
+		eq(t, http.StatusAccepted, info.Status)
+		eq(t, int64(10), info.Written)
+		eq(t, true, info.Wrote)
+	})
+}
@@ -0,0 +1,193 @@
+package goh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+/*
+HTTP handler that serves `.Handler` with a time limit, similar to
+`http.TimeoutHandler`. Intended for declarative per-route timeouts:
+
+	var han = goh.Timeout{
+		After:     5 * time.Second,
+		Handler:   slowHandler,
+		OnTimeout: goh.JsonWith(http.StatusServiceUnavailable, errBody),
+	}
+
+`.Handler` runs in a separate goroutine with a request context that's canceled
+after `.After`. Its response is buffered, and written to the client only if it
+completes in time. On timeout, the buffered response is discarded, and the
+client receives `.OnTimeout`. When `.OnTimeout` is nil, the error is handled by
+`.ErrFunc`, defaulting to `goh.HandleErr`, with `goh.ErrStatus` 503 wrapping
+`http.ErrHandlerTimeout`. The same happens when the client disconnects before
+the handler completes. Subsequent writes by `.Handler` fail with
+`http.ErrHandlerTimeout`, and should make it stop.
+
+Since the response is buffered, `.Handler` can't stream, and flushing has no
+effect. When `.After` is not positive, `.Handler` is served directly without a
+limit. When `.Handler` is nil, responds with `goh.NotFound`. Panics in
+`.Handler` before the timeout are propagated to the serving goroutine.
+*/
+type Timeout struct {
+	After     time.Duration
+	Handler   http.Handler
+	OnTimeout http.Handler
+	ErrFunc   ErrFunc
+}
+
+// Implement `http.Handler`.
+func (self Timeout) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Timeout`, self.serveHTTP)
+}
+
+func (self Timeout) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	han := orNotFound(self.Handler)
+	if self.After <= 0 {
+		han.ServeHTTP(rew, req)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), self.After)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	writer := &timeoutWriter{ctx: ctx, header: cloneHeader(rew.Header())}
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+
+	go func() {
+		defer func() {
+			val := recover()
+			if val != nil {
+				panicked <- val
+			}
+		}()
+		han.ServeHTTP(writer, req)
+		close(done)
+	}()
+
+	select {
+	case val := <-panicked:
+		panic(val)
+
+	case <-done:
+		if writer.expire() {
+			self.timedOut(rew, req, ctx.Err())
+			return
+		}
+		writer.flushTo(rew, req, self.ErrFunc)
+
+	case <-ctx.Done():
+		writer.expire()
+		self.timedOut(rew, req, ctx.Err())
+	}
+}
+
+func (self Timeout) timedOut(rew http.ResponseWriter, req *http.Request, cause error) {
+	if self.OnTimeout != nil {
+		self.OnTimeout.ServeHTTP(rew, req)
+		return
+	}
+
+	err := ErrStatus{
+		Status: http.StatusServiceUnavailable,
+		Err:    fmt.Errorf(`[goh] handler didn't complete within %v (%v): %w`, self.After, cause, http.ErrHandlerTimeout),
+	}
+	Head{ErrFunc: self.ErrFunc}.fail(rew, req, ErrInfo{err, `goh.Timeout`, 0, 0, false})
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Timeout) Han(*http.Request) http.Handler { return self }
+
+/*
+Buffers the response of the handler wrapped by `goh.Timeout`. Safe for
+concurrent use by the handler goroutine and the serving goroutine, which may
+time out while the handler is still writing.
+*/
+type timeoutWriter struct {
+	sync.Mutex
+	ctx      context.Context
+	header   http.Header
+	status   int
+	buf      bytes.Buffer
+	timedOut bool
+}
+
+func (self *timeoutWriter) Header() http.Header { return self.header }
+
+func (self *timeoutWriter) WriteHeader(status int) {
+	self.Lock()
+	defer self.Unlock()
+	if self.status == 0 && !self.expired() {
+		self.status = status
+	}
+}
+
+func (self *timeoutWriter) Write(src []byte) (int, error) {
+	self.Lock()
+	defer self.Unlock()
+	if self.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if self.status == 0 {
+		self.status = http.StatusOK
+	}
+	return self.buf.Write(src)
+}
+
+/*
+True if the context has expired, which may be observed by the handler before
+the serving goroutine. Once true, remains true, and the buffered response is
+never flushed. Must be called under lock.
+*/
+func (self *timeoutWriter) expired() bool {
+	if !self.timedOut && self.ctx.Err() != nil {
+		self.timedOut = true
+	}
+	return self.timedOut
+}
+
+// Locking version of `.expired`.
+func (self *timeoutWriter) expire() bool {
+	self.Lock()
+	defer self.Unlock()
+	return self.expired()
+}
+
+/*
+Called after the handler has returned, so the header is no longer mutated.
+The buffered header started as a copy of the outer header, and replaces it
+entirely, preserving any deletions made by the handler. Write errors are
+reported to the given error handler. By then, the status has been sent.
+*/
+func (self *timeoutWriter) flushTo(rew http.ResponseWriter, req *http.Request, errFunc ErrFunc) {
+	target := rew.Header()
+	for key := range target {
+		delete(target, key)
+	}
+	for key, vals := range self.header {
+		target[key] = vals
+	}
+
+	status := self.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	rew.WriteHeader(status)
+
+	// Writing even an empty body fails for statuses such as 204 and 304.
+	if self.buf.Len() == 0 {
+		return
+	}
+
+	size, err := rew.Write(self.buf.Bytes())
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write buffered response: %w`, err)
+		Head{ErrFunc: errFunc}.fail(rew, req, ErrInfo{err, `goh.Timeout`, status, int64(size), true})
+	}
+}
@@ -0,0 +1,128 @@
+package goh
+
+import (
+	"errors"
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+	"time"
+)
+
+var (
+	_ = http.Handler(Timeout{})
+	_ = Han(Timeout{}.Han)
+)
+
+func TestTimeout(t *testing.T) {
+	t.Run(`completed`, func(t *testing.T) {
+		han := Timeout{
+			After: time.Minute,
+			Handler: StringWith(http.StatusCreated, `hello`).WithHeader(
+				http.Header{`One`: {`two`}},
+			),
+		}
+
+		rew := ht.NewRecorder()
+		rew.Header().Set(`Three`, `four`)
+		han.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusCreated, rew.Code)
+		eq(t, `hello`, rew.Body.String())
+		eq(t, `two`, rew.Header().Get(`One`))
+		eq(t, `four`, rew.Header().Get(`Three`))
+	})
+
+	t.Run(`timed out`, func(t *testing.T) {
+		late := make(chan error, 1)
+		slow := http.HandlerFunc(func(rew http.ResponseWriter, req *http.Request) {
+			<-req.Context().Done()
+			rew.Header().Set(`One`, `two`)
+			_, err := rew.Write([]byte(`late`))
+			late <- err
+		})
+
+		rew := ht.NewRecorder()
+		Timeout{After: time.Millisecond, Handler: slow}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusServiceUnavailable, rew.Code)
+		eq(t, ``, rew.Header().Get(`One`))
+		eq(t, true, errors.Is(<-late, http.ErrHandlerTimeout))
+	})
+
+	t.Run(`OnTimeout`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Timeout{
+			After:     time.Millisecond,
+			Handler:   blockingHandler(),
+			OnTimeout: JsonWith(http.StatusGatewayTimeout, `too slow`),
+		}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusGatewayTimeout, rew.Code)
+		eq(t, typeJsonUtf8, rew.Header().Get(HeadType))
+		eq(t, "\"too slow\"\n", rew.Body.String())
+	})
+
+	t.Run(`ErrFunc`, func(t *testing.T) {
+		var info ErrInfo
+		rew := ht.NewRecorder()
+		Timeout{
+			After:   time.Millisecond,
+			Handler: blockingHandler(),
+			ErrFunc: func(rew http.ResponseWriter, _ *http.Request, err error, _ bool) {
+				info, _ = ErrInfoOf(err)
+				rew.WriteHeader(http.StatusTeapot)
+			},
+		}.ServeHTTP(rew, pathReq(`/`))
+
+		eq(t, http.StatusTeapot, rew.Code)
+		eq(t, `goh.Timeout`, info.Handler)
+		eq(t, http.StatusServiceUnavailable, ErrHttpStatus(info))
+		eq(t, true, errors.Is(info, http.ErrHandlerTimeout))
+	})
+
+	t.Run(`write error`, func(t *testing.T) {
+		var info ErrInfo
+		var wrote bool
+		rec := ht.NewRecorder()
+		Timeout{
+			After:   time.Minute,
+			Handler: StringWith(http.StatusCreated, `hello world`),
+			ErrFunc: func(_ http.ResponseWriter, _ *http.Request, err error, val bool) {
+				info, _ = ErrInfoOf(err)
+				wrote = val
+			},
+		}.ServeHTTP(&failWriter{rec, 5}, pathReq(`/`))
+
+		eq(t, http.StatusCreated, rec.Code)
+		eq(t, `hello`, rec.Body.String())
+		eq(t, true, wrote)
+		eq(t, `goh.Timeout`, info.Handler)
+		eq(t, http.StatusCreated, info.Status)
+		eq(t, int64(5), info.Written)
+		eq(t, `[goh] failed to write buffered response: broken pipe`, info.Error())
+	})
+
+	t.Run(`no limit`, func(t *testing.T) {
+		rew := ht.NewRecorder()
+		Timeout{Handler: StringOk(`hello`)}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, `hello`, rew.Body.String())
+
+		rew = ht.NewRecorder()
+		Timeout{After: time.Minute}.ServeHTTP(rew, pathReq(`/`))
+		eq(t, http.StatusNotFound, rew.Code)
+	})
+
+	t.Run(`panic`, func(t *testing.T) {
+		defer func() { eq(t, `boom`, recover()) }()
+		Timeout{
+			After:   time.Minute,
+			Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic(`boom`) }),
+		}.ServeHTTP(ht.NewRecorder(), pathReq(`/`))
+	})
+}
+
+func blockingHandler() http.Handler {
+	return http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	})
+}
@@ -0,0 +1,75 @@
+package goh
+
+import (
+	"fmt"
+	"net/http"
+)
+
+/*
+Returns an error if `.Status` is not a valid final HTTP status. Zero is valid,
+and means the implicit 200. Otherwise the status must be between 200 and 599;
+informational 1xx statuses are rejected, since the response would continue
+after them with an implicit 200.
+Handler types have their own `.Validate` methods with additional checks.
+Validation is optional, and may be used in tests or at startup to catch
+mistakes that would otherwise produce corrupt responses.
+*/
+func (self Head) Validate() error {
+	if self.Status == 0 || self.Status >= 200 && self.Status <= 599 {
+		return nil
+	}
+	return fmt.Errorf(`[goh] invalid HTTP status %v: expected 0 or between 200 and 599`, self.Status)
+}
+
+func (self Head) validateBody(hasBody bool) error {
+	err := self.Validate()
+	if err != nil {
+		return err
+	}
+	if hasBody && isBodilessStatus(self.Status) {
+		return fmt.Errorf(`[goh] HTTP status %v doesn't allow a response body`, self.Status)
+	}
+	return nil
+}
+
+// Validates the status, and rejects a body with a bodiless status such as 204.
+func (self Reader) Validate() error { return self.Head().validateBody(self.Body != nil) }
+
+// Validates the status, and rejects a body with a bodiless status such as 204.
+func (self Bytes) Validate() error { return self.Head().validateBody(len(self.Body) > 0) }
+
+// Validates the status, and rejects a body with a bodiless status such as 204.
+func (self String) Validate() error { return self.Head().validateBody(self.Body != ``) }
+
+// Validates the status, and rejects a body with a bodiless status such as 204.
+func (self Text) Validate() error { return self.Head().validateBody(self.Body != ``) }
+
+// Validates the status, and rejects a body with a bodiless status such as 204.
+func (self HtmlString) Validate() error { return self.Head().validateBody(self.Body != ``) }
+
+// Validates the status, and rejects a body with a bodiless status such as 204.
+func (self HtmlBytes) Validate() error { return self.Head().validateBody(len(self.Body) > 0) }
+
+/*
+Validates the status, and rejects bodiless statuses such as 204, because JSON
+encoding always produces a body, even for nil.
+*/
+func (self Json) Validate() error { return self.Head().validateBody(true) }
+
+// Validates the status, and rejects a body with a bodiless status such as 204.
+func (self Xml) Validate() error { return self.Head().validateBody(self.Body != nil) }
+
+// Returns an error if `.Status` is not between 300 and 399.
+func (self Redirect) Validate() error {
+	if isRedirectStatus(self.Status) {
+		return nil
+	}
+	return fmt.Errorf(`[goh] invalid redirect status %v: expected between 300 and 399`, self.Status)
+}
+
+// Statuses 1xx, 204, and 304, which must not have a response body.
+func isBodilessStatus(val int) bool {
+	return val >= 100 && val <= 199 ||
+		val == http.StatusNoContent ||
+		val == http.StatusNotModified
+}
@@ -0,0 +1,42 @@
+package goh
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	test := func(exp string, err error) {
+		t.Helper()
+		if exp == `` {
+			eq(t, nil, err)
+			return
+		}
+		if err == nil || !strings.Contains(err.Error(), exp) {
+			t.Fatalf(`expected error containing %q, got %v`, exp, err)
+		}
+	}
+
+	test(``, Head{}.Validate())
+	test(``, Head{Status: 200}.Validate())
+	test(`invalid HTTP status 100`, Head{Status: 100}.Validate())
+	test(`invalid HTTP status 103`, Head{Status: 103}.Validate())
+	test(``, Head{Status: 599}.Validate())
+	test(`invalid HTTP status 42`, Head{Status: 42}.Validate())
+	test(`invalid HTTP status 1000`, Head{Status: 1000}.Validate())
+	test(`invalid HTTP status 42`, StringWith(42, `hello`).Validate())
+
+	test(``, NoContent().Validate())
+	test(``, StringWith(http.StatusNotModified, ``).Validate())
+	test(``, Reader{Status: http.StatusNoContent}.Validate())
+	test(``, Xml{Status: http.StatusNoContent}.Validate())
+	test(`status 204 doesn't allow a response body`, BytesWith(http.StatusNoContent, []byte(`hello`)).Validate())
+	test(`status 304 doesn't allow a response body`, StringWith(http.StatusNotModified, `hello`).Validate())
+	test(`status 204 doesn't allow a response body`, Json{Status: http.StatusNoContent}.Validate())
+	test(``, JsonOk(nil).Validate())
+
+	test(``, RedirectTemporary(`/`).Validate())
+	test(`invalid redirect status 200`, Redirect{Status: http.StatusOK}.Validate())
+	test(`invalid redirect status 0`, Redirect{}.Validate())
+}
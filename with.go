@@ -0,0 +1,67 @@
+package goh
+
+import "net/http"
+
+//go:generate go run gen_with.go
+
+/*
+Common interface of Goh handler types that pseudo-embed `goh.Head`. Allows
+generic code to inspect and modify the status, header, and error handler of any
+such handler. Example middleware that adds a header to every response:
+
+	func withCache(han http.Handler) http.Handler {
+		res, _ := han.(goh.Res)
+		if res == nil {
+			return han
+		}
+		return res.WithHead(res.Head().SetHeader(`Cache-Control`, `max-age=3600`))
+	}
+
+Not every type supports every field of `goh.Head`. The types that don't have
+`.NoContentLength` and `.Language` fields (`Redirect`, `File`, `Dir`, `FS`,
+`FileFS`, `DirList`, `Zip`, `Tar`, `Sitemap`, `SitemapIndex`, `Robots`,
+`RuntimeStats`) report them as zero from `.Head`, and their `.WithHead` ignores
+them. To set "Content-Language" on such types, use `Head.SetHeader`.
+*/
+type Res interface {
+	http.Handler
+	Head() Head
+	WithHead(Head) Res
+}
+
+/*
+Returns a modified version with the given header key set to the given value.
+Clones the header instead of modifying it, which keeps it safe to use with
+handlers shared between goroutines.
+*/
+func (self Head) SetHeader(key, val string) Head {
+	self.Header = self.Header.Clone()
+	if self.Header == nil {
+		self.Header = http.Header{}
+	}
+	self.Header.Set(key, val)
+	return self
+}
+
+/*
+Creates a head from the fields supported by every handler type. Used by the
+`.Head` methods of types without `.NoContentLength` and `.Language`. Counterpart
+of `goh.Head.basic`.
+*/
+func basicHead(status int, header http.Header, errFunc ErrFunc, appendHeader bool) Head {
+	return Head{status, header, errFunc, appendHeader, false, ``}
+}
+
+/*
+Returns the fields supported by every handler type, for assignment in
+`.WithHead` of types without `.NoContentLength` and `.Language`, which ignore
+those fields. See `goh.Res`.
+*/
+func (self Head) basic() (int, http.Header, ErrFunc, bool) {
+	return self.Status, self.Header, self.ErrFunc, self.AppendHeader
+}
+
+// Returns all fields, for assignment in `.WithHead` of types that support them.
+func (self Head) fields() (int, http.Header, ErrFunc, bool, bool, string) {
+	return self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language
+}
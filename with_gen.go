@@ -0,0 +1,353 @@
+// Code generated by "go run gen_with.go"; DO NOT EDIT.
+
+package goh
+
+import "net/http"
+
+// Returns a modified version with the given status.
+func (self Reader) WithStatus(val int) Reader {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Reader) WithHeader(val http.Header) Reader {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Reader) WithErrFunc(val ErrFunc) Reader {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Reader) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self Bytes) WithStatus(val int) Bytes {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Bytes) WithHeader(val http.Header) Bytes {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Bytes) WithErrFunc(val ErrFunc) Bytes {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Bytes) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self String) WithStatus(val int) String {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self String) WithHeader(val http.Header) String {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self String) WithErrFunc(val ErrFunc) String {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self String) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self Text) WithStatus(val int) Text {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Text) WithHeader(val http.Header) Text {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Text) WithErrFunc(val ErrFunc) Text {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Text) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self HtmlString) WithStatus(val int) HtmlString {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self HtmlString) WithHeader(val http.Header) HtmlString {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self HtmlString) WithErrFunc(val ErrFunc) HtmlString {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self HtmlString) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self HtmlBytes) WithStatus(val int) HtmlBytes {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self HtmlBytes) WithHeader(val http.Header) HtmlBytes {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self HtmlBytes) WithErrFunc(val ErrFunc) HtmlBytes {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self HtmlBytes) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self Json) WithStatus(val int) Json {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Json) WithHeader(val http.Header) Json {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Json) WithErrFunc(val ErrFunc) Json {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Json) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self Xml) WithStatus(val int) Xml {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Xml) WithHeader(val http.Header) Xml {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Xml) WithErrFunc(val ErrFunc) Xml {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Xml) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self Template) WithStatus(val int) Template {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Template) WithHeader(val http.Header) Template {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Template) WithErrFunc(val ErrFunc) Template {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Template) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Markdown) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader, self.NoContentLength, self.Language = val.fields()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self Redirect) WithStatus(val int) Redirect {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Redirect) WithHeader(val http.Header) Redirect {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Redirect) WithErrFunc(val ErrFunc) Redirect {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Redirect) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self File) WithStatus(val int) File {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self File) WithHeader(val http.Header) File {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self File) WithErrFunc(val ErrFunc) File {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self File) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Returns a modified version with the given status.
+func (self Dir) WithStatus(val int) Dir {
+	self.Status = val
+	return self
+}
+
+// Returns a modified version with the given header.
+func (self Dir) WithHeader(val http.Header) Dir {
+	self.Header = val
+	return self
+}
+
+// Returns a modified version with the given error handler.
+func (self Dir) WithErrFunc(val ErrFunc) Dir {
+	self.ErrFunc = val
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Dir) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self FS) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self FileFS) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self DirList) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Zip) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Tar) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Sitemap) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self SitemapIndex) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self Robots) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
+
+// Implement `goh.Res`. Returns a modified version with the given head.
+func (self RuntimeStats) WithHead(val Head) Res {
+	self.Status, self.Header, self.ErrFunc, self.AppendHeader = val.basic()
+	return self
+}
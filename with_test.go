@@ -0,0 +1,100 @@
+package goh
+
+import (
+	"net/http"
+	ht "net/http/httptest"
+	"testing"
+)
+
+var (
+	_ = Res(Reader{})
+	_ = Res(Bytes{})
+	_ = Res(String{})
+	_ = Res(Text{})
+	_ = Res(HtmlString{})
+	_ = Res(HtmlBytes{})
+	_ = Res(Json{})
+	_ = Res(Xml{})
+	_ = Res(Redirect{})
+	_ = Res(File{})
+	_ = Res(Dir{})
+	_ = Res(FS{})
+	_ = Res(FileFS{})
+	_ = Res(DirList{})
+	_ = Res(Zip{})
+	_ = Res(Tar{})
+	_ = Res(Sitemap{})
+	_ = Res(SitemapIndex{})
+	_ = Res(Robots{})
+	_ = Res(Template{})
+	_ = Res(Markdown{})
+	_ = Res(RuntimeStats{})
+)
+
+func TestWith(t *testing.T) {
+	errFunc := func(http.ResponseWriter, *http.Request, error, bool) {}
+	head := http.Header{`One`: {`two`}}
+
+	han := JsonOk(`hello`).WithStatus(http.StatusCreated).WithHeader(head).WithErrFunc(errFunc)
+
+	eq(t, http.StatusCreated, han.Status)
+	eq(t, head, han.Header)
+	eq(t, true, han.ErrFunc != nil)
+	eq(t, `hello`, han.Body)
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+	eq(t, http.StatusCreated, rew.Code)
+	eq(t, `two`, rew.Header().Get(`One`))
+
+	base := StringOk(`hello`)
+	base.WithStatus(http.StatusTeapot)
+	eq(t, http.StatusOK, base.Status)
+
+	eq(t, Dir{Path: `.`, Status: http.StatusAccepted}, Dir{Path: `.`}.WithStatus(http.StatusAccepted))
+	eq(t, File{Path: `one`, Header: head}, File{Path: `one`}.WithHeader(head))
+}
+
+func TestRes(t *testing.T) {
+	withCache := func(han http.Handler) http.Handler {
+		res, _ := han.(Res)
+		if res == nil {
+			return han
+		}
+		return res.WithHead(res.Head().SetHeader(`Cache-Control`, `no-store`))
+	}
+
+	head := http.Header{`One`: {`two`}}
+	han := withCache(String{Status: http.StatusAccepted, Header: head, Body: `hello`})
+
+	eq(t, String{
+		Status: http.StatusAccepted,
+		Header: http.Header{`One`: {`two`}, `Cache-Control`: {`no-store`}},
+		Body:   `hello`,
+	}, han)
+	eq(t, http.Header{`One`: {`two`}}, head)
+
+	eq(t, Bytes{Header: http.Header{`Cache-Control`: {`no-store`}}}, withCache(Bytes{}))
+
+	other := NotFound{}
+	eq(t, other, withCache(other))
+}
+
+func TestRes_fields(t *testing.T) {
+	head := Head{
+		Status:          http.StatusAccepted,
+		Header:          http.Header{`One`: {`two`}},
+		AppendHeader:    true,
+		NoContentLength: true,
+		Language:        `en`,
+	}
+
+	eq(t, head, Bytes{}.WithHead(head).Head())
+	eq(t, head, Markdown{}.WithHead(head).Head())
+
+	basic := head
+	basic.NoContentLength = false
+	basic.Language = ``
+	eq(t, basic, File{}.WithHead(head).Head())
+	eq(t, basic, Robots{}.WithHead(head).Head())
+}
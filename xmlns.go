@@ -0,0 +1,94 @@
+package goh
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+)
+
+/*
+Utility type for use together with `goh.Xml` and `goh.XmlDoc`. When encoded as
+XML, declares the given namespaces on the root element of `.Val`: `.Default`
+as "xmlns", and each entry of `.Prefixes` as "xmlns:<prefix>". Nested elements
+may then use prefixed names in struct tags. Example usage:
+
+	type Feed struct {
+		XMLName xml.Name `xml:"feed"`
+		Title   string   `xml:"title"`
+		Link    string   `xml:"atom:link"`
+	}
+
+	res := goh.XmlOk(goh.XmlNs{
+		Default:  `http://www.w3.org/2005/Atom`,
+		Prefixes: map[string]string{`atom`: `http://www.w3.org/2005/Atom`},
+		Val:      Feed{Title: `title`, Link: `link`},
+	})
+
+Eventual output:
+
+	<feed xmlns="http://www.w3.org/2005/Atom" xmlns:atom="http://www.w3.org/2005/Atom">...</feed>
+
+The name of the root element is determined by encoding `.Val`, which is
+therefore encoded twice.
+*/
+type XmlNs struct {
+	Default  string
+	Prefixes map[string]string
+	Val      interface{}
+}
+
+// Implement `encoding/xml.Marshaler`, adding namespace declarations to the
+// root element of `.Val`.
+func (self XmlNs) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	start, err := xmlRootStart(self.Val)
+	if err != nil {
+		return err
+	}
+	if start.Name.Local == `` {
+		return nil
+	}
+
+	var attrs []xml.Attr
+	if self.Default != `` {
+		start.Name.Space = ``
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: `xmlns`}, Value: self.Default})
+	}
+
+	keys := make([]string, 0, len(self.Prefixes))
+	for key := range self.Prefixes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		attrs = append(attrs, xml.Attr{
+			Name:  xml.Name{Local: `xmlns:` + key},
+			Value: self.Prefixes[key],
+		})
+	}
+
+	start.Attr = attrs
+	return enc.EncodeElement(self.Val, start)
+}
+
+// Returns the start element of the root of the given value, without attributes.
+func xmlRootStart(val interface{}) (xml.StartElement, error) {
+	body, err := xml.Marshal(val)
+	if err != nil {
+		return xml.StartElement{}, err
+	}
+
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// Empty output, for example from a nil value.
+			return xml.StartElement{}, nil
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if ok {
+			return xml.StartElement{Name: start.Name}, nil
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package goh
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+type xmlNsFeed struct {
+	XMLName xml.Name `xml:"feed"`
+	Lang    string   `xml:"lang,attr"`
+	Title   string   `xml:"title"`
+	Link    string   `xml:"atom:link"`
+}
+
+func TestXmlNs(t *testing.T) {
+	test := func(exp string, val interface{}) {
+		t.Helper()
+		bytes, err := xml.Marshal(val)
+		try(err)
+		eq(t, exp, string(bytes))
+	}
+
+	test(
+		`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/" lang="en">`+
+			`<title>one</title><atom:link>two</atom:link>`+
+			`</feed>`,
+		XmlNs{
+			Default: `http://www.w3.org/2005/Atom`,
+			Prefixes: map[string]string{
+				`media`: `http://search.yahoo.com/mrss/`,
+				`atom`:  `http://www.w3.org/2005/Atom`,
+			},
+			Val: xmlNsFeed{Lang: `en`, Title: `one`, Link: `two`},
+		},
+	)
+
+	test(`<string xmlns="urn:one">text</string>`, XmlNs{Default: `urn:one`, Val: `text`})
+	test(``, XmlNs{Default: `urn:one`})
+
+	test(
+		`<?xml version="1.0"?><feed xmlns="urn:one" lang=""><title></title><atom:link></atom:link></feed>`,
+		XmlDoc{Val: XmlNs{Default: `urn:one`, Val: xmlNsFeed{}}},
+	)
+}
@@ -0,0 +1,109 @@
+package goh
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Default value of `goh.Zip.Name`.
+const DefaultZipName = `archive.zip`
+
+/*
+HTTP handler that streams a zip archive of the files in the given paths, which
+may be directories or individual files. Intended for "download all" endpoints
+backed by the same directories served by `goh.Dir`. Example usage:
+
+	var han = goh.Zip{Paths: []string{`reports`}, Name: `reports.zip`}
+
+Directories are walked recursively. Entries in the archive are named relative
+to the parent of each path, so the files in `static` are archived as
+`static/...`. Only regular files are included. Like `goh.Dir`, this skips
+dotfiles unless `.AllowDotfiles` is true. `.Filter`, when set, is applied to
+the path of each file, but not to directories, so filters such as
+`goh.AllowExts` work as expected.
+
+The response has "Content-Type: application/zip" and "Content-Disposition:
+attachment" with the file name `.Name`, defaulting to `goh.DefaultZipName`.
+Either can be overridden via `.Header`.
+
+The files are listed before writing the response, and errors at that stage
+result in a regular error response via `.ErrFunc`. Errors while streaming the
+archive are also passed to `.ErrFunc`, with `wrote = true`, since the response
+is already partially written.
+*/
+type Zip struct {
+	Status        int
+	Header        http.Header
+	ErrFunc       ErrFunc
+	AppendHeader  bool
+	Paths         []string
+	Filter        Filter
+	Name          string
+	AllowDotfiles bool
+}
+
+// Returns the pseudo-embedded `goh.Head` part.
+func (self Zip) Head() Head {
+	return basicHead(self.Status, self.Header, self.ErrFunc, self.AppendHeader)
+}
+
+// Implement `http.Handler`.
+func (self Zip) ServeHTTP(rew http.ResponseWriter, req *http.Request) {
+	observe(rew, req, `goh.Zip`, self.serveHTTP)
+}
+
+func (self Zip) serveHTTP(rew http.ResponseWriter, req *http.Request) {
+	head := self.Head()
+
+	files, err := self.archive().files()
+	if err != nil {
+		head.fail(rew, req, ErrInfo{Err: err, Handler: `goh.Zip`})
+		return
+	}
+
+	header := rew.Header()
+	header.Set(HeadType, TypeZip)
+	header.Set(`Content-Disposition`, ContentDisposition(DispositionAttachment, orStr(self.Name, DefaultZipName)))
+	head.Write(rew)
+
+	writer := spyingWriter{Writer: rew}
+	err = writeZip(&writer, files)
+	if err != nil {
+		err = fmt.Errorf(`[goh] failed to write zip archive: %w`, err)
+		head.fail(rew, req, ErrInfo{err, `goh.Zip`, head.sentStatusIf(writer.wrote), writer.size, writer.wrote})
+	}
+}
+
+// Conforms to `goh.Han`, returning self.
+func (self Zip) Han(*http.Request) http.Handler { return self }
+
+func (self Zip) archive() archive {
+	return archive{self.Paths, self.Filter, self.AllowDotfiles}
+}
+
+func writeZip(out io.Writer, files []archiveFile) error {
+	writer := zip.NewWriter(out)
+
+	for _, file := range files {
+		head, err := zip.FileInfoHeader(file.Info)
+		if err != nil {
+			return err
+		}
+		head.Name = file.Name
+		head.Method = zip.Deflate
+
+		dst, err := writer.CreateHeader(head)
+		if err != nil {
+			return err
+		}
+
+		err = copyFile(dst, file.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
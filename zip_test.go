@@ -0,0 +1,91 @@
+package goh
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	ht "net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+var (
+	_ = http.Handler(Zip{})
+	_ = Han(Zip{}.Han)
+)
+
+func TestZip(t *testing.T) {
+	root := t.TempDir()
+	writeFile(filepath.Join(root, `static/one.txt`), `one`)
+	writeFile(filepath.Join(root, `static/two/three.txt`), `three`)
+	writeFile(filepath.Join(root, `static/two/four.md`), `four`)
+	writeFile(filepath.Join(root, `static/.env`), `secret`)
+	writeFile(filepath.Join(root, `static/.git/config`), `secret`)
+	writeFile(filepath.Join(root, `five.txt`), `five`)
+
+	han := Zip{
+		Paths:  []string{filepath.Join(root, `static`), filepath.Join(root, `five.txt`)},
+		Filter: AllowExts{`.txt`},
+	}
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+
+	eq(t, http.StatusOK, rew.Code)
+	eq(t, TypeZip, rew.Header().Get(HeadType))
+	eq(t, `attachment; filename="archive.zip"`, rew.Header().Get(`Content-Disposition`))
+	eq(
+		t,
+		map[string]string{
+			`static/one.txt`:       `one`,
+			`static/two/three.txt`: `three`,
+			`five.txt`:             `five`,
+		},
+		readZip(rew.Body.Bytes()),
+	)
+}
+
+func TestZip_Name(t *testing.T) {
+	rew := ht.NewRecorder()
+	Zip{Status: 201, Name: `reports.zip`}.ServeHTTP(rew, nil)
+
+	eq(t, 201, rew.Code)
+	eq(t, `attachment; filename="reports.zip"`, rew.Header().Get(`Content-Disposition`))
+	eq(t, map[string]string{}, readZip(rew.Body.Bytes()))
+}
+
+func TestZip_error(t *testing.T) {
+	var wrote []bool
+
+	han := Zip{
+		Paths: []string{filepath.Join(t.TempDir(), `missing`)},
+		ErrFunc: func(rew http.ResponseWriter, _ *http.Request, err error, val bool) {
+			wrote = append(wrote, val)
+			WriteErr(rew, nil, err, val)
+		},
+	}
+
+	rew := ht.NewRecorder()
+	han.ServeHTTP(rew, nil)
+
+	eq(t, []bool{false}, wrote)
+	eq(t, http.StatusInternalServerError, rew.Code)
+	eq(t, ``, rew.Header().Get(`Content-Disposition`))
+}
+
+func readZip(src []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(src), int64(len(src)))
+	try(err)
+
+	out := map[string]string{}
+	for _, file := range reader.File {
+		body, err := file.Open()
+		try(err)
+		val, err := io.ReadAll(body)
+		try(err)
+		try(body.Close())
+		out[file.Name] = string(val)
+	}
+	return out
+}